@@ -1,17 +1,16 @@
 package main
 
 import (
-	"context"
 	"fmt"
-	"os"
 	"strconv"
+	"strings"
 
-	"github.com/open-policy-agent/opa/ast"
-	"github.com/open-policy-agent/opa/rego"
+	"github.com/shopspring/decimal"
 	"github.com/spf13/cobra"
 
 	"github.com/pkg/errors"
 
+	"github.com/infracost/infracost/internal/comment"
 	"github.com/infracost/infracost/internal/config"
 	"github.com/infracost/infracost/internal/output"
 	"github.com/infracost/infracost/internal/ui"
@@ -42,6 +41,7 @@ func commentCmd(ctx *config.RunContext) *cobra.Command {
 	cmds := []*cobra.Command{commentGitHubCmd(ctx), commentGitLabCmd(ctx), commentAzureReposCmd(ctx), commentBitbucketCmd(ctx)}
 	for _, subCmd := range cmds {
 		subCmd.Flags().StringArray("policy-path", nil, "Path to Infracost policy files, glob patterns need quotes (experimental)")
+		subCmd.Flags().String("template-path", "", "Path to a Go template used instead of the built-in comment template (experimental)")
 	}
 
 	cmd.AddCommand(cmds...)
@@ -49,15 +49,15 @@ func commentCmd(ctx *config.RunContext) *cobra.Command {
 	return cmd
 }
 
-func buildCommentBody(cmd *cobra.Command, ctx *config.RunContext, paths []string, mdOpts output.MarkdownOptions) ([]byte, error) {
+func buildCommentBody(cmd *cobra.Command, ctx *config.RunContext, paths []string, mdOpts output.MarkdownOptions) ([]byte, output.Root, error) {
 	inputs, err := output.LoadPaths(paths)
 	if err != nil {
-		return nil, err
+		return nil, output.Root{}, err
 	}
 
 	combined, err := output.Combine(inputs)
 	if err != nil {
-		return nil, err
+		return nil, output.Root{}, err
 	}
 	combined.IsCIRun = ctx.IsCIRun()
 
@@ -70,16 +70,10 @@ func buildCommentBody(cmd *cobra.Command, ctx *config.RunContext, paths []string
 		combined.RunID, combined.ShareURL = shareCombinedRun(ctx, combined, inputs)
 	}
 
-	var policyChecks output.PolicyCheck
 	policyPaths, _ := cmd.Flags().GetStringArray("policy-path")
-	if len(policyPaths) > 0 {
-		policyChecks, err = queryPolicy(policyPaths, combined)
-		if err != nil {
-			return nil, err
-		}
-
-		ctx.SetContextValue("passedPolicyCount", len(policyChecks.Passed))
-		ctx.SetContextValue("failedPolicyCount", len(policyChecks.Failures))
+	policyChecks, err := queryPolicies(ctx, config.Policies{Paths: policyPaths}, combined)
+	if err != nil {
+		return nil, combined, err
 	}
 
 	opts := output.Options{
@@ -88,110 +82,100 @@ func buildCommentBody(cmd *cobra.Command, ctx *config.RunContext, paths []string
 		ShowSkipped:      true,
 		PolicyChecks:     policyChecks,
 	}
+	opts.TemplatePath, _ = cmd.Flags().GetString("template-path")
 
 	b, err := output.ToMarkdown(combined, opts, mdOpts)
 	if err != nil {
-		return nil, err
+		return nil, combined, err
 	}
 
 	if policyChecks.HasFailed() {
-		return b, policyChecks.Failures
+		return b, combined, policyChecks.Failures
 	}
 
-	return b, nil
+	return b, combined, nil
 }
 
-type PRNumber int
+// addApprovalFlags adds the flags used to request approval for cost increases on
+// pull requests or merge requests.
+func addApprovalFlags(cmd *cobra.Command, target string) {
+	cmd.Flags().StringSlice("approvers", nil, fmt.Sprintf("Users or groups that must approve cost increases above the approval threshold, requires %s", target))
+	cmd.Flags().Float64("approval-threshold", 0, "Monthly cost increase above which approval is required")
+	cmd.Flags().String("approval-status-context", "infracost/approval", "Name of the commit status used to report the approval")
+}
 
-func (p *PRNumber) Set(value string) error {
-	if value == "" {
+// loadApprovalFlags sets the approval markdown options from the approval flags.
+func loadApprovalFlags(cmd *cobra.Command, target string, hasTarget bool, mdOpts *output.MarkdownOptions) error {
+	approvers, _ := cmd.Flags().GetStringSlice("approvers")
+	if len(approvers) == 0 {
 		return nil
 	}
 
-	v, err := strconv.Atoi(value)
-	*p = PRNumber(v)
-
-	if err != nil {
-		return errors.New("must be integer")
+	if !hasTarget {
+		ui.PrintUsage(cmd)
+		return fmt.Errorf("--approvers can only be used with --%s", target)
 	}
 
-	return nil
-}
+	threshold, _ := cmd.Flags().GetFloat64("approval-threshold")
+	d := decimal.NewFromFloat(threshold)
 
-func (p *PRNumber) String() string {
-	return fmt.Sprintf("%d", *p)
-}
+	mdOpts.Approvers = approvers
+	mdOpts.ApprovalThreshold = &d
 
-func (p *PRNumber) Type() string {
-	return "int"
+	return nil
 }
 
-func queryPolicy(policyPaths []string, input output.Root) (output.PolicyCheck, error) {
-	checks := output.PolicyCheck{
-		Enabled: true,
+// checkApproval sets the approval commit status and requests a review from the
+// approvers if the cost change requires approval and has not been approved yet.
+func checkApproval(cmd *cobra.Command, ctx *config.RunContext, commentHandler *comment.CommentHandler, combined output.Root, mdOpts output.MarkdownOptions) error {
+	if len(mdOpts.Approvers) == 0 {
+		return nil
 	}
 
-	inputValue, err := ast.InterfaceToValue(input)
-	if err != nil {
-		return checks, fmt.Errorf("Unable to process Infracost output into Rego input: %s", err.Error())
-	}
-
-	ctx := context.Background()
-	r := rego.New(
-		rego.Query("data.infracost.deny"),
-		rego.ParsedInput(inputValue),
-		rego.Load(policyPaths, func(abspath string, info os.FileInfo, depth int) bool {
-			return false
-		}),
-	)
-	pq, err := r.PrepareForEval(ctx)
-	if err != nil {
-		return checks, fmt.Errorf("Unable to query provided policies: %s", err.Error())
-	}
+	statusContext, _ := cmd.Flags().GetString("approval-status-context")
+	required := mdOpts.ApprovalRequired(combined)
+	ctx.SetContextValue("approvalRequired", required)
 
-	res, err := pq.Eval(ctx)
+	result, err := commentHandler.CheckApproval(ctx.Context(), comment.ApprovalOptions{
+		Required:      required,
+		Approvers:     mdOpts.Approvers,
+		StatusContext: statusContext,
+	})
 	if err != nil {
-		return checks, err
-	}
-
-	if len(res) == 0 {
-		return checks, fmt.Errorf("The provided polices returned no valid data.infracost.deny rules. Please check that the policies are formatted correctly.")
+		return err
 	}
 
-	for _, e := range res[0].Expressions {
-		switch v := e.Value.(type) {
-		case map[string]interface{}:
-			readPolicyOut(v, &checks)
-		case []interface{}:
-			for _, ii := range v {
-				if m, ok := ii.(map[string]interface{}); ok {
-					readPolicyOut(m, &checks)
-				}
-			}
-		}
+	if result.ApprovedBy != "" {
+		ctx.SetContextValue("approved", true)
+		cmd.Printf("Cost change approved by %s\n", result.ApprovedBy)
+	} else if required {
+		cmd.Printf("Cost change requires approval from %s\n", strings.Join(mdOpts.Approvers, ", "))
 	}
 
-	return checks, nil
+	return nil
 }
 
-func readPolicyOut(v map[string]interface{}, checks *output.PolicyCheck) {
-	if _, ok := v["msg"]; !ok {
-		checks.Failures = append(checks.Failures, "Policy rule invalid as it did not contain {msg: string} property in output object. Please edit rule output object.")
-		return
-	}
-	msg := v["msg"].(string)
+type PRNumber int
 
-	if _, ok := v["failed"]; !ok {
-		checks.Failures = append(checks.Failures, fmt.Sprintf("Policy rule: [%s] did not contain {failed: bool} output property. Please edit rule output object.", msg))
-		return
+func (p *PRNumber) Set(value string) error {
+	if value == "" {
+		return nil
 	}
 
-	failed, _ := v["failed"].(bool)
+	v, err := strconv.Atoi(value)
+	*p = PRNumber(v)
 
-	if failed {
-		checks.Failures = append(checks.Failures, msg)
-		return
+	if err != nil {
+		return errors.New("must be integer")
 	}
 
-	checks.Passed = append(checks.Passed, msg)
+	return nil
+}
+
+func (p *PRNumber) String() string {
+	return fmt.Sprintf("%d", *p)
+}
+
+func (p *PRNumber) Type() string {
+	return "int"
 }
@@ -28,7 +28,11 @@ func commentGitHubCmd(ctx *config.RunContext) *cobra.Command {
 
   Post a new comment to a commit:
 
-      infracost comment github --repo my-org/my-repo --commit 2ca7182 --path infracost.json --behavior hide-and-new --github-token $GITHUB_TOKEN`,
+      infracost comment github --repo my-org/my-repo --commit 2ca7182 --path infracost.json --behavior hide-and-new --github-token $GITHUB_TOKEN
+
+  Require approval from a team for monthly cost increases above $500:
+
+      infracost comment github --repo my-org/my-repo --pull-request 3 --path infracost.json --approvers my-org/finops --approval-threshold 500 --github-token $GITHUB_TOKEN`,
 		ValidArgs: []string{"--", "-"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx.SetContextValue("platform", "github")
@@ -77,11 +81,17 @@ func commentGitHubCmd(ctx *config.RunContext) *cobra.Command {
 
 			paths, _ := cmd.Flags().GetStringArray("path")
 
-			body, err := buildCommentBody(cmd, ctx, paths, output.MarkdownOptions{
+			mdOpts := output.MarkdownOptions{
 				WillUpdate:          prNumber != 0 && behavior == "update",
 				WillReplace:         prNumber != 0 && behavior == "delete-and-new",
 				IncludeFeedbackLink: true,
-			})
+			}
+			err = loadApprovalFlags(cmd, "pull-request", prNumber != 0, &mdOpts)
+			if err != nil {
+				return err
+			}
+
+			body, combined, err := buildCommentBody(cmd, ctx, paths, mdOpts)
 			var policyFailure output.PolicyCheckFailures
 			if err != nil {
 				if v, ok := err.(output.PolicyCheckFailures); ok {
@@ -105,6 +115,11 @@ func commentGitHubCmd(ctx *config.RunContext) *cobra.Command {
 				}
 
 				cmd.Println("Comment posted to GitHub")
+
+				err = checkApproval(cmd, ctx, commentHandler, combined, mdOpts)
+				if err != nil {
+					return err
+				}
 			} else {
 				cmd.Println(string(body))
 				cmd.Println("Comment not posted to GitHub (--dry-run was specified)")
@@ -140,6 +155,7 @@ func commentGitHubCmd(ctx *config.RunContext) *cobra.Command {
 	_ = cmd.MarkFlagRequired("repo")
 	cmd.Flags().String("tag", "", "Customize hidden markdown tag used to detect comments posted by Infracost")
 	cmd.Flags().Bool("dry-run", false, "Generate comment without actually posting to GitHub")
+	addApprovalFlags(cmd, "pull-request")
 
 	return cmd
 }
@@ -21,3 +21,21 @@ func TestCommentGitHubCommit(t *testing.T) {
 		[]string{"comment", "github", "--github-token", "abc", "--repo", "test/test", "--commit", "5", "--path", "./testdata/terraform_v0.14_breakdown.json", "--dry-run"},
 		nil)
 }
+
+func TestCommentGitHubWithPolicies(t *testing.T) {
+	GoldenFileCommandTest(t, testutil.CalcGoldenFileTestdataDirName(),
+		[]string{"comment", "github", "--github-token", "abc", "--repo", "test/test", "--pull-request", "5", "--path", "./testdata/terraform_v0.14_breakdown.json", "--policy-path", "./testdata/comment_git_hub_with_policies/policy.rego", "--dry-run"},
+		nil)
+}
+
+func TestCommentGitHubWithApproval(t *testing.T) {
+	GoldenFileCommandTest(t, testutil.CalcGoldenFileTestdataDirName(),
+		[]string{"comment", "github", "--github-token", "abc", "--repo", "test/test", "--pull-request", "5", "--path", "./testdata/terraform_v0.14_breakdown.json", "--approvers", "alice,my-org/finops", "--approval-threshold", "10", "--dry-run"},
+		nil)
+}
+
+func TestCommentGitHubApprovalRequiresPullRequest(t *testing.T) {
+	GoldenFileCommandTest(t, testutil.CalcGoldenFileTestdataDirName(),
+		[]string{"comment", "github", "--github-token", "abc", "--repo", "test/test", "--commit", "5", "--path", "./testdata/terraform_v0.14_breakdown.json", "--approvers", "alice", "--dry-run"},
+		nil)
+}
@@ -28,7 +28,11 @@ func commentGitLabCmd(ctx *config.RunContext) *cobra.Command {
 
   Post a new comment to a commit:
 
-      infracost comment gitlab --repo my-org/my-repo --commit 2ca7182 --path infracost.json --behavior delete-and-new --gitlab-token $GITLAB_TOKEN`,
+      infracost comment gitlab --repo my-org/my-repo --commit 2ca7182 --path infracost.json --behavior delete-and-new --gitlab-token $GITLAB_TOKEN
+
+  Require approval from a group for monthly cost increases above $500:
+
+      infracost comment gitlab --repo my-org/my-repo --merge-request 3 --path infracost.json --approvers my-org/finops --approval-threshold 500 --gitlab-token $GITLAB_TOKEN`,
 		ValidArgs: []string{"--", "-"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx.SetContextValue("platform", "gitlab")
@@ -77,11 +81,17 @@ func commentGitLabCmd(ctx *config.RunContext) *cobra.Command {
 
 			paths, _ := cmd.Flags().GetStringArray("path")
 
-			body, err := buildCommentBody(cmd, ctx, paths, output.MarkdownOptions{
+			mdOpts := output.MarkdownOptions{
 				WillUpdate:          mrNumber != 0 && behavior == "update",
 				WillReplace:         mrNumber != 0 && behavior == "delete-and-new",
 				IncludeFeedbackLink: true,
-			})
+			}
+			err = loadApprovalFlags(cmd, "merge-request", mrNumber != 0, &mdOpts)
+			if err != nil {
+				return err
+			}
+
+			body, combined, err := buildCommentBody(cmd, ctx, paths, mdOpts)
 			var policyFailure output.PolicyCheckFailures
 			if err != nil {
 				if v, ok := err.(output.PolicyCheckFailures); ok {
@@ -105,6 +115,11 @@ func commentGitLabCmd(ctx *config.RunContext) *cobra.Command {
 				}
 
 				cmd.Println("Comment posted to GitLab")
+
+				err = checkApproval(cmd, ctx, commentHandler, combined, mdOpts)
+				if err != nil {
+					return err
+				}
 			} else {
 				cmd.Println(string(body))
 				cmd.Println("Comment not posted to GitLab (--dry-run was specified)")
@@ -138,6 +153,7 @@ func commentGitLabCmd(ctx *config.RunContext) *cobra.Command {
 	_ = cmd.MarkFlagRequired("repo")
 	cmd.Flags().String("tag", "", "Customize hidden markdown tag used to detect comments posted by Infracost")
 	cmd.Flags().Bool("dry-run", false, "Generate comment without actually posting to GitLab")
+	addApprovalFlags(cmd, "merge-request")
 
 	return cmd
 }
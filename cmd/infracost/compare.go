@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/infracost/infracost/internal/apiclient"
+	"github.com/infracost/infracost/internal/config"
+	"github.com/infracost/infracost/internal/output"
+	"github.com/infracost/infracost/internal/ui"
+)
+
+func compareCmd(ctx *config.RunContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "compare",
+		Short: "Show diff of monthly costs between two Infracost JSON files",
+		Long: `Show diff of monthly costs between two Infracost JSON files, e.g. from two runs of the same projects.
+
+Projects are matched by their name and path. Terraform is not run, so only the costs in the
+JSON files are compared.`,
+		Example: `  Compare last week's Infracost JSON file to today's:
+
+      infracost breakdown --path /path/to/code --format json --out-file infracost-today.json
+      infracost compare --from infracost-last-week.json --to infracost-today.json
+
+  Create markdown report to post in a GitHub comment:
+
+      infracost compare --from infracost-base.json --to infracost.json --format github-comment`,
+		ValidArgs: []string{"--", "-"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, _ := cmd.Flags().GetString("format")
+			ctx.SetContextValue("outputFormat", format)
+
+			if format != "" && !contains(validOutputFormats, format) {
+				ui.PrintUsage(cmd)
+				return fmt.Errorf("--format only supports %s", strings.Join(validOutputFormats, ", "))
+			}
+
+			outFile, _ := cmd.Flags().GetString("out-file")
+			if format == "xlsx" && outFile == "" {
+				ui.PrintUsage(cmd)
+				return errors.New("--format xlsx needs --out-file to be set since the output is a binary file")
+			}
+
+			fromPath, _ := cmd.Flags().GetString("from")
+			from, err := loadCompareRoot(fromPath)
+			if err != nil {
+				return err
+			}
+
+			toPath, _ := cmd.Flags().GetString("to")
+			to, err := loadCompareRoot(toPath)
+			if err != nil {
+				return err
+			}
+
+			compared, err := output.Compare(from, to)
+			if err != nil {
+				return err
+			}
+			compared.IsCIRun = ctx.IsCIRun()
+
+			fields := parseFieldsFlag(cmd)
+			if fields == nil {
+				fields = defaultOutputFields
+			}
+
+			opts := output.Options{
+				DashboardEnabled: ctx.Config.EnableDashboard,
+				NoColor:          ctx.Config.NoColor,
+				Fields:           fields,
+			}
+			opts.ShowSkipped, _ = cmd.Flags().GetBool("show-skipped")
+			opts.TemplatePath, _ = cmd.Flags().GetString("template-path")
+
+			opts.MetricsLabelLevel, err = metricsLabelLevelFlag(cmd)
+			if err != nil {
+				return err
+			}
+
+			err = checkOutputFlags(cmd, format, opts)
+			if err != nil {
+				return err
+			}
+
+			if format == "" {
+				format = "diff"
+			}
+
+			b, err := renderOutput(format, compared, opts)
+			if err != nil {
+				return err
+			}
+
+			pricingClient := apiclient.NewPricingAPIClient(ctx)
+			err = pricingClient.AddEvent("infracost-compare", ctx.EventEnv())
+			if err != nil {
+				log.Errorf("Error reporting event: %s", err)
+			}
+
+			if pushgatewayURL, _ := cmd.Flags().GetString("pushgateway-url"); pushgatewayURL != "" {
+				err = pushMetrics(pushgatewayURL, compared, opts)
+				if err != nil {
+					return err
+				}
+			}
+
+			if outFile != "" {
+				return saveOutFile(ctx, cmd, outFile, b)
+			}
+
+			cmd.Println(string(b))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("from", "", "Path to the Infracost JSON file to compare from, e.g. from the base branch")
+	cmd.Flags().String("to", "", "Path to the Infracost JSON file to compare to, e.g. from the latest run")
+	cmd.Flags().StringP("out-file", "o", "", "Save output to a file, helpful with format flag")
+
+	cmd.Flags().String("format", "diff", "Output format: json, diff, table, html, github-comment, gitlab-comment, azure-repos-comment, bitbucket-comment, slack-message, csv, xlsx, sarif, junit, openmetrics")
+	cmd.Flags().Bool("show-skipped", false, "List unsupported and free resources")
+	cmd.Flags().StringSlice("fields", []string{"monthlyQuantity", "unit", "monthlyCost"}, "Comma separated list of output fields: all,price,monthlyQuantity,unit,hourlyCost,monthlyCost.\nSupported by table and html output formats")
+	addMetricsFlags(cmd)
+	cmd.Flags().String("template-path", "", "Path to a Go template used instead of the built-in output. Not supported by json, slack-message, csv, xlsx, sarif, junit and openmetrics formats (experimental)")
+
+	_ = cmd.MarkFlagRequired("from")
+	_ = cmd.MarkFlagRequired("to")
+	_ = cmd.MarkFlagFilename("from", "json")
+	_ = cmd.MarkFlagFilename("to", "json")
+
+	_ = cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return validOutputFormats, cobra.ShellCompDirectiveDefault
+	})
+
+	return cmd
+}
+
+// loadCompareRoot loads the Infracost JSON file for one side of the comparison. If the path is a glob
+// pattern that matches multiple files they are combined first.
+func loadCompareRoot(path string) (output.Root, error) {
+	inputs, err := output.LoadPaths([]string{path})
+	if err != nil {
+		return output.Root{}, err
+	}
+
+	return output.Combine(inputs)
+}
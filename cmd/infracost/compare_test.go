@@ -0,0 +1,43 @@
+package main_test
+
+import (
+	"testing"
+
+	"github.com/infracost/infracost/internal/testutil"
+)
+
+func TestCompareHelp(t *testing.T) {
+	GoldenFileCommandTest(t, testutil.CalcGoldenFileTestdataDirName(), []string{"compare", "--help"}, nil)
+}
+
+func TestCompareFormatDiff(t *testing.T) {
+	GoldenFileCommandTest(t, testutil.CalcGoldenFileTestdataDirName(), []string{"compare", "--from", "./testdata/terraform_v0.14_compare_from.json", "--to", "./testdata/terraform_v0.14_breakdown.json"}, nil)
+}
+
+func TestCompareFormatJSON(t *testing.T) {
+	opts := DefaultOptions()
+	opts.IsJSON = true
+	GoldenFileCommandTest(t, testutil.CalcGoldenFileTestdataDirName(), []string{"compare", "--format", "json", "--from", "./testdata/terraform_v0.14_compare_from.json", "--to", "./testdata/terraform_v0.14_breakdown.json"}, opts)
+}
+
+func TestCompareFormatGitHubComment(t *testing.T) {
+	GoldenFileCommandTest(t, testutil.CalcGoldenFileTestdataDirName(), []string{"compare", "--format", "github-comment", "--from", "./testdata/terraform_v0.14_compare_from.json", "--to", "./testdata/terraform_v0.14_breakdown.json"}, nil)
+}
+
+func TestCompareFormatOpenMetricsProjectLabels(t *testing.T) {
+	GoldenFileCommandTest(t, testutil.CalcGoldenFileTestdataDirName(), []string{"compare", "--format", "openmetrics", "--metrics-label-level", "project", "--from", "./testdata/terraform_v0.14_compare_from.json", "--to", "./testdata/terraform_v0.14_breakdown.json"}, nil)
+}
+
+func TestCompareMissingFrom(t *testing.T) {
+	GoldenFileCommandTest(t, testutil.CalcGoldenFileTestdataDirName(), []string{"compare", "--to", "./testdata/terraform_v0.14_breakdown.json"}, nil)
+}
+
+func TestCompareMovedResources(t *testing.T) {
+	GoldenFileCommandTest(t, testutil.CalcGoldenFileTestdataDirName(), []string{"compare", "--from", "./testdata/terraform_v0.14_breakdown.json", "--to", "./testdata/terraform_v0.14_compare_moved.json"}, nil)
+}
+
+func TestCompareMovedResourcesJSON(t *testing.T) {
+	opts := DefaultOptions()
+	opts.IsJSON = true
+	GoldenFileCommandTest(t, testutil.CalcGoldenFileTestdataDirName(), []string{"compare", "--format", "json", "--from", "./testdata/terraform_v0.14_breakdown.json", "--to", "./testdata/terraform_v0.14_compare_moved.json"}, opts)
+}
@@ -15,6 +15,8 @@ import (
 var supportedConfigureKeys = map[string]struct{}{
 	"api_key":                  {},
 	"currency":                 {},
+	"secondary_currency":       {},
+	"exchange_rates_file":      {},
 	"pricing_api_endpoint":     {},
 	"enable_dashboard":         {},
 	"disable_hcl":              {},
@@ -106,6 +108,12 @@ func configureSetCmd(ctx *config.RunContext) *cobra.Command {
 			case "currency":
 				ctx.Config.Configuration.Currency = value
 				saveConfiguration = true
+			case "secondary_currency":
+				ctx.Config.Configuration.SecondaryCurrency = value
+				saveConfiguration = true
+			case "exchange_rates_file":
+				ctx.Config.Configuration.ExchangeRatesFile = value
+				saveConfiguration = true
 			case "disable_hcl":
 				b, err := strconv.ParseBool(value)
 				if err != nil {
@@ -220,6 +228,26 @@ func configureGetCmd(ctx *config.RunContext) *cobra.Command {
 					)
 					ui.PrintWarning(cmd.ErrOrStderr(), msg)
 				}
+			case "secondary_currency":
+				value = ctx.Config.Configuration.SecondaryCurrency
+
+				if value == "" {
+					msg := fmt.Sprintf("No secondary currency in your saved config (%s).\nSet a secondary currency using %s.",
+						config.ConfigurationFilePath(),
+						ui.PrimaryString("infracost configure set secondary_currency CURRENCY"),
+					)
+					ui.PrintWarning(cmd.ErrOrStderr(), msg)
+				}
+			case "exchange_rates_file":
+				value = ctx.Config.Configuration.ExchangeRatesFile
+
+				if value == "" {
+					msg := fmt.Sprintf("No exchange rates file in your saved config (%s).\nSet an exchange rates file using %s.",
+						config.ConfigurationFilePath(),
+						ui.PrimaryString("infracost configure set exchange_rates_file /path/to/rates.yml"),
+					)
+					ui.PrintWarning(cmd.ErrOrStderr(), msg)
+				}
 			case "tls_insecure_skip_verify":
 				if ctx.Config.Configuration.TLSInsecureSkipVerify == nil {
 					value = ""
@@ -275,6 +303,8 @@ Supported settings:
   - api_key: Infracost API key
   - pricing_api_endpoint: endpoint of the Cloud Pricing API
   - currency: convert output from USD to your preferred currency
+  - secondary_currency: also show the total costs in this currency, using the exchange rates
+  - exchange_rates_file: YAML or JSON file with exchange rates used to convert costs between currencies
   - enable_dashboard: enable the Infracost dashboard
   - tls_insecure_skip_verify: skip TLS certificate checks for a self-hosted Cloud Pricing API
   - tls_ca_cert_file: verify certificate of a self-hosted Cloud Pricing API using this CA certificate
@@ -21,7 +21,11 @@ func diffCmd(ctx *config.RunContext) *cobra.Command {
 
       terraform plan -out tfplan.binary
       terraform show -json tfplan.binary > plan.json
-      infracost diff --path plan.json`,
+      infracost diff --path plan.json
+
+  Compare the HCL code to another git branch without running Terraform:
+
+      infracost diff --path /path/to/code --terraform-parse-hcl --compare-to main`,
 		ValidArgs: []string{"--", "-"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := checkAPIKey(ctx.Config.APIKey, ctx.Config.PricingAPIEndpoint, ctx.Config.DefaultPricingAPIEndpoint); err != nil {
@@ -55,6 +59,11 @@ func diffCmd(ctx *config.RunContext) *cobra.Command {
 
 	cmd.Flags().String("out-file", "", "Save output to a file")
 
+	cmd.Flags().Bool("terraform-parse-hcl", false, "Parse HCL code instead of generating a Terraform plan. This does not need credentials and is faster (experimental)")
+	cmd.Flags().StringSlice("terraform-var-file", nil, "Load variable files, similar to Terraform’s -var-file flag. Applicable with --terraform-parse-hcl (experimental)")
+	cmd.Flags().StringSlice("terraform-var", nil, "Set value for an input variable, similar to Terraform’s -var flag. Applicable with --terraform-parse-hcl (experimental)")
+	cmd.Flags().String("compare-to", "", "Git ref to compare the HCL code to, e.g. main. Applicable with --terraform-parse-hcl (experimental)")
+
 	return cmd
 }
 
@@ -63,6 +72,10 @@ func checkDiffConfig(cfg *config.Config) error {
 		if projectConfig.TerraformUseState {
 			return errors.New("terraform_use_state cannot be used with `infracost diff` as the Terraform state only contains the current state")
 		}
+
+		if projectConfig.TerraformCompareTo != "" && !projectConfig.TerraformParseHCL {
+			return errors.New("--compare-to can only be used with --terraform-parse-hcl")
+		}
 	}
 
 	return nil
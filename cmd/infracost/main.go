@@ -15,6 +15,7 @@ import (
 
 	"github.com/infracost/infracost/internal/apiclient"
 	"github.com/infracost/infracost/internal/config"
+	"github.com/infracost/infracost/internal/output"
 	"github.com/infracost/infracost/internal/ui"
 	"github.com/infracost/infracost/internal/update"
 	"github.com/infracost/infracost/internal/version"
@@ -62,7 +63,7 @@ func Run(modifyCtx func(*config.RunContext), args *[]string) {
 		handleUpdateMessage(updateMessageChan)
 
 		if appErr != nil || unexpectedErr != nil {
-			ctx.Exit(1)
+			ctx.Exit(exitCode(appErr))
 		}
 	}()
 
@@ -76,6 +77,16 @@ func Run(modifyCtx func(*config.RunContext), args *[]string) {
 	appErr = rootCmd.Execute()
 }
 
+// exitCode returns the exit code the application should use for the given error.
+func exitCode(err error) int {
+	var policyFailures output.PolicyCheckFailures
+	if errors.As(err, &policyFailures) {
+		return policyFailureExitCode
+	}
+
+	return 1
+}
+
 func newRootCmd(ctx *config.RunContext) *cobra.Command {
 	rootCmd := &cobra.Command{
 		Use:     "infracost",
@@ -114,7 +125,9 @@ func newRootCmd(ctx *config.RunContext) *cobra.Command {
 	rootCmd.AddCommand(diffCmd(ctx))
 	rootCmd.AddCommand(breakdownCmd(ctx))
 	rootCmd.AddCommand(outputCmd(ctx))
+	rootCmd.AddCommand(compareCmd(ctx))
 	rootCmd.AddCommand(commentCmd(ctx))
+	rootCmd.AddCommand(policyCmd(ctx))
 	rootCmd.AddCommand(completionCmd())
 	rootCmd.AddCommand(figAutocompleteCmd())
 
@@ -262,3 +275,30 @@ func saveOutFileWithMsg(ctx *config.RunContext, cmd *cobra.Command, outFile, suc
 
 	return nil
 }
+
+// addMetricsFlags adds the flags used to push the costs as OpenMetrics to a Pushgateway.
+func addMetricsFlags(cmd *cobra.Command) {
+	cmd.Flags().String("pushgateway-url", "", "Push the costs as OpenMetrics to a Prometheus Pushgateway compatible URL, e.g. http://pushgateway:9091 (experimental)")
+	cmd.Flags().String("metrics-label-level", output.MetricsLabelLevelResource, "Most detailed label of the cost metrics used by openmetrics format and pushgateway-url: resource, resource_type, project (experimental)")
+
+	_ = cmd.RegisterFlagCompletionFunc("metrics-label-level", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return output.MetricsLabelLevels, cobra.ShellCompDirectiveDefault
+	})
+}
+
+// pushMetrics pushes the costs as OpenMetrics to the Pushgateway set with the `--pushgateway-url` flag
+func pushMetrics(pushgatewayURL string, r output.Root, opts output.Options) error {
+	b, err := output.ToOpenMetrics(r, opts)
+	if err != nil {
+		return err
+	}
+
+	err = apiclient.PushMetrics(pushgatewayURL, b)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("Metrics pushed to %s", pushgatewayURL)
+
+	return nil
+}
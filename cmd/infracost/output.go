@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/Rhymond/go-money"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
@@ -23,6 +25,11 @@ var validOutputFormats = []string{
 	"azure-repos-comment",
 	"bitbucket-comment",
 	"slack-message",
+	"csv",
+	"xlsx",
+	"sarif",
+	"junit",
+	"openmetrics",
 }
 
 func outputCmd(ctx *config.RunContext) *cobra.Command {
@@ -56,7 +63,15 @@ func outputCmd(ctx *config.RunContext) *cobra.Command {
 
   Create markdown report to post in a Bitbucket comment:
 
-      infracost output --format bitbucket-comment --path "out*.json" # glob needs quotes`,
+      infracost output --format bitbucket-comment --path "out*.json" # glob needs quotes
+
+  Combine Infracost JSON files in different currencies by converting them to EUR:
+
+      infracost output --path "out*.json" --currency EUR # needs exchange_rates_file in your configuration
+
+  Create markdown report using your own Go template:
+
+      infracost output --format github-comment --path "out*.json" --template-path my-comment.tmpl`,
 		ValidArgs: []string{"--", "-"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var err error
@@ -69,6 +84,12 @@ func outputCmd(ctx *config.RunContext) *cobra.Command {
 				return fmt.Errorf("--format only supports %s", strings.Join(validOutputFormats, ", "))
 			}
 
+			outFile, _ := cmd.Flags().GetString("out-file")
+			if format == "xlsx" && outFile == "" {
+				ui.PrintUsage(cmd)
+				return errors.New("--format xlsx needs --out-file to be set since the output is a binary file")
+			}
+
 			paths, _ := cmd.Flags().GetStringArray("path")
 
 			inputs, err := output.LoadPaths(paths)
@@ -76,46 +97,60 @@ func outputCmd(ctx *config.RunContext) *cobra.Command {
 				return err
 			}
 
+			currency, _ := cmd.Flags().GetString("currency")
+			if currency != "" && money.GetCurrency(currency) == nil {
+				ui.PrintUsage(cmd)
+				return fmt.Errorf("--currency %s is not a valid ISO 4217 currency code", currency)
+			}
+
+			inputs, err = convertInputCurrencies(ctx.Config, inputs, currency)
+			if err != nil {
+				return err
+			}
+
 			combined, err := output.Combine(inputs)
 			if err != nil {
 				return err
 			}
 			combined.IsCIRun = ctx.IsCIRun()
 
-			includeAllFields := "all"
-			validFields := []string{"price", "monthlyQuantity", "unit", "hourlyCost", "monthlyCost"}
-
-			fields := []string{"monthlyQuantity", "unit", "monthlyCost"}
-			if cmd.Flags().Changed("fields") {
-				fields, _ = cmd.Flags().GetStringSlice("fields")
-				if len(fields) == 0 {
-					ui.PrintWarningf(cmd.ErrOrStderr(), "fields is empty, using defaults: %s", cmd.Flag("fields").DefValue)
-				} else if len(fields) == 1 && fields[0] == includeAllFields {
-					fields = validFields
+			if secondary := ctx.Config.SecondaryCurrency; secondary != "" {
+				rate, err := exchangeRate(ctx.Config, combined.Currency, secondary)
+				if err != nil {
+					ui.PrintWarningf(cmd.ErrOrStderr(), "Ignoring secondary currency '%s': %s", secondary, err)
 				} else {
-					vf := []string{}
-					for _, f := range fields {
-						if !contains(validFields, f) {
-							ui.PrintWarningf(cmd.ErrOrStderr(), "Invalid field '%s' specified, valid fields are: %s or '%s' to include all fields", f, validFields, includeAllFields)
-						} else {
-							vf = append(vf, f)
-						}
-					}
-					fields = vf
+					output.AddSecondaryCurrency(&combined, rate)
 				}
 			}
 
+			fields := parseFieldsFlag(cmd)
+			if fields == nil {
+				fields = defaultOutputFields
+			}
+
+			policyPaths, _ := cmd.Flags().GetStringArray("policy-path")
+			policyChecks, err := queryPolicies(ctx, config.Policies{Paths: policyPaths}, combined)
+			if err != nil {
+				return err
+			}
+
 			opts := output.Options{
 				DashboardEnabled: ctx.Config.EnableDashboard,
 				NoColor:          ctx.Config.NoColor,
 				Fields:           fields,
+				PolicyChecks:     policyChecks,
 			}
 			opts.ShowSkipped, _ = cmd.Flags().GetBool("show-skipped")
+			opts.TemplatePath, _ = cmd.Flags().GetString("template-path")
 
-			validFieldsFormats := []string{"table", "html"}
+			opts.MetricsLabelLevel, err = metricsLabelLevelFlag(cmd)
+			if err != nil {
+				return err
+			}
 
-			if cmd.Flags().Changed("fields") && !contains(validFieldsFormats, format) {
-				ui.PrintWarning(cmd.ErrOrStderr(), "fields is only supported for table and html output formats")
+			err = checkOutputFlags(cmd, format, opts)
+			if err != nil {
+				return err
 			}
 
 			if ctx.Config.EnableDashboard {
@@ -126,24 +161,7 @@ func outputCmd(ctx *config.RunContext) *cobra.Command {
 				combined.RunID, combined.ShareURL = shareCombinedRun(ctx, combined, inputs)
 			}
 
-			var b []byte
-
-			switch strings.ToLower(format) {
-			case "json":
-				b, err = output.ToJSON(combined, opts)
-			case "html":
-				b, err = output.ToHTML(combined, opts)
-			case "diff":
-				b, err = output.ToDiff(combined, opts)
-			case "github-comment", "gitlab-comment", "azure-repos-comment":
-				b, err = output.ToMarkdown(combined, opts, output.MarkdownOptions{})
-			case "bitbucket-comment":
-				b, err = output.ToMarkdown(combined, opts, output.MarkdownOptions{BasicSyntax: true})
-			case "slack-message":
-				b, err = output.ToSlackMessage(combined, opts)
-			default:
-				b, err = output.ToTable(combined, opts)
-			}
+			b, err := renderOutput(format, combined, opts)
 			if err != nil {
 				return err
 			}
@@ -154,7 +172,14 @@ func outputCmd(ctx *config.RunContext) *cobra.Command {
 				log.Errorf("Error reporting event: %s", err)
 			}
 
-			if outFile, _ := cmd.Flags().GetString("out-file"); outFile != "" {
+			if pushgatewayURL, _ := cmd.Flags().GetString("pushgateway-url"); pushgatewayURL != "" {
+				err = pushMetrics(pushgatewayURL, combined, opts)
+				if err != nil {
+					return err
+				}
+			}
+
+			if outFile != "" {
 				err = saveOutFile(ctx, cmd, outFile, b)
 				if err != nil {
 					return err
@@ -163,6 +188,10 @@ func outputCmd(ctx *config.RunContext) *cobra.Command {
 				cmd.Println(string(b))
 			}
 
+			if policyChecks.HasFailed() {
+				return policyChecks.Failures
+			}
+
 			return nil
 		},
 	}
@@ -170,9 +199,13 @@ func outputCmd(ctx *config.RunContext) *cobra.Command {
 	cmd.Flags().StringArrayP("path", "p", []string{}, "Path to Infracost JSON files, glob patterns need quotes")
 	cmd.Flags().StringP("out-file", "o", "", "Save output to a file, helpful with format flag")
 
-	cmd.Flags().String("format", "table", "Output format: json, diff, table, html, github-comment, gitlab-comment, azure-repos-comment, bitbucket-comment, slack-message")
+	cmd.Flags().String("format", "table", "Output format: json, diff, table, html, github-comment, gitlab-comment, azure-repos-comment, bitbucket-comment, slack-message, csv, xlsx, sarif, junit, openmetrics")
 	cmd.Flags().Bool("show-skipped", false, "List unsupported and free resources")
 	cmd.Flags().StringSlice("fields", []string{"monthlyQuantity", "unit", "monthlyCost"}, "Comma separated list of output fields: all,price,monthlyQuantity,unit,hourlyCost,monthlyCost.\nSupported by table and html output formats")
+	cmd.Flags().String("currency", "", "Convert the costs to this currency (ISO 4217) using the configured exchange rates")
+	cmd.Flags().StringArray("policy-path", nil, "Path to Infracost policy files, glob patterns need quotes (experimental)")
+	addMetricsFlags(cmd)
+	cmd.Flags().String("template-path", "", "Path to a Go template used instead of the built-in output. Not supported by json, slack-message, csv, xlsx, sarif, junit and openmetrics formats (experimental)")
 
 	_ = cmd.MarkFlagRequired("path")
 	_ = cmd.MarkFlagFilename("path", "json")
@@ -184,6 +217,109 @@ func outputCmd(ctx *config.RunContext) *cobra.Command {
 	return cmd
 }
 
+// defaultOutputFields are the cost component fields shown by the table and html formats when
+// the --fields flag isn't set.
+var defaultOutputFields = []string{"monthlyQuantity", "unit", "monthlyCost"}
+
+// validOutputFields are the cost component fields that can be set with the --fields flag.
+var validOutputFields = []string{"price", "monthlyQuantity", "unit", "hourlyCost", "monthlyCost"}
+
+// parseFieldsFlag returns the output fields set with the --fields flag, or nil if the flag isn't
+// set or is empty. Invalid fields are ignored with a warning and "all" includes every field.
+func parseFieldsFlag(cmd *cobra.Command) []string {
+	if !cmd.Flags().Changed("fields") {
+		return nil
+	}
+
+	includeAllFields := "all"
+
+	fields, _ := cmd.Flags().GetStringSlice("fields")
+	if len(fields) == 0 {
+		ui.PrintWarningf(cmd.ErrOrStderr(), "fields is empty, using defaults: %s", cmd.Flag("fields").DefValue)
+		return nil
+	}
+
+	if len(fields) == 1 && fields[0] == includeAllFields {
+		return validOutputFields
+	}
+
+	vf := []string{}
+	for _, f := range fields {
+		if !contains(validOutputFields, f) {
+			ui.PrintWarningf(cmd.ErrOrStderr(), "Invalid field '%s' specified, valid fields are: %s or '%s' to include all fields", f, validOutputFields, includeAllFields)
+		} else {
+			vf = append(vf, f)
+		}
+	}
+
+	return vf
+}
+
+// metricsLabelLevelFlag returns the --metrics-label-level flag, or an error if it isn't a valid level.
+func metricsLabelLevelFlag(cmd *cobra.Command) (string, error) {
+	level, _ := cmd.Flags().GetString("metrics-label-level")
+
+	if level != "" && !contains(output.MetricsLabelLevels, level) {
+		ui.PrintUsage(cmd)
+		return "", fmt.Errorf("--metrics-label-level only supports %s", strings.Join(output.MetricsLabelLevels, ", "))
+	}
+
+	return level, nil
+}
+
+// checkOutputFlags checks that the output options set by the flags are supported by the format.
+func checkOutputFlags(cmd *cobra.Command, format string, opts output.Options) error {
+	if opts.TemplatePath != "" && contains([]string{"json", "slack-message", "csv", "xlsx", "sarif", "junit", "openmetrics"}, format) {
+		ui.PrintUsage(cmd)
+		return fmt.Errorf("--template-path is not supported with the %s format", format)
+	}
+
+	if cmd.Flags().Changed("fields") && !contains([]string{"table", "html"}, format) {
+		ui.PrintWarning(cmd.ErrOrStderr(), "fields is only supported for table and html output formats")
+	}
+
+	return nil
+}
+
+// renderOutput renders the Infracost output in the format. Formats that aren't known are
+// rendered as a table.
+func renderOutput(format string, root output.Root, opts output.Options) ([]byte, error) {
+	switch strings.ToLower(format) {
+	case "json":
+		return output.ToJSON(root, opts)
+	case "html":
+		return output.ToHTML(root, opts)
+	case "csv":
+		return output.ToCSV(root, opts)
+	case "xlsx":
+		return output.ToXLSX(root, opts)
+	case "sarif":
+		return output.ToSARIF(root, opts)
+	case "junit":
+		return output.ToJUnit(root, opts)
+	case "openmetrics":
+		return output.ToOpenMetrics(root, opts)
+	case "diff":
+		if opts.TemplatePath != "" {
+			return output.ToText(root, opts)
+		}
+
+		return output.ToDiff(root, opts)
+	case "github-comment", "gitlab-comment", "azure-repos-comment":
+		return output.ToMarkdown(root, opts, output.MarkdownOptions{})
+	case "bitbucket-comment":
+		return output.ToMarkdown(root, opts, output.MarkdownOptions{BasicSyntax: true})
+	case "slack-message":
+		return output.ToSlackMessage(root, opts)
+	}
+
+	if opts.TemplatePath != "" {
+		return output.ToText(root, opts)
+	}
+
+	return output.ToTable(root, opts)
+}
+
 func shareCombinedRun(ctx *config.RunContext, combined output.Root, inputs []output.ReportInput) (string, string) {
 	if len(inputs) == 1 && inputs[0].Root.RunID != "" {
 		result := inputs[0].Root
@@ -212,6 +348,62 @@ func shareCombinedRun(ctx *config.RunContext, combined output.Root, inputs []out
 	return result.RunID, result.ShareURL
 }
 
+// convertInputCurrencies converts the costs of the inputs to the currency so they can be combined. If currency is
+// empty, the inputs are only converted to the configured currency if they are in different currencies.
+func convertInputCurrencies(cfg *config.Config, inputs []output.ReportInput, currency string) ([]output.ReportInput, error) {
+	if currency == "" {
+		if !hasMixedCurrencies(inputs) {
+			return inputs, nil
+		}
+
+		if cfg.ExchangeRates == nil {
+			return nil, errors.New("Invalid Infracost JSON file currency mismatch.  Set exchange_rates_file in your configuration to combine files in different currencies")
+		}
+
+		currency = cfg.Currency
+	}
+
+	converted := make([]output.ReportInput, 0, len(inputs))
+
+	for _, input := range inputs {
+		from := input.Root.Currency
+		if from == "" {
+			from = "USD"
+		}
+
+		rate, err := exchangeRate(cfg, from, currency)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error converting %s to %s", input.Metadata["filename"], strings.ToUpper(currency))
+		}
+
+		root, err := output.ConvertCurrency(input.Root, rate)
+		if err != nil {
+			return nil, err
+		}
+
+		converted = append(converted, output.ReportInput{
+			Metadata: input.Metadata,
+			Root:     root,
+		})
+	}
+
+	return converted, nil
+}
+
+func hasMixedCurrencies(inputs []output.ReportInput) bool {
+	currencies := map[string]bool{}
+	for _, input := range inputs {
+		currency := strings.ToUpper(input.Root.Currency)
+		if currency == "" {
+			currency = "USD"
+		}
+
+		currencies[currency] = true
+	}
+
+	return len(currencies) > 1
+}
+
 func contains(arr []string, e string) bool {
 	for _, a := range arr {
 		if a == e {
@@ -1,12 +1,17 @@
 package main_test
 
 import (
+	"io"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"path/filepath"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/infracost/infracost/internal/config"
 	"github.com/infracost/infracost/internal/testutil"
 )
 
@@ -129,3 +134,94 @@ func TestOutputTerraformOutFileTable(t *testing.T) {
 func TestOutputJSONArrayPath(t *testing.T) {
 	GoldenFileCommandTest(t, testutil.CalcGoldenFileTestdataDirName(), []string{"output", "--path", "[\"./testdata/example_out.json\", \"./testdata/terraform_v0.14*breakdown.json\"]"}, nil)
 }
+
+func TestOutputFormatTableWithPolicies(t *testing.T) {
+	GoldenFileCommandTest(t, testutil.CalcGoldenFileTestdataDirName(), []string{"output", "--format", "table", "--path", "./testdata/terraform_v0.14_breakdown.json", "--policy-path", "./testdata/comment_git_hub_with_policies/policy.rego"}, nil)
+}
+
+func TestOutputFormatDiffWithPolicies(t *testing.T) {
+	GoldenFileCommandTest(t, testutil.CalcGoldenFileTestdataDirName(), []string{"output", "--format", "diff", "--path", "./testdata/terraform_v0.14_breakdown.json", "--policy-path", "./testdata/comment_git_hub_with_policies/policy.rego"}, nil)
+}
+
+func TestOutputFormatSarifWithPolicies(t *testing.T) {
+	GoldenFileCommandTest(t, testutil.CalcGoldenFileTestdataDirName(), []string{"output", "--format", "sarif", "--path", "./testdata/terraform_v0.14_breakdown.json", "--policy-path", "./testdata/comment_git_hub_with_policies/policy.rego"}, nil)
+}
+
+func TestOutputFormatJunitWithPolicies(t *testing.T) {
+	GoldenFileCommandTest(t, testutil.CalcGoldenFileTestdataDirName(), []string{"output", "--format", "junit", "--path", "./testdata/terraform_v0.14_breakdown.json", "--policy-path", "./testdata/comment_git_hub_with_policies/policy.rego"}, nil)
+}
+
+func TestOutputFormatOpenmetrics(t *testing.T) {
+	GoldenFileCommandTest(t, testutil.CalcGoldenFileTestdataDirName(), []string{"output", "--format", "openmetrics", "--path", "./testdata/example_out.json", "--path", "./testdata/terraform_v0.14_breakdown.json"}, nil)
+}
+
+func TestOutputFormatOpenmetricsProjectLabelLevel(t *testing.T) {
+	GoldenFileCommandTest(t, testutil.CalcGoldenFileTestdataDirName(), []string{"output", "--format", "openmetrics", "--metrics-label-level", "project", "--path", "./testdata/example_out.json", "--path", "./testdata/terraform_v0.14_breakdown.json"}, nil)
+}
+
+func TestOutputPushgateway(t *testing.T) {
+	testdataName := testutil.CalcGoldenFileTestdataDirName()
+	goldenFilePath := "./testdata/" + testdataName + "/pushed_metrics.golden"
+
+	var method, path string
+	var pushed []byte
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		pushed, _ = io.ReadAll(r.Body)
+	}))
+	defer ts.Close()
+
+	GoldenFileCommandTest(t, testdataName, []string{"output", "--path", "./testdata/terraform_v0.14_breakdown.json", "--metrics-label-level", "resource_type", "--pushgateway-url", ts.URL}, nil)
+
+	assert.Equal(t, http.MethodPut, method)
+	assert.Equal(t, "/metrics/job/infracost", path)
+	testutil.AssertGoldenFile(t, goldenFilePath, pushed)
+}
+
+func TestOutputFormatGitHubCommentWithTemplate(t *testing.T) {
+	GoldenFileCommandTest(t, testutil.CalcGoldenFileTestdataDirName(), []string{"output", "--format", "github-comment", "--path", "./testdata/example_out.json", "--path", "./testdata/terraform_v0.14_breakdown.json", "--path", "./testdata/terraform_v0.14_nochange_breakdown.json", "--template-path", "./testdata/output_format_git_hub_comment_with_template/comment.tmpl"}, nil)
+}
+
+func TestOutputFormatTableWithTemplate(t *testing.T) {
+	GoldenFileCommandTest(t, testutil.CalcGoldenFileTestdataDirName(), []string{"output", "--format", "table", "--path", "./testdata/example_out.json", "--path", "./testdata/azure_firewall_out.json", "--template-path", "./testdata/output_format_table_with_template/summary.tmpl"}, nil)
+}
+
+func TestOutputFormatWithInvalidTemplate(t *testing.T) {
+	GoldenFileCommandTest(t, testutil.CalcGoldenFileTestdataDirName(), []string{"output", "--format", "github-comment", "--path", "./testdata/example_out.json", "--template-path", "./testdata/output_format_with_invalid_template/invalid.tmpl"}, nil)
+}
+
+func TestOutputFormatCSV(t *testing.T) {
+	GoldenFileCommandTest(t, testutil.CalcGoldenFileTestdataDirName(), []string{"output", "--format", "csv", "--path", "./testdata/example_out.json", "--path", "./testdata/terraform_v0.14_breakdown.json"}, nil)
+}
+
+func TestOutputFormatXlsxNoOutFile(t *testing.T) {
+	GoldenFileCommandTest(t, testutil.CalcGoldenFileTestdataDirName(), []string{"output", "--format", "xlsx", "--path", "./testdata/example_out.json"}, nil)
+}
+
+func withExchangeRates(ctx *config.RunContext) {
+	ctx.Config.ExchangeRates = &config.ExchangeRates{
+		Date:  "2022-06-01",
+		Rates: map[string]float64{"EUR": 0.9, "GBP": 0.8},
+	}
+}
+
+func TestOutputFormatTableWithCurrencyConversion(t *testing.T) {
+	GoldenFileCommandTest(t, testutil.CalcGoldenFileTestdataDirName(), []string{"output", "--format", "table", "--path", "./testdata/example_out.json", "--path", "./testdata/azure_firewall_out_eur.json", "--currency", "GBP"}, nil, withExchangeRates)
+}
+
+func TestOutputFormatJSONWithCurrencyConversion(t *testing.T) {
+	opts := DefaultOptions()
+	opts.IsJSON = true
+	GoldenFileCommandTest(t, testutil.CalcGoldenFileTestdataDirName(), []string{"output", "--format", "json", "--path", "./testdata/example_out.json", "--path", "./testdata/azure_firewall_out_eur.json"}, opts, withExchangeRates)
+}
+
+func TestOutputFormatTableWithSecondaryCurrency(t *testing.T) {
+	GoldenFileCommandTest(t, testutil.CalcGoldenFileTestdataDirName(), []string{"output", "--format", "table", "--path", "./testdata/example_out.json"}, nil, withExchangeRates, func(ctx *config.RunContext) {
+		ctx.Config.SecondaryCurrency = "EUR"
+	})
+}
+
+func TestOutputCurrencyMismatch(t *testing.T) {
+	GoldenFileCommandTest(t, testutil.CalcGoldenFileTestdataDirName(), []string{"output", "--path", "./testdata/example_out.json", "--path", "./testdata/azure_firewall_out_eur.json"}, nil)
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/infracost/infracost/internal/config"
+	"github.com/infracost/infracost/internal/output"
+	"github.com/infracost/infracost/internal/policy"
+	"github.com/infracost/infracost/internal/ui"
+)
+
+// policyFailureExitCode is the exit code used when a run completes but one or
+// more cost policies failed, so that CI systems can tell a policy failure apart
+// from an error running Infracost.
+const policyFailureExitCode = 3
+
+// queryPolicies evaluates the Rego policies and guardrails against the Infracost
+// output and records the results on the run context.
+func queryPolicies(ctx *config.RunContext, policies config.Policies, r output.Root) (output.PolicyCheck, error) {
+	var checks output.PolicyCheck
+
+	if len(policies.Paths) == 0 && len(policies.Guardrails) == 0 {
+		return checks, nil
+	}
+
+	if len(policies.Paths) > 0 {
+		regoChecks, err := policy.Query(policies.Paths, r)
+		if err != nil {
+			return checks, err
+		}
+
+		checks.Merge(regoChecks)
+	}
+
+	if len(policies.Guardrails) > 0 {
+		guardrailChecks, err := policy.EvaluateGuardrails(policies.Guardrails, r)
+		if err != nil {
+			return checks, err
+		}
+
+		checks.Merge(guardrailChecks)
+	}
+
+	ctx.SetContextValue("passedPolicyCount", len(checks.Passed))
+	ctx.SetContextValue("failedPolicyCount", len(checks.Failures))
+	ctx.SetContextValue("warnedPolicyCount", len(checks.Warnings))
+	ctx.SetContextValue("guardrailCount", len(policies.Guardrails))
+
+	return checks, nil
+}
+
+func policyCmd(ctx *config.RunContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "policy",
+		Short: "Work with Infracost cost policies",
+		Long:  "Work with Infracost cost policies",
+		Example: `  Test policies against Infracost JSON fixtures:
+
+      infracost policy test --policy-path policies/ --fixtures tests/`,
+		ValidArgs: []string{"--", "-"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(policyTestCmd(ctx))
+
+	return cmd
+}
+
+func policyTestCmd(ctx *config.RunContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test",
+		Short: "Test cost policies against Infracost JSON fixtures",
+		Long: `Test cost policies against Infracost JSON fixtures
+
+Each YAML file in the fixtures directory defines an Infracost JSON fixture and
+the expected outcome (pass, warn or fail) of the policies run against it:
+
+  fixture: large_increase.json
+  expect:
+    cost_increase: fail
+    tagging: pass
+
+Any OPA test_ rules found in the policy paths are also run.`,
+		Example: `  Test policies against fixtures:
+
+      infracost policy test --policy-path policies/ --fixtures tests/
+
+  Only run OPA test_ rules:
+
+      infracost policy test --policy-path policies/`,
+		ValidArgs: []string{"--", "-"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			policyPaths, _ := cmd.Flags().GetStringArray("policy-path")
+			fixturesPath, _ := cmd.Flags().GetString("fixtures")
+
+			var results []policy.TestResult
+
+			if fixturesPath != "" {
+				fixtureResults, err := policy.RunFixtureTests(policyPaths, fixturesPath)
+				if err != nil {
+					return err
+				}
+
+				results = append(results, fixtureResults...)
+			}
+
+			regoResults, err := policy.RunRegoTests(policyPaths)
+			if err != nil {
+				return err
+			}
+			results = append(results, regoResults...)
+
+			if len(results) == 0 {
+				cmd.Println("No policy tests found")
+				return nil
+			}
+
+			cmd.Print(formatPolicyTestResults(results))
+
+			failed := 0
+			for _, r := range results {
+				if !r.Passed() {
+					failed++
+				}
+			}
+
+			ctx.SetContextValue("policyTestCount", len(results))
+			ctx.SetContextValue("failedPolicyTestCount", failed)
+
+			if failed > 0 {
+				return fmt.Errorf("%d of %d policy tests failed", failed, len(results))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArray("policy-path", nil, "Path to Infracost policy files or directories, glob patterns need quotes")
+	_ = cmd.MarkFlagRequired("policy-path")
+	cmd.Flags().String("fixtures", "", "Path to a directory of fixture YAML files that define the expected policy outcomes")
+	_ = cmd.MarkFlagDirname("fixtures")
+
+	return cmd
+}
+
+// formatPolicyTestResults returns a report of the policy test results grouped by policy.
+func formatPolicyTestResults(results []policy.TestResult) string {
+	s := ""
+	passed := 0
+	lastPolicy := ""
+
+	for _, r := range results {
+		if r.Policy != lastPolicy {
+			if lastPolicy != "" {
+				s += "\n"
+			}
+
+			s += fmt.Sprintf("%s %s\n", ui.BoldString("Policy:"), r.Policy)
+			lastPolicy = r.Policy
+		}
+
+		if r.Passed() {
+			passed++
+			s += fmt.Sprintf("  %s %s\n", ui.SuccessString("✔"), r.Name)
+			continue
+		}
+
+		if r.Err != nil {
+			s += fmt.Sprintf("  %s %s: %s\n", ui.ErrorString("✖"), r.Name, r.Err)
+			continue
+		}
+
+		s += fmt.Sprintf("  %s %s: expected %s, got %s\n", ui.ErrorString("✖"), r.Name, r.Expected, r.Actual)
+	}
+
+	s += fmt.Sprintf("\n%d passed, %d failed\n", passed, len(results)-passed)
+
+	return s
+}
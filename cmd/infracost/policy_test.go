@@ -0,0 +1,17 @@
+package main_test
+
+import (
+	"testing"
+
+	"github.com/infracost/infracost/internal/testutil"
+)
+
+func TestPolicyTestHelp(t *testing.T) {
+	GoldenFileCommandTest(t, testutil.CalcGoldenFileTestdataDirName(), []string{"policy", "test", "--help"}, nil)
+}
+
+func TestPolicyTestFixtures(t *testing.T) {
+	GoldenFileCommandTest(t, testutil.CalcGoldenFileTestdataDirName(),
+		[]string{"policy", "test", "--policy-path", "./testdata/policy_test_fixtures/policies", "--fixtures", "./testdata/policy_test_fixtures/fixtures"},
+		nil)
+}
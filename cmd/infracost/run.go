@@ -30,6 +30,7 @@ import (
 	"github.com/infracost/infracost/internal/schema"
 	"github.com/infracost/infracost/internal/ui"
 	"github.com/infracost/infracost/internal/usage"
+	awsusage "github.com/infracost/infracost/internal/usage/aws"
 )
 
 type projectJob struct {
@@ -48,7 +49,7 @@ type hclRunDiff struct {
 	missingResources []string
 }
 
-var validRunFormats = []string{"json", "table", "html"}
+var validRunFormats = []string{"json", "table", "html", "csv", "xlsx", "sarif", "junit", "openmetrics"}
 
 func addRunFlags(cmd *cobra.Command) {
 	cmd.Flags().StringP("path", "p", "", "Path to the Terraform directory or JSON/plan file")
@@ -66,9 +67,17 @@ func addRunFlags(cmd *cobra.Command) {
 
 	cmd.Flags().Bool("sync-usage-file", false, "Sync usage-file with missing resources, needs usage-file too (experimental)")
 
+	cmd.Flags().StringArray("policy-path", nil, "Path to Infracost policy files, glob patterns need quotes (experimental)")
+
+	cmd.Flags().Bool("auto-detect", false, "Find the Terraform root modules, their .tfvars files and Terragrunt configs in path and run each as a project (experimental)")
+	cmd.Flags().StringArray("include-path", nil, "Only include auto-detected projects whose path relative to path matches, glob patterns need quotes (experimental)")
+	cmd.Flags().StringArray("exclude-path", nil, "Exclude auto-detected projects whose path relative to path matches, glob patterns need quotes (experimental)")
+	cmd.Flags().String("write-config-file", "", "Write the auto-detected projects to an Infracost config file at this path (experimental)")
+
 	_ = cmd.MarkFlagFilename("path", "json", "tf")
 	_ = cmd.MarkFlagFilename("config-file", "yml")
 	_ = cmd.MarkFlagFilename("usage-file", "yml")
+	_ = cmd.MarkFlagFilename("write-config-file", "yml")
 }
 
 // panicError is used to collect goroutine panics into an error interface so
@@ -203,6 +212,15 @@ func runMain(cmd *cobra.Command, runCtx *config.RunContext) error {
 	r.IsCIRun = runCtx.IsCIRun()
 	r.Currency = runCtx.Config.Currency
 
+	if runCtx.Config.SecondaryCurrency != "" {
+		rate, err := exchangeRate(runCtx.Config, r.Currency, runCtx.Config.SecondaryCurrency)
+		if err != nil {
+			return err
+		}
+
+		output.AddSecondaryCurrency(&r, rate)
+	}
+
 	dashboardClient := apiclient.NewDashboardAPIClient(runCtx)
 	result, err := dashboardClient.AddRun(runCtx, projectContexts, r)
 	if err != nil {
@@ -211,11 +229,18 @@ func runMain(cmd *cobra.Command, runCtx *config.RunContext) error {
 
 	r.RunID, r.ShareURL = result.RunID, result.ShareURL
 
+	policyChecks, err := queryPolicies(runCtx, runCtx.Config.Policies, r)
+	if err != nil {
+		return err
+	}
+
 	opts := output.Options{
-		DashboardEnabled: runCtx.Config.EnableDashboard,
-		ShowSkipped:      runCtx.Config.ShowSkipped,
-		NoColor:          runCtx.Config.NoColor,
-		Fields:           runCtx.Config.Fields,
+		DashboardEnabled:  runCtx.Config.EnableDashboard,
+		ShowSkipped:       runCtx.Config.ShowSkipped,
+		NoColor:           runCtx.Config.NoColor,
+		Fields:            runCtx.Config.Fields,
+		PolicyChecks:      policyChecks,
+		MetricsLabelLevel: runCtx.Config.MetricsLabelLevel,
 	}
 
 	var b []byte
@@ -225,6 +250,16 @@ func runMain(cmd *cobra.Command, runCtx *config.RunContext) error {
 		b, err = output.ToJSON(r, opts)
 	case "html":
 		b, err = output.ToHTML(r, opts)
+	case "csv":
+		b, err = output.ToCSV(r, opts)
+	case "xlsx":
+		b, err = output.ToXLSX(r, opts)
+	case "sarif":
+		b, err = output.ToSARIF(r, opts)
+	case "junit":
+		b, err = output.ToJUnit(r, opts)
+	case "openmetrics":
+		b, err = output.ToOpenMetrics(r, opts)
 	case "diff":
 		b, err = output.ToDiff(r, opts)
 	default:
@@ -248,6 +283,13 @@ func runMain(cmd *cobra.Command, runCtx *config.RunContext) error {
 		log.Errorf("Error reporting event: %s", err)
 	}
 
+	if runCtx.Config.PushgatewayURL != "" {
+		err = pushMetrics(runCtx.Config.PushgatewayURL, r, opts)
+		if err != nil {
+			return err
+		}
+	}
+
 	if outFile, _ := cmd.Flags().GetString("out-file"); outFile != "" {
 		err = saveOutFile(runCtx, cmd, outFile, b)
 		if err != nil {
@@ -261,6 +303,10 @@ func runMain(cmd *cobra.Command, runCtx *config.RunContext) error {
 		cmd.Println(string(b))
 	}
 
+	if policyChecks.HasFailed() {
+		return policyChecks.Failures
+	}
+
 	return nil
 }
 
@@ -296,10 +342,6 @@ func runProjectConfig(cmd *cobra.Command, runCtx *config.RunContext, ctx *config
 		defer mux.Unlock()
 	}
 
-	for k, v := range projectCfg.Env {
-		os.Setenv(k, v)
-	}
-
 	provider, err := providers.Detect(ctx)
 	if err != nil {
 		m := fmt.Sprintf("%s\n\n", err)
@@ -400,6 +442,15 @@ func runProjectConfig(cmd *cobra.Command, runCtx *config.RunContext, ctx *config
 		return nil, err
 	}
 
+	for _, project := range projects {
+		if len(project.Metadata.MissingVariables) > 0 {
+			ui.PrintWarningf(cmd.ErrOrStderr(),
+				"The following required Terraform variables are not set and will be treated as unknown, so resources using them may have no cost: %s. Set them with --terraform-var, --terraform-var-file or TF_VAR_ environment variables.\n",
+				strings.Join(project.Metadata.MissingVariables, ", "),
+			)
+		}
+	}
+
 	spinnerOpts := ui.SpinnerOptions{
 		EnableLogging: runCtx.Config.IsLogging(),
 		NoColor:       runCtx.Config.NoColor,
@@ -535,7 +586,7 @@ func generateUsageFile(cmd *cobra.Command, runCtx *config.RunContext, projectCtx
 	spinner := ui.NewSpinner("Syncing usage data from cloud", spinnerOpts)
 	defer spinner.Fail()
 
-	syncResult, err := usage.SyncUsageData(usageFile, providerProjects)
+	syncResult, err := usage.SyncUsageData(awsusage.WithProjectEnv(context.Background(), projectCfg.Env), usageFile, providerProjects)
 
 	if err != nil {
 		spinner.Fail()
@@ -631,6 +682,12 @@ func loadRunFlags(cfg *config.Config, cmd *cobra.Command) error {
 		return errors.New(m)
 	}
 
+	autoDetect, _ := cmd.Flags().GetBool("auto-detect")
+	if autoDetect && !hasPathFlag {
+		ui.PrintUsage(cmd)
+		return errors.New("--auto-detect flag needs --path to be set to the directory that should be searched for projects")
+	}
+
 	projectCfg := cfg.Projects[0]
 
 	if hasProjectFlags {
@@ -648,6 +705,13 @@ func loadRunFlags(cfg *config.Config, cmd *cobra.Command) error {
 		}
 	}
 
+	if autoDetect {
+		err := loadAutoDetectedProjects(cmd, cfg)
+		if err != nil {
+			return err
+		}
+	}
+
 	if hasConfigFile {
 		cfgFilePath, _ := cmd.Flags().GetString("config-file")
 		err := cfg.LoadFromConfigFile(cfgFilePath)
@@ -663,6 +727,17 @@ func loadRunFlags(cfg *config.Config, cmd *cobra.Command) error {
 		}
 	}
 
+	if cmd.Flags().Changed("compare-to") {
+		compareTo, _ := cmd.Flags().GetString("compare-to")
+		for _, p := range cfg.Projects {
+			p.TerraformCompareTo = compareTo
+		}
+	}
+
+	if cmd.Flags().Changed("policy-path") {
+		cfg.Policies.Paths, _ = cmd.Flags().GetStringArray("policy-path")
+	}
+
 	cfg.NoCache, _ = cmd.Flags().GetBool("no-cache")
 
 	cfg.Format, _ = cmd.Flags().GetString("format")
@@ -672,9 +747,22 @@ func loadRunFlags(cfg *config.Config, cmd *cobra.Command) error {
 		return fmt.Errorf("--format only supports %s", strings.Join(validRunFormats, ", "))
 	}
 
+	if outFile, _ := cmd.Flags().GetString("out-file"); cfg.Format == "xlsx" && outFile == "" {
+		ui.PrintUsage(cmd)
+		return errors.New("--format xlsx needs --out-file to be set since the output is a binary file")
+	}
+
 	cfg.ShowSkipped, _ = cmd.Flags().GetBool("show-skipped")
 	cfg.SyncUsageFile, _ = cmd.Flags().GetBool("sync-usage-file")
 
+	cfg.PushgatewayURL, _ = cmd.Flags().GetString("pushgateway-url")
+	cfg.MetricsLabelLevel, _ = cmd.Flags().GetString("metrics-label-level")
+
+	if cfg.MetricsLabelLevel != "" && !contains(output.MetricsLabelLevels, cfg.MetricsLabelLevel) {
+		ui.PrintUsage(cmd)
+		return fmt.Errorf("--metrics-label-level only supports %s", strings.Join(output.MetricsLabelLevels, ", "))
+	}
+
 	includeAllFields := "all"
 	validFields := []string{"price", "monthlyQuantity", "unit", "hourlyCost", "monthlyCost"}
 	validFieldsFormats := []string{"table", "html"}
@@ -703,6 +791,49 @@ func loadRunFlags(cfg *config.Config, cmd *cobra.Command) error {
 	return nil
 }
 
+// loadAutoDetectedProjects replaces the project set by the path flag with a project for each Terraform root
+// module and Terragrunt config found in the path. The other project flags are applied to every project.
+func loadAutoDetectedProjects(cmd *cobra.Command, cfg *config.Config) error {
+	projectCfg := cfg.Projects[0]
+
+	opts := providers.DiscoverOptions{}
+	opts.IncludePaths, _ = cmd.Flags().GetStringArray("include-path")
+	opts.ExcludePaths, _ = cmd.Flags().GetStringArray("exclude-path")
+
+	discovered, err := providers.DiscoverProjects(projectCfg.Path, opts)
+	if err != nil {
+		return err
+	}
+
+	if len(discovered) == 0 {
+		return fmt.Errorf("No Terraform root modules or Terragrunt configs found in %s", projectCfg.Path)
+	}
+
+	projects := make([]*config.Project, 0, len(discovered))
+	for _, d := range discovered {
+		p := *projectCfg
+		p.Name = d.Name
+		p.Path = d.Path
+		p.TerraformPlanFlags = strings.TrimSpace(fmt.Sprintf("%s %s", d.TerraformPlanFlags, projectCfg.TerraformPlanFlags))
+		projects = append(projects, &p)
+	}
+
+	log.Debugf("Auto-detected %d projects in %s", len(projects), projectCfg.Path)
+
+	cfg.Projects = projects
+
+	if cfgFilePath, _ := cmd.Flags().GetString("write-config-file"); cfgFilePath != "" {
+		err := config.WriteConfigFile(cfgFilePath, projects)
+		if err != nil {
+			return err
+		}
+
+		ui.PrintSuccessf(cmd.ErrOrStderr(), "Config file for the auto-detected projects saved to %s", cfgFilePath)
+	}
+
+	return nil
+}
+
 func checkRunConfig(warningWriter io.Writer, cfg *config.Config) error {
 	if cfg.Format == "json" && cfg.ShowSkipped {
 		ui.PrintWarning(warningWriter, "show-skipped is not needed with JSON output format as that always includes them.\n")
@@ -729,9 +860,38 @@ func checkRunConfig(warningWriter io.Writer, cfg *config.Config) error {
 		cfg.Currency = "USD"
 	}
 
+	if cfg.SecondaryCurrency != "" {
+		if money.GetCurrency(cfg.SecondaryCurrency) == nil {
+			ui.PrintWarning(warningWriter, fmt.Sprintf("Ignoring unknown secondary currency '%s'.\n", cfg.SecondaryCurrency))
+			cfg.SecondaryCurrency = ""
+		} else if _, err := cfg.ExchangeRates.Rate(cfg.Currency, cfg.SecondaryCurrency); err != nil {
+			ui.PrintWarning(warningWriter, fmt.Sprintf("Ignoring secondary currency '%s': %s.\n", cfg.SecondaryCurrency, err))
+			cfg.SecondaryCurrency = ""
+		}
+	}
+
 	return nil
 }
 
+// exchangeRate returns the configured exchange rate to convert costs from one currency to another.
+func exchangeRate(cfg *config.Config, from, to string) (output.ExchangeRate, error) {
+	rate, err := cfg.ExchangeRates.Rate(from, to)
+	if err != nil {
+		return output.ExchangeRate{}, err
+	}
+
+	r := output.ExchangeRate{
+		From: strings.ToUpper(from),
+		To:   strings.ToUpper(to),
+		Rate: rate,
+	}
+	if cfg.ExchangeRates != nil {
+		r.Date = cfg.ExchangeRates.Date
+	}
+
+	return r, nil
+}
+
 func buildRunEnv(runCtx *config.RunContext, projectContexts []*config.ProjectContext, r output.Root, projects []*schema.Project, hclR *output.Root, hclProjects []*schema.Project) map[string]interface{} {
 	env := runCtx.EventEnvWithProjectContexts(projectContexts)
 	env["projectCount"] = len(projectContexts)
@@ -0,0 +1,29 @@
+package infracost
+
+deny[out] {
+	r := input.projects[_].breakdown.resources[_]
+	r.name == "aws_instance.instance_1"
+
+	out := {
+		"msg": "EC2 instances must not cost more than $1/month",
+		"resource": r.name,
+		"project": input.projects[0].name,
+		"remediation": "Use a smaller instance type",
+		"failed": to_number(r.monthlyCost) > 1,
+	}
+}
+
+deny[out] {
+	out := {
+		"msg": "Total monthly cost increase should be less than $10",
+		"severity": "warn",
+		"failed": to_number(input.diffTotalMonthlyCost) > 10,
+	}
+}
+
+deny[out] {
+	out := {
+		"msg": "Total monthly cost must be less than $1000",
+		"failed": to_number(input.totalMonthlyCost) > 1000,
+	}
+}
@@ -0,0 +1,10 @@
+package infracost
+
+deny[out] {
+	maxDiff := 10.0
+
+	out := {
+		"msg": sprintf("Total monthly cost diff must be less than $%.2f", [maxDiff]),
+		"failed": to_number(input.diffTotalMonthlyCost) >= maxDiff,
+	}
+}
@@ -0,0 +1,9 @@
+package infracost
+
+test_small_increase_passes {
+	count([r | r := deny[_]; r.failed]) == 0 with input as {"diffTotalMonthlyCost": "5"}
+}
+
+test_large_increase_fails {
+	count([r | r := deny[_]; r.failed]) == 1 with input as {"diffTotalMonthlyCost": "50"}
+}
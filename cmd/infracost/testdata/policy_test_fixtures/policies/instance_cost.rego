@@ -0,0 +1,13 @@
+package infracost
+
+deny[out] {
+	r := input.projects[_].breakdown.resources[_]
+	startswith(r.name, "aws_instance.")
+
+	out := {
+		"msg": "EC2 instances should cost less than $1/month",
+		"resource": r.name,
+		"severity": "warn",
+		"failed": to_number(r.monthlyCost) > 1,
+	}
+}
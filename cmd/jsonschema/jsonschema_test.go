@@ -15,7 +15,15 @@ func TestVerifyExample(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	exampleBytes, err := os.ReadFile(schemaFile)
+	for _, filename := range []string{schemaFile, versionedFilename(schemaFile)} {
+		verifySchemaFile(t, generatedBytes, filename)
+	}
+}
+
+func verifySchemaFile(t *testing.T, generatedBytes []byte, filename string) {
+	t.Helper()
+
+	exampleBytes, err := os.ReadFile(filename)
 	if err != nil {
 		t.Fatal(err)
 	}
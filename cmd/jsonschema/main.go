@@ -10,6 +10,7 @@ import (
 	"github.com/infracost/infracost/internal/output"
 	"github.com/shopspring/decimal"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 )
@@ -100,8 +101,21 @@ func subresourcesType() (*jsonschema.Type, error) {
 	return subschema.Definitions["Resource"], nil
 }
 
+// writeOutput writes the JSON schema to the out file, and a copy for the current output version next to it
+// so consumers can validate against a specific version.
 func writeOutput(c config, data []byte) error {
-	return os.WriteFile(c.Filename, data, 0600)
+	err := os.WriteFile(c.Filename, data, 0600)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(versionedFilename(c.Filename), data, 0600)
+}
+
+// versionedFilename returns the filename with the output version added, e.g. infracost.schema.v0.3.json.
+func versionedFilename(filename string) string {
+	ext := filepath.Ext(filename)
+	return fmt.Sprintf("%s.v%s%s", strings.TrimSuffix(filename, ext), output.Version(), ext)
 }
 
 type config struct {
@@ -0,0 +1,52 @@
+package apiclient
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// defaultPushgatewayJob is the job the metrics are grouped under if the Pushgateway URL doesn't set one.
+const defaultPushgatewayJob = "infracost"
+
+// PushMetrics pushes metrics in the Prometheus or OpenMetrics text format to a Pushgateway compatible
+// endpoint. The URL can either be the base URL of the Pushgateway, in which case the metrics are grouped
+// under the infracost job, or include the grouping key, e.g. http://pushgateway:9091/metrics/job/infracost/repo/my-repo.
+// The metrics replace all the metrics in their group so costs of removed resources aren't kept.
+func PushMetrics(pushgatewayURL string, metrics []byte) error {
+	url := pushgatewayMetricsURL(pushgatewayURL)
+
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(metrics))
+	if err != nil {
+		return errors.Wrap(err, "Error generating Pushgateway request")
+	}
+
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	req.Header.Set("User-Agent", userAgent())
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "Error pushing metrics to Pushgateway")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("Error pushing metrics to Pushgateway: %s %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
+
+func pushgatewayMetricsURL(pushgatewayURL string) string {
+	if strings.Contains(pushgatewayURL, "/metrics/job/") {
+		return pushgatewayURL
+	}
+
+	return strings.TrimSuffix(pushgatewayURL, "/") + "/metrics/job/" + defaultPushgatewayJob
+}
@@ -0,0 +1,207 @@
+package comment
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ApprovalCommand is the comment an approver posts to approve a cost change.
+const ApprovalCommand = "/infracost approve"
+
+const (
+	// ApprovalStatePending is the commit status state used while a cost change is waiting for approval.
+	ApprovalStatePending = "pending"
+	// ApprovalStateSuccess is the commit status state used once a cost change is approved or
+	// when it does not require approval.
+	ApprovalStateSuccess = "success"
+)
+
+// defaultApprovalStatusContext is the name of the commit status used if none is set.
+const defaultApprovalStatusContext = "infracost/approval"
+
+// maxStatusDescriptionLength is the maximum length of a commit status description
+// supported by GitHub.
+const maxStatusDescriptionLength = 140
+
+// ApprovalHandler is an interface that is implemented by PlatformHandlers that
+// support requesting approval for a cost change. It is used to call the
+// platform-specific APIs for requesting reviewers, finding approvals and setting
+// the commit status.
+type ApprovalHandler interface {
+	// CallListApprovers calls the platform-specific API to resolve the given
+	// approvers, which can be users or groups, to a list of user names.
+	CallListApprovers(ctx context.Context, approvers []string) ([]string, error)
+
+	// CallFindApprovals calls the platform-specific API to find the approvals
+	// of everyone that has commented ApprovalCommand or reacted with a thumbs up
+	// to one of the given comments.
+	CallFindApprovals(ctx context.Context, comments []Comment) ([]Approval, error)
+
+	// CallGetStatusCreatedAt calls the platform-specific API to get the time the
+	// first commit status with the given context was set on the head commit. This
+	// is set by the platform, so unlike the commit date it can't be backdated. False
+	// is returned if the head commit doesn't have the status yet.
+	CallGetStatusCreatedAt(ctx context.Context, statusContext string) (time.Time, bool, error)
+
+	// CallRequestReviewers calls the platform-specific API to request a review
+	// from the given approvers.
+	CallRequestReviewers(ctx context.Context, approvers []string) error
+
+	// CallSetCommitStatus calls the platform-specific API to set a commit status
+	// on the head commit.
+	CallSetCommitStatus(ctx context.Context, status ApprovalStatus) error
+}
+
+// ApprovalOptions contains the inputs used to check and request approval for a cost change.
+type ApprovalOptions struct {
+	// Required is true if the cost change crossed the approval threshold.
+	Required bool
+	// Approvers are the users or groups that can approve the cost change.
+	Approvers []string
+	// StatusContext is the name of the commit status. If not set, infracost/approval will be used.
+	StatusContext string
+}
+
+// ApprovalStatus is a commit status that reports the approval state of a cost change.
+type ApprovalStatus struct {
+	State       string
+	Description string
+	Context     string
+}
+
+// Approval is an approval of a cost change by a user.
+type Approval struct {
+	User      string
+	CreatedAt time.Time
+}
+
+// ApprovalResult is the outcome of checking the approval for a cost change.
+type ApprovalResult struct {
+	Status     ApprovalStatus
+	ApprovedBy string
+}
+
+// CheckApproval sets the approval commit status for the cost change. If the change
+// requires approval and none of the approvers have approved it yet, a review is
+// requested from the approvers and the status is set to pending. The status is set
+// to success once an approver comments ApprovalCommand or reacts with a thumbs up to
+// an Infracost comment, which is checked the next time this is called. Approvals
+// only count if they were made after the status was first set on the head commit,
+// so a push after an approval needs to be approved again.
+func (h *CommentHandler) CheckApproval(ctx context.Context, opts ApprovalOptions) (ApprovalResult, error) {
+	handler, ok := h.PlatformHandler.(ApprovalHandler)
+	if !ok {
+		return ApprovalResult{}, fmt.Errorf("Approvals are not supported for this platform")
+	}
+
+	statusContext := opts.StatusContext
+	if statusContext == "" {
+		statusContext = defaultApprovalStatusContext
+	}
+
+	result := ApprovalResult{
+		Status: ApprovalStatus{
+			State:       ApprovalStateSuccess,
+			Description: "Cost change does not require approval",
+			Context:     statusContext,
+		},
+	}
+
+	if opts.Required {
+		approvedBy, err := h.findApproval(ctx, handler, opts.Approvers, statusContext)
+		if err != nil {
+			return result, err
+		}
+
+		if approvedBy != "" {
+			result.ApprovedBy = approvedBy
+			result.Status.Description = fmt.Sprintf("Cost change approved by %s", approvedBy)
+		} else {
+			log.Infof("Requesting review from %s", strings.Join(opts.Approvers, ", "))
+
+			err = handler.CallRequestReviewers(ctx, opts.Approvers)
+			if err != nil {
+				return result, err
+			}
+
+			result.Status.State = ApprovalStatePending
+			result.Status.Description = fmt.Sprintf("Cost change requires approval from %s", strings.Join(opts.Approvers, ", "))
+		}
+	}
+
+	result.Status.Description = truncateDescription(result.Status.Description)
+
+	log.Infof("Setting %s commit status to %s", statusContext, result.Status.State)
+
+	err := handler.CallSetCommitStatus(ctx, result.Status)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// findApproval returns the first of the approvers that has approved the cost
+// change of the head commit, or an empty string if nobody has approved it.
+func (h *CommentHandler) findApproval(ctx context.Context, handler ApprovalHandler, approvers []string, statusContext string) (string, error) {
+	// The head commit is evaluated for the first time, so any approvals must be for
+	// an earlier commit.
+	since, ok, err := handler.CallGetStatusCreatedAt(ctx, statusContext)
+	if err != nil || !ok {
+		return "", err
+	}
+
+	users, err := handler.CallListApprovers(ctx, approvers)
+	if err != nil {
+		return "", err
+	}
+
+	comments, err := h.matchingComments(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	approvals, err := handler.CallFindApprovals(ctx, comments)
+	if err != nil {
+		return "", err
+	}
+
+	for _, approval := range approvals {
+		if !approval.CreatedAt.After(since) {
+			continue
+		}
+
+		for _, user := range users {
+			if strings.EqualFold(approval.User, user) {
+				return approval.User, nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// isApprovalComment returns true if the comment body starts with the ApprovalCommand
+// as a whole word, either on its own or followed by whitespace.
+func isApprovalComment(body string) bool {
+	body = strings.TrimSpace(body)
+	if !strings.HasPrefix(body, ApprovalCommand) {
+		return false
+	}
+
+	rest := body[len(ApprovalCommand):]
+	return rest == "" || unicode.IsSpace(rune(rest[0]))
+}
+
+func truncateDescription(s string) string {
+	if len(s) <= maxStatusDescriptionLength {
+		return s
+	}
+
+	return s[:maxStatusDescriptionLength-3] + "..."
+}
@@ -0,0 +1,187 @@
+package comment
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeComment struct {
+	body string
+}
+
+func (c *fakeComment) Body() string        { return c.body }
+func (c *fakeComment) Ref() string         { return "" }
+func (c *fakeComment) Less(o Comment) bool { return false }
+func (c *fakeComment) IsHidden() bool      { return false }
+
+// fakeApprovalHandler is a PlatformHandler and ApprovalHandler that returns the
+// approvals and status time it is set up with, and records the calls made to it.
+type fakeApprovalHandler struct {
+	users           []string
+	approvals       []Approval
+	statusCreatedAt *time.Time
+
+	requestedReviewers []string
+	statuses           []ApprovalStatus
+}
+
+func (h *fakeApprovalHandler) CallFindMatchingComments(ctx context.Context, tag string) ([]Comment, error) {
+	return []Comment{&fakeComment{body: "Infracost estimate"}}, nil
+}
+
+func (h *fakeApprovalHandler) CallCreateComment(ctx context.Context, body string) (Comment, error) {
+	return &fakeComment{body: body}, nil
+}
+
+func (h *fakeApprovalHandler) CallUpdateComment(ctx context.Context, comment Comment, body string) error {
+	return nil
+}
+
+func (h *fakeApprovalHandler) CallDeleteComment(ctx context.Context, comment Comment) error {
+	return nil
+}
+
+func (h *fakeApprovalHandler) CallHideComment(ctx context.Context, comment Comment) error {
+	return nil
+}
+
+func (h *fakeApprovalHandler) AddMarkdownTag(s string, tag string) string {
+	return s
+}
+
+func (h *fakeApprovalHandler) CallListApprovers(ctx context.Context, approvers []string) ([]string, error) {
+	return h.users, nil
+}
+
+func (h *fakeApprovalHandler) CallFindApprovals(ctx context.Context, comments []Comment) ([]Approval, error) {
+	return h.approvals, nil
+}
+
+func (h *fakeApprovalHandler) CallGetStatusCreatedAt(ctx context.Context, statusContext string) (time.Time, bool, error) {
+	if h.statusCreatedAt == nil {
+		return time.Time{}, false, nil
+	}
+
+	return *h.statusCreatedAt, true, nil
+}
+
+func (h *fakeApprovalHandler) CallRequestReviewers(ctx context.Context, approvers []string) error {
+	h.requestedReviewers = append(h.requestedReviewers, approvers...)
+	return nil
+}
+
+func (h *fakeApprovalHandler) CallSetCommitStatus(ctx context.Context, status ApprovalStatus) error {
+	h.statuses = append(h.statuses, status)
+	return nil
+}
+
+func TestCheckApproval(t *testing.T) {
+	pushed := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	before := pushed.Add(-time.Hour)
+	after := pushed.Add(time.Hour)
+
+	tests := []struct {
+		name               string
+		statusCreatedAt    *time.Time
+		approvals          []Approval
+		expectedState      string
+		expectedApprovedBy string
+	}{
+		{
+			name:            "no approval",
+			statusCreatedAt: &pushed,
+			expectedState:   ApprovalStatePending,
+		},
+		{
+			name:               "approval by an approver",
+			statusCreatedAt:    &pushed,
+			approvals:          []Approval{{User: "Alice", CreatedAt: after}},
+			expectedState:      ApprovalStateSuccess,
+			expectedApprovedBy: "Alice",
+		},
+		{
+			name:            "approval by a non-approver",
+			statusCreatedAt: &pushed,
+			approvals:       []Approval{{User: "mallory", CreatedAt: after}},
+			expectedState:   ApprovalStatePending,
+		},
+		{
+			name:            "approval before the head commit",
+			statusCreatedAt: &pushed,
+			approvals:       []Approval{{User: "alice", CreatedAt: before}},
+			expectedState:   ApprovalStatePending,
+		},
+		{
+			name:          "head commit not evaluated yet",
+			approvals:     []Approval{{User: "alice", CreatedAt: after}},
+			expectedState: ApprovalStatePending,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &fakeApprovalHandler{
+				users:           []string{"alice", "bob"},
+				approvals:       tt.approvals,
+				statusCreatedAt: tt.statusCreatedAt,
+			}
+			h := NewCommentHandler(context.Background(), handler, "")
+
+			result, err := h.CheckApproval(context.Background(), ApprovalOptions{
+				Required:  true,
+				Approvers: []string{"my-org/finops"},
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedState, result.Status.State)
+			assert.Equal(t, tt.expectedApprovedBy, result.ApprovedBy)
+			assert.Equal(t, []ApprovalStatus{result.Status}, handler.statuses)
+
+			if tt.expectedState == ApprovalStatePending {
+				assert.Equal(t, []string{"my-org/finops"}, handler.requestedReviewers)
+			} else {
+				assert.Empty(t, handler.requestedReviewers)
+			}
+		})
+	}
+}
+
+func TestCheckApprovalNotRequired(t *testing.T) {
+	handler := &fakeApprovalHandler{}
+	h := NewCommentHandler(context.Background(), handler, "")
+
+	result, err := h.CheckApproval(context.Background(), ApprovalOptions{Approvers: []string{"alice"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, ApprovalStatus{
+		State:       ApprovalStateSuccess,
+		Description: "Cost change does not require approval",
+		Context:     defaultApprovalStatusContext,
+	}, result.Status)
+	assert.Empty(t, handler.requestedReviewers)
+	assert.Equal(t, []ApprovalStatus{result.Status}, handler.statuses)
+}
+
+func TestIsApprovalComment(t *testing.T) {
+	tests := []struct {
+		body     string
+		expected bool
+	}{
+		{"/infracost approve", true},
+		{"  /infracost approve\n", true},
+		{"/infracost approve looks good", true},
+		{"/infracost approve\nthanks", true},
+		{"/infracost approved", false},
+		{"/infracost approve-not", false},
+		{"/infracost approve?", false},
+		{"please /infracost approve", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, isApprovalComment(tt.body), tt.body)
+	}
+}
@@ -305,6 +305,210 @@ func (h *githubPRHandler) AddMarkdownTag(s string, tag string) string {
 	return addMarkdownTag(s, tag)
 }
 
+// CallListApprovers calls the GitHub API to resolve the approvers to user logins.
+// Approvers in the format org/team are expanded to the members of the team.
+func (h *githubPRHandler) CallListApprovers(ctx context.Context, approvers []string) ([]string, error) {
+	var users []string
+
+	for _, approver := range approvers {
+		approver = strings.TrimPrefix(approver, "@")
+
+		org, team, isTeam := splitGitHubTeam(approver)
+		if !isTeam {
+			users = append(users, approver)
+			continue
+		}
+
+		opts := &github.TeamListTeamMembersOptions{ListOptions: github.ListOptions{PerPage: 100}}
+		for {
+			members, resp, err := h.v3client.Teams.ListTeamMembersBySlug(ctx, org, team, opts)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Error listing members of team %s", approver)
+			}
+
+			for _, m := range members {
+				users = append(users, m.GetLogin())
+			}
+
+			if resp.NextPage == 0 {
+				break
+			}
+			opts.Page = resp.NextPage
+		}
+	}
+
+	return users, nil
+}
+
+// CallFindApprovals calls the GitHub API to find the approvals of the users that have
+// commented the approval command on the pull request or reacted with a thumbs up to
+// one of the given comments.
+func (h *githubPRHandler) CallFindApprovals(ctx context.Context, comments []Comment) ([]Approval, error) {
+	var approvals []Approval
+
+	opts := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		prComments, resp, err := h.v3client.Issues.ListComments(ctx, h.owner, h.repo, h.prNumber, opts)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error listing pull request comments")
+		}
+
+		for _, c := range prComments {
+			if isApprovalComment(c.GetBody()) {
+				approvals = append(approvals, Approval{User: c.GetUser().GetLogin(), CreatedAt: c.GetCreatedAt()})
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	for _, comment := range comments {
+		page := 1
+		for {
+			reactions, resp, err := h.listIssueCommentReactions(ctx, int64(comment.(*githubComment).id), page)
+			if err != nil {
+				return nil, errors.Wrap(err, "Error listing comment reactions")
+			}
+
+			for _, r := range reactions {
+				if r.Content == "+1" {
+					approvals = append(approvals, Approval{User: r.User.Login, CreatedAt: r.CreatedAt})
+				}
+			}
+
+			if resp.NextPage == 0 {
+				break
+			}
+			page = resp.NextPage
+		}
+	}
+
+	return approvals, nil
+}
+
+// githubReaction is a reaction to a GitHub comment. The go-github Reaction type
+// does not include the time the reaction was created, so this is used instead.
+type githubReaction struct {
+	Content string `json:"content"`
+	User    struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// listIssueCommentReactions calls the GitHub API to list a page of the reactions
+// to the issue comment.
+func (h *githubPRHandler) listIssueCommentReactions(ctx context.Context, id int64, page int) ([]githubReaction, *github.Response, error) {
+	u := fmt.Sprintf("repos/%s/%s/issues/comments/%d/reactions?per_page=100&page=%d", h.owner, h.repo, id, page)
+
+	req, err := h.v3client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var reactions []githubReaction
+	resp, err := h.v3client.Do(ctx, req, &reactions)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return reactions, resp, nil
+}
+
+// CallGetStatusCreatedAt calls the GitHub API to get the time the first commit status
+// with the given context was set on the head commit of the pull request.
+func (h *githubPRHandler) CallGetStatusCreatedAt(ctx context.Context, statusContext string) (time.Time, bool, error) {
+	pr, _, err := h.v3client.PullRequests.Get(ctx, h.owner, h.repo, h.prNumber)
+	if err != nil {
+		return time.Time{}, false, errors.Wrap(err, "Error getting pull request")
+	}
+
+	var createdAt time.Time
+	found := false
+
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		statuses, resp, err := h.v3client.Repositories.ListStatuses(ctx, h.owner, h.repo, pr.GetHead().GetSHA(), opts)
+		if err != nil {
+			return time.Time{}, false, errors.Wrap(err, "Error listing commit statuses")
+		}
+
+		for _, s := range statuses {
+			if s.GetContext() != statusContext {
+				continue
+			}
+
+			if !found || s.GetCreatedAt().Before(createdAt) {
+				createdAt = s.GetCreatedAt()
+				found = true
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return createdAt, found, nil
+}
+
+// CallRequestReviewers calls the GitHub API to request a review on the pull request
+// from the approvers. Approvers in the format org/team are requested as team reviewers.
+func (h *githubPRHandler) CallRequestReviewers(ctx context.Context, approvers []string) error {
+	req := github.ReviewersRequest{}
+
+	for _, approver := range approvers {
+		approver = strings.TrimPrefix(approver, "@")
+
+		if _, team, isTeam := splitGitHubTeam(approver); isTeam {
+			req.TeamReviewers = append(req.TeamReviewers, team)
+		} else {
+			req.Reviewers = append(req.Reviewers, approver)
+		}
+	}
+
+	_, _, err := h.v3client.PullRequests.RequestReviewers(ctx, h.owner, h.repo, h.prNumber, req)
+	if err != nil {
+		return errors.Wrap(err, "Error requesting reviewers")
+	}
+
+	return nil
+}
+
+// CallSetCommitStatus calls the GitHub API to set a commit status on the head commit
+// of the pull request.
+func (h *githubPRHandler) CallSetCommitStatus(ctx context.Context, status ApprovalStatus) error {
+	pr, _, err := h.v3client.PullRequests.Get(ctx, h.owner, h.repo, h.prNumber)
+	if err != nil {
+		return errors.Wrap(err, "Error getting pull request")
+	}
+
+	_, _, err = h.v3client.Repositories.CreateStatus(ctx, h.owner, h.repo, pr.GetHead().GetSHA(), &github.RepoStatus{
+		State:       github.String(status.State),
+		Description: github.String(status.Description),
+		Context:     github.String(status.Context),
+	})
+	if err != nil {
+		return errors.Wrap(err, "Error setting commit status")
+	}
+
+	return nil
+}
+
+// splitGitHubTeam parses an approver in the format org/team into its org and team slug parts.
+func splitGitHubTeam(approver string) (string, string, bool) {
+	parts := strings.SplitN(approver, "/", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+
+	return parts[0], parts[1], true
+}
+
 // githubCommitHandler is a PlatformHandler for GitHub commits. It
 // implements the PlatformHandler interface and contains the functions
 // for finding, creating, updating, deleting and hiding comments on GitHub commits.
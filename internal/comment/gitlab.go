@@ -5,11 +5,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/shurcooL/graphql"
@@ -297,6 +299,303 @@ func (h *gitlabPRHandler) AddMarkdownTag(s string, tag string) string {
 	return addMarkdownTag(s, tag)
 }
 
+// gitlabUser is a GitLab user as returned by the REST API.
+type gitlabUser struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+}
+
+// CallListApprovers calls the GitLab API to resolve the approvers to usernames.
+// Approvers that are groups are expanded to the members of the group.
+func (h *gitlabPRHandler) CallListApprovers(ctx context.Context, approvers []string) ([]string, error) {
+	users, err := h.listApproverUsers(ctx, approvers)
+	if err != nil {
+		return nil, err
+	}
+
+	usernames := make([]string, 0, len(users))
+	for _, u := range users {
+		usernames = append(usernames, u.Username)
+	}
+
+	return usernames, nil
+}
+
+// CallFindApprovals calls the GitLab API to find the approvals of the users that have
+// commented the approval command on the merge request or reacted with a thumbs up to
+// one of the given comments.
+func (h *gitlabPRHandler) CallFindApprovals(ctx context.Context, comments []Comment) ([]Approval, error) {
+	var approvals []Approval
+
+	page := "1"
+	for page != "" {
+		var notes []struct {
+			Body      string     `json:"body"`
+			Author    gitlabUser `json:"author"`
+			CreatedAt time.Time  `json:"created_at"`
+		}
+
+		notesURL := fmt.Sprintf("%s/api/v4/projects/%s/merge_requests/%d/notes?per_page=100&page=%s", h.serverURL, url.PathEscape(h.project), h.mrNumber, page)
+		res, err := h.callAPI(ctx, "GET", notesURL, nil, &notes)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error listing merge request comments")
+		}
+
+		for _, n := range notes {
+			if isApprovalComment(n.Body) {
+				approvals = append(approvals, Approval{User: n.Author.Username, CreatedAt: n.CreatedAt})
+			}
+		}
+
+		page = res.Header.Get("X-Next-Page")
+	}
+
+	for _, comment := range comments {
+		page := "1"
+		for page != "" {
+			var emoji []struct {
+				Name      string     `json:"name"`
+				User      gitlabUser `json:"user"`
+				CreatedAt time.Time  `json:"created_at"`
+			}
+
+			emojiURL := fmt.Sprintf("%s/api/v4/projects/%s/merge_requests/%d/notes/%s/award_emoji?per_page=100&page=%s", h.serverURL, url.PathEscape(h.project), h.mrNumber, gitlabNoteID(comment.(*gitlabComment).id), page)
+			res, err := h.callAPI(ctx, "GET", emojiURL, nil, &emoji)
+			if err != nil {
+				return nil, errors.Wrap(err, "Error listing comment reactions")
+			}
+
+			for _, e := range emoji {
+				if e.Name == "thumbsup" {
+					approvals = append(approvals, Approval{User: e.User.Username, CreatedAt: e.CreatedAt})
+				}
+			}
+
+			page = res.Header.Get("X-Next-Page")
+		}
+	}
+
+	return approvals, nil
+}
+
+// CallGetStatusCreatedAt calls the GitLab API to get the time the first commit status
+// with the given name was set on the head commit of the merge request.
+func (h *gitlabPRHandler) CallGetStatusCreatedAt(ctx context.Context, statusContext string) (time.Time, bool, error) {
+	var mr struct {
+		SHA string `json:"sha"`
+	}
+
+	mrURL := fmt.Sprintf("%s/api/v4/projects/%s/merge_requests/%d", h.serverURL, url.PathEscape(h.project), h.mrNumber)
+	_, err := h.callAPI(ctx, "GET", mrURL, nil, &mr)
+	if err != nil {
+		return time.Time{}, false, errors.Wrap(err, "Error getting merge request")
+	}
+
+	var createdAt time.Time
+	found := false
+
+	page := "1"
+	for page != "" {
+		var statuses []struct {
+			Name      string    `json:"name"`
+			CreatedAt time.Time `json:"created_at"`
+		}
+
+		statusesURL := fmt.Sprintf("%s/api/v4/projects/%s/repository/commits/%s/statuses?name=%s&all=true&per_page=100&page=%s", h.serverURL, url.PathEscape(h.project), mr.SHA, url.QueryEscape(statusContext), page)
+		res, err := h.callAPI(ctx, "GET", statusesURL, nil, &statuses)
+		if err != nil {
+			return time.Time{}, false, errors.Wrap(err, "Error listing commit statuses")
+		}
+
+		for _, s := range statuses {
+			if s.Name != statusContext {
+				continue
+			}
+
+			if !found || s.CreatedAt.Before(createdAt) {
+				createdAt = s.CreatedAt
+				found = true
+			}
+		}
+
+		page = res.Header.Get("X-Next-Page")
+	}
+
+	return createdAt, found, nil
+}
+
+// CallRequestReviewers calls the GitLab API to add the approvers as reviewers of the
+// merge request. Existing reviewers are kept.
+func (h *gitlabPRHandler) CallRequestReviewers(ctx context.Context, approvers []string) error {
+	users, err := h.listApproverUsers(ctx, approvers)
+	if err != nil {
+		return err
+	}
+
+	var mr struct {
+		Reviewers []gitlabUser `json:"reviewers"`
+	}
+
+	mrURL := fmt.Sprintf("%s/api/v4/projects/%s/merge_requests/%d", h.serverURL, url.PathEscape(h.project), h.mrNumber)
+	_, err = h.callAPI(ctx, "GET", mrURL, nil, &mr)
+	if err != nil {
+		return errors.Wrap(err, "Error getting merge request")
+	}
+
+	seen := map[int]bool{}
+	reviewerIDs := []int{}
+	for _, u := range append(mr.Reviewers, users...) {
+		if !seen[u.ID] {
+			seen[u.ID] = true
+			reviewerIDs = append(reviewerIDs, u.ID)
+		}
+	}
+
+	_, err = h.callAPI(ctx, "PUT", mrURL, map[string]interface{}{"reviewer_ids": reviewerIDs}, nil)
+	if err != nil {
+		return errors.Wrap(err, "Error requesting reviewers")
+	}
+
+	return nil
+}
+
+// CallSetCommitStatus calls the GitLab API to set a commit status on the head commit
+// of the merge request.
+func (h *gitlabPRHandler) CallSetCommitStatus(ctx context.Context, status ApprovalStatus) error {
+	var mr struct {
+		SHA string `json:"sha"`
+	}
+
+	mrURL := fmt.Sprintf("%s/api/v4/projects/%s/merge_requests/%d", h.serverURL, url.PathEscape(h.project), h.mrNumber)
+	_, err := h.callAPI(ctx, "GET", mrURL, nil, &mr)
+	if err != nil {
+		return errors.Wrap(err, "Error getting merge request")
+	}
+
+	statusURL := fmt.Sprintf("%s/api/v4/projects/%s/statuses/%s", h.serverURL, url.PathEscape(h.project), mr.SHA)
+	_, err = h.callAPI(ctx, "POST", statusURL, map[string]interface{}{
+		"state":       status.State,
+		"name":        status.Context,
+		"description": status.Description,
+	}, nil)
+	if err != nil {
+		return errors.Wrap(err, "Error setting commit status")
+	}
+
+	return nil
+}
+
+// listApproverUsers resolves the approvers to GitLab users. Each approver is
+// first looked up as a group, and if no group exists it is looked up as a user.
+func (h *gitlabPRHandler) listApproverUsers(ctx context.Context, approvers []string) ([]gitlabUser, error) {
+	var users []gitlabUser
+
+	for _, approver := range approvers {
+		approver = strings.TrimPrefix(approver, "@")
+
+		members, isGroup, err := h.listGroupMembers(ctx, approver)
+		if err != nil {
+			return nil, err
+		}
+		if isGroup {
+			users = append(users, members...)
+			continue
+		}
+
+		var found []gitlabUser
+		usersURL := fmt.Sprintf("%s/api/v4/users?username=%s", h.serverURL, url.QueryEscape(approver))
+		_, err = h.callAPI(ctx, "GET", usersURL, nil, &found)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error finding user %s", approver)
+		}
+		if len(found) == 0 {
+			return nil, errors.Errorf("No GitLab user or group found for approver %s", approver)
+		}
+
+		users = append(users, found[0])
+	}
+
+	return users, nil
+}
+
+// listGroupMembers lists all the members of the GitLab group. If no group
+// exists with the given name then false is returned.
+func (h *gitlabPRHandler) listGroupMembers(ctx context.Context, group string) ([]gitlabUser, bool, error) {
+	var users []gitlabUser
+
+	page := "1"
+	for page != "" {
+		var members []gitlabUser
+
+		membersURL := fmt.Sprintf("%s/api/v4/groups/%s/members/all?per_page=100&page=%s", h.serverURL, url.PathEscape(group), page)
+		res, err := h.callAPI(ctx, "GET", membersURL, nil, &members)
+		if err != nil {
+			if res != nil && res.StatusCode == http.StatusNotFound {
+				return nil, false, nil
+			}
+			return nil, false, errors.Wrapf(err, "Error listing members of group %s", group)
+		}
+
+		users = append(users, members...)
+
+		page = res.Header.Get("X-Next-Page")
+	}
+
+	return users, true, nil
+}
+
+// callAPI calls the GitLab REST API and unmarshals the response into resData if
+// it is not nil. An error is returned if the response status is not successful,
+// in which case the response is also returned so the status can be checked.
+func (h *gitlabPRHandler) callAPI(ctx context.Context, method, apiURL string, reqData interface{}, resData interface{}) (*http.Response, error) {
+	var reqBody io.Reader
+	if reqData != nil {
+		b, err := json.Marshal(reqData)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error marshaling request body")
+		}
+		reqBody = bytes.NewBuffer(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, apiURL, reqBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := h.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res, errors.New(res.Status)
+	}
+
+	if resData == nil {
+		return res, nil
+	}
+
+	resBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return res, errors.Wrap(err, "Error reading response body")
+	}
+
+	err = json.Unmarshal(resBody, resData)
+	if err != nil {
+		return res, errors.Wrap(err, "Error unmarshaling response body")
+	}
+
+	return res, nil
+}
+
+// gitlabNoteID returns the numeric ID of a note. Notes found using the GraphQL API
+// have a global ID in the format gid://gitlab/Note/123.
+func gitlabNoteID(id string) string {
+	return id[strings.LastIndex(id, "/")+1:]
+}
+
 // gitlabCommitHandler is a PlatformHandler for GitLab commits. It
 // implements the PlatformHandler interface and contains the functions
 // for finding, creating, updating, deleting comments on GitLab commits.
@@ -17,15 +17,21 @@ import (
 // to provide flags every run. Fields are documented below. More info
 // is outlined here: https://www.infracost.io/config-file
 type Project struct {
+	// Name is an optional name for the project. If it is not set, a name is generated from the VCS repo
+	// or the path of the project.
+	Name string `yaml:"name,omitempty" ignored:"true"`
 	// Path to the Terraform directory or JSON/plan file.
 	// A path can be repeated with different parameters, e.g. for multiple workspaces.
 	Path string `yaml:"path,omitempty" ignored:"true"`
 	// TerraformParseHCL will run a project by parsing hcl files the given Path rather than using a plan.json or terraform binary.
 	TerraformParseHCL bool `yaml:"hcl_only,omitempty"`
 	// TerraformVarFiles is the number of var files that are needed to run an TerraformParseHCL run
-	TerraformVarFiles []string `yaml:"terraform_var_files"`
+	TerraformVarFiles []string `yaml:"terraform_var_files,omitempty"`
 	// TerraformVars is a slice of input vars that is used to run an TerraformParseHCL run
-	TerraformVars []string `json:"terraform_vars"`
+	TerraformVars []string `yaml:"terraform_vars,omitempty" json:"terraform_vars"`
+	// TerraformCompareTo is a git ref whose HCL is parsed as the prior state of a TerraformParseHCL run,
+	// so the diff shows the cost changes between that ref and the current files.
+	TerraformCompareTo string `yaml:"terraform_compare_to,omitempty" ignored:"true"`
 	// TerraformPlanFlags are flags to pass to terraform plan with Terraform directory paths
 	TerraformPlanFlags string `yaml:"terraform_plan_flags,omitempty" ignored:"true"`
 	// TerraformInitFlags are flags to pass to terraform init
@@ -41,12 +47,56 @@ type Project struct {
 	// Only applicable for terraform cloud/enterprise users.
 	TerraformCloudToken string `yaml:"terraform_cloud_token,omitempty" envconfig:"INFRACOST_TERRAFORM_CLOUD_TOKEN"`
 	// TerragruntFlags set additional flags that should be passed to terragrunt.
-	TerragruntFlags string `envconfig:"INFRACOST_TERRAGRUNT_FLAGS"`
+	TerragruntFlags string `yaml:"-" envconfig:"INFRACOST_TERRAGRUNT_FLAGS"`
 	// UsageFile is the full path to usage file that specifies values for usage-based resources
 	UsageFile string `yaml:"usage_file,omitempty" ignored:"true"`
 	// TerraformUseState sets if the users wants to use the terraform state for infracost ops.
-	TerraformUseState bool              `yaml:"terraform_use_state,omitempty" ignored:"true"`
-	Env               map[string]string `yaml:"env,omitempty" ignored:"true"`
+	TerraformUseState bool `yaml:"terraform_use_state,omitempty" ignored:"true"`
+	// Env are environment variables that are only set for this project. They are passed to the commands
+	// run for the project and used when parsing its HCL, but never set in the process environment, so
+	// projects that run in parallel can't see each other's values.
+	Env map[string]string `yaml:"env,omitempty" ignored:"true"`
+}
+
+// LookupEnv returns the value of the environment variable for the project. Variables set in the
+// project Env take precedence over the process environment.
+func (p *Project) LookupEnv(key string) (string, bool) {
+	if v, ok := p.Env[key]; ok {
+		return v, true
+	}
+
+	return os.LookupEnv(key)
+}
+
+// Policies defines the cost policies that are evaluated against the output of a run.
+// More info is outlined here: https://www.infracost.io/config-file
+type Policies struct {
+	// Paths to Infracost Rego policy files, glob patterns are supported.
+	Paths []string `yaml:"paths,omitempty"`
+	// Guardrails are declarative cost policies that don't require writing Rego.
+	Guardrails []*Guardrail `yaml:"guardrails,omitempty"`
+}
+
+// Guardrail defines a set of cost thresholds and resource rules that are checked
+// against the projects of a run. Any threshold that is not set is not checked.
+type Guardrail struct {
+	// Name is used to identify the guardrail in the policy check output.
+	Name string `yaml:"name,omitempty"`
+	// Paths limits the guardrail to projects with a matching path. Glob patterns are supported
+	// and a directory matches any project beneath it. If empty the guardrail applies to all projects.
+	Paths []string `yaml:"paths,omitempty"`
+	// Severity is either error (default) which fails the run, or warn which only reports the result.
+	Severity string `yaml:"severity,omitempty"`
+	// MaxMonthlyIncrease is the maximum increase in a project's monthly cost.
+	MaxMonthlyIncrease *float64 `yaml:"max_monthly_increase,omitempty"`
+	// MaxPercentIncrease is the maximum percentage increase in a project's monthly cost.
+	MaxPercentIncrease *float64 `yaml:"max_percent_increase,omitempty"`
+	// MaxResourceMonthlyCost is the maximum monthly cost of any single resource.
+	MaxResourceMonthlyCost *float64 `yaml:"max_resource_monthly_cost,omitempty"`
+	// ForbidResourceTypes are resource types that are not allowed in a project, e.g. aws_nat_gateway.
+	ForbidResourceTypes []string `yaml:"forbid_resource_types,omitempty"`
+	// RequireTags are tag keys that every resource with a cost must have.
+	RequireTags []string `yaml:"require_tags,omitempty"`
 }
 
 type Config struct {
@@ -65,13 +115,26 @@ type Config struct {
 	DashboardAPIEndpoint      string `yaml:"dashboard_api_endpoint,omitempty" envconfig:"INFRACOST_DASHBOARD_API_ENDPOINT"`
 	EnableDashboard           bool   `yaml:"enable_dashboard,omitempty" envconfig:"INFRACOST_ENABLE_DASHBOARD"`
 	DisableHCLParsing         bool   `yaml:"disable_hcl_parsing,omitempty" envconfig:"INFRACOST_DISABLE_HCL_PARSING"`
+	// TerraformModuleCacheDir is the directory where modules downloaded by the HCL parser are cached and shared
+	// between projects. Defaults to the infracost directory in the user's cache directory.
+	TerraformModuleCacheDir string `yaml:"terraform_module_cache_dir,omitempty" envconfig:"INFRACOST_TERRAFORM_MODULE_CACHE_DIR"`
+	// DisableModuleCache stops the HCL parser from using the shared module cache, so modules are downloaded
+	// into each project's .infracost directory.
+	DisableModuleCache bool `yaml:"disable_module_cache,omitempty" envconfig:"INFRACOST_DISABLE_MODULE_CACHE"`
 
 	TLSInsecureSkipVerify *bool  `envconfig:"INFRACOST_TLS_INSECURE_SKIP_VERIFY"`
 	TLSCACertFile         string `envconfig:"INFRACOST_TLS_CA_CERT_FILE"`
 
 	Currency string `envconfig:"INFRACOST_CURRENCY"`
+	// SecondaryCurrency is a currency that the total costs are also shown in, converted using the ExchangeRates.
+	SecondaryCurrency string `envconfig:"INFRACOST_SECONDARY_CURRENCY"`
+	// ExchangeRatesFile is the path to a file with the exchange rates used to convert costs between currencies.
+	ExchangeRatesFile string `envconfig:"INFRACOST_EXCHANGE_RATES_FILE"`
+	// ExchangeRates are loaded from the ExchangeRatesFile and the exchange rates in the configuration file.
+	ExchangeRates *ExchangeRates `ignored:"true"`
 
 	Projects      []*Project `yaml:"projects" ignored:"true"`
+	Policies      Policies   `yaml:"policies,omitempty" ignored:"true"`
 	Format        string     `yaml:"format,omitempty" ignored:"true"`
 	ShowSkipped   bool       `yaml:"show_skipped,omitempty" ignored:"true"`
 	SyncUsageFile bool       `yaml:"sync_usage_file,omitempty" ignored:"true"`
@@ -79,6 +142,9 @@ type Config struct {
 
 	NoCache bool `yaml:"fields,omitempty" ignored:"true"`
 
+	PushgatewayURL    string `yaml:"pushgateway_url,omitempty" ignored:"true"`
+	MetricsLabelLevel string `yaml:"metrics_label_level,omitempty" ignored:"true"`
+
 	SkipErrLine bool
 
 	// for testing
@@ -120,6 +186,7 @@ func (c *Config) LoadFromConfigFile(path string) error {
 	}
 
 	c.Projects = cfgFile.Projects
+	c.Policies = cfgFile.Policies
 
 	// Reload the environment to overwrite any of the config file configs
 	err = c.LoadFromEnv()
@@ -215,6 +282,26 @@ func (c *Config) IsSelfHosted() bool {
 	return c.PricingAPIEndpoint != "" && c.PricingAPIEndpoint != c.DefaultPricingAPIEndpoint
 }
 
+// ModuleCacheDir returns the directory of the shared module cache, or an empty
+// string if the shared module cache is disabled.
+func (c *Config) ModuleCacheDir() string {
+	if c.DisableModuleCache {
+		return ""
+	}
+
+	if c.TerraformModuleCacheDir != "" {
+		return c.TerraformModuleCacheDir
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		logrus.Debugf("Could not find user cache directory, disabling shared module cache: %s", err)
+		return ""
+	}
+
+	return filepath.Join(dir, "infracost", "terraform_modules")
+}
+
 func IsTest() bool {
 	return os.Getenv("INFRACOST_ENV") == "test" || strings.HasSuffix(os.Args[0], ".test")
 }
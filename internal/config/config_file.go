@@ -22,6 +22,14 @@ var (
 	ErrorNilProjects       = errors.New("no projects specified in config file, please specify at least one project, see https://infracost.io/config-file for file specification")
 )
 
+// legacyProjectKeys are the keys that the TerraformVars and TerragruntFlags project fields were
+// read from before they had explicit yaml keys. They are still accepted so existing config files
+// keep working.
+var legacyProjectKeys = map[string]struct{}{
+	"terraformvars":   {},
+	"terragruntflags": {},
+}
+
 // YamlError is a custom error type that allows setting multiple
 // error messages under a base message. It is used to decipher
 // between internal errors and the yaml.v2 errors.
@@ -111,6 +119,7 @@ func (y *YamlError) Error() string {
 type fileSpec struct {
 	Version  string     `yaml:"version"`
 	Projects []*Project `yaml:"projects" ignored:"true"`
+	Policies Policies   `yaml:"policies,omitempty" ignored:"true"`
 }
 
 // UnmarshalYAML implements the yaml.v2.Unmarshaller interface. Marshalls the
@@ -140,6 +149,10 @@ func (f *fileSpec) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		allowedKeys[strings.TrimSpace(pieces[0])] = struct{}{}
 	}
 
+	for k := range legacyProjectKeys {
+		allowedKeys[k] = struct{}{}
+	}
+
 	if len(r.Projects) == 0 {
 		return &YamlError{raw: ErrorNilProjects}
 	}
@@ -201,11 +214,49 @@ func (f *fileSpec) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		return &YamlError{raw: ErrorInvalidConfigFile}
 	}
 
+	for i, fields := range r.Projects {
+		err = setLegacyProjectKeys(c.Projects[i], fields)
+		if err != nil {
+			return &YamlError{raw: ErrorInvalidConfigFile}
+		}
+	}
+
 	f.Version = c.Version
 	f.Projects = c.Projects
+	f.Policies = c.Policies
 	return nil
 }
 
+// setLegacyProjectKeys sets the project fields from any legacyProjectKeys in the project config.
+// The current keys take precedence if both are set.
+func setLegacyProjectKeys(p *Project, fields map[string]interface{}) error {
+	if v, ok := fields["terraformvars"]; ok && len(p.TerraformVars) == 0 {
+		err := convertYamlValue(v, &p.TerraformVars)
+		if err != nil {
+			return err
+		}
+	}
+
+	if v, ok := fields["terragruntflags"]; ok {
+		err := convertYamlValue(v, &p.TerragruntFlags)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// convertYamlValue converts a value decoded from yaml into out.
+func convertYamlValue(v interface{}, out interface{}) error {
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return yaml.UnmarshalStrict(b, out)
+}
+
 func loadConfigFile(path string) (fileSpec, error) {
 	var cfgFile fileSpec
 
@@ -238,6 +289,40 @@ func loadConfigFile(path string) (fileSpec, error) {
 	return cfgFile, nil
 }
 
+// WriteConfigFile writes the projects to a config file at the given path, so that projects found by
+// auto-detection can be committed and edited. Every project field that is set is written, except for
+// the Terraform Cloud token, which should be set with INFRACOST_TERRAFORM_CLOUD_TOKEN rather than
+// committed.
+func WriteConfigFile(path string, projects []*Project) error {
+	type writeSpec struct {
+		Version  string     `yaml:"version"`
+		Projects []*Project `yaml:"projects"`
+	}
+
+	spec := writeSpec{
+		Version:  maxConfigFileVersion,
+		Projects: make([]*Project, 0, len(projects)),
+	}
+
+	for _, p := range projects {
+		project := *p
+		project.TerraformCloudToken = ""
+		spec.Projects = append(spec.Projects, &project)
+	}
+
+	b, err := yaml.Marshal(spec)
+	if err != nil {
+		return errors.Wrap(err, "Error marshalling config file")
+	}
+
+	err = os.WriteFile(path, b, 0600)
+	if err != nil {
+		return errors.Wrap(err, "Error writing config file")
+	}
+
+	return nil
+}
+
 func checkVersion(v string) bool {
 	if !strings.HasPrefix(v, "v") {
 		v = "v" + v
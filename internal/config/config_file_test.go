@@ -2,9 +2,12 @@ package config
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestYamlError_Error(t *testing.T) {
@@ -75,3 +78,78 @@ func TestYamlError_Error(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "infracost.yml")
+
+	projects := []*Project{
+		{Name: "infra (dev)", Path: "infra", TerraformPlanFlags: "-var-file=dev.tfvars"},
+		{Path: "live/dev/app"},
+		{
+			Path:                "hcl",
+			TerraformParseHCL:   true,
+			TerraformVarFiles:   []string{"prod.tfvars"},
+			TerraformVars:       []string{"instance_type=t3.large"},
+			TerraformCompareTo:  "main",
+			TerraformInitFlags:  "-upgrade",
+			TerraformBinary:     "tofu",
+			TerraformWorkspace:  "prod",
+			TerraformCloudHost:  "tfe.example.com",
+			TerraformCloudToken: "secret",
+			UsageFile:           "usage.yml",
+			TerraformUseState:   true,
+			Env:                 map[string]string{"AWS_PROFILE": "prod"},
+		},
+	}
+
+	err := WriteConfigFile(path, projects)
+	require.NoError(t, err)
+
+	cfgFile, err := loadConfigFile(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, maxConfigFileVersion, cfgFile.Version)
+	require.Len(t, cfgFile.Projects, 3)
+	assert.Equal(t, "infra (dev)", cfgFile.Projects[0].Name)
+	assert.Equal(t, "infra", cfgFile.Projects[0].Path)
+	assert.Equal(t, "-var-file=dev.tfvars", cfgFile.Projects[0].TerraformPlanFlags)
+	assert.Empty(t, cfgFile.Projects[1].Name)
+	assert.Equal(t, "live/dev/app", cfgFile.Projects[1].Path)
+	assert.Empty(t, cfgFile.Projects[1].TerraformPlanFlags)
+
+	expected := *projects[2]
+	expected.TerraformCloudToken = ""
+	assert.Equal(t, &expected, cfgFile.Projects[2])
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "secret")
+	assert.NotContains(t, string(b), "terraform_var_files: []")
+}
+
+func TestLoadConfigFileLegacyProjectKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "infracost.yml")
+
+	err := os.WriteFile(path, []byte(`version: 0.1
+projects:
+  - path: legacy
+    terraformvars:
+      - instance_type=t3.large
+    terragruntflags: --terragrunt-config dev.hcl
+  - path: both
+    terraform_vars:
+      - instance_type=t3.small
+    terraformvars:
+      - instance_type=t3.large
+`), 0600)
+	require.NoError(t, err)
+
+	cfgFile, err := loadConfigFile(path)
+	require.NoError(t, err)
+
+	require.Len(t, cfgFile.Projects, 2)
+	assert.Equal(t, []string{"instance_type=t3.large"}, cfgFile.Projects[0].TerraformVars)
+	assert.Equal(t, "--terragrunt-config dev.hcl", cfgFile.Projects[0].TerragruntFlags)
+	assert.Equal(t, []string{"instance_type=t3.small"}, cfgFile.Projects[1].TerraformVars)
+	assert.Empty(t, cfgFile.Projects[1].TerragruntFlags)
+}
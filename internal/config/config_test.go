@@ -12,11 +12,14 @@ import (
 
 func TestConfigLoadFromConfigFile(t *testing.T) {
 	tmp := t.TempDir()
+	maxMonthlyIncrease := 500.0
+
 	tests := []struct {
-		name     string
-		contents []byte
-		expected []*Project
-		error    error
+		name             string
+		contents         []byte
+		expected         []*Project
+		expectedPolicies Policies
+		error            error
 	}{
 		{
 			name: "should parse valid projects",
@@ -49,6 +52,42 @@ projects:
 				},
 			},
 		},
+		{
+			name: "should parse policies",
+			contents: []byte(`version: 0.1
+
+policies:
+  paths:
+    - policies/*.rego
+    - other/policy.rego
+  guardrails:
+    - name: prod
+      paths:
+        - infra/prod
+      max_monthly_increase: 500
+      forbid_resource_types:
+        - aws_nat_gateway
+
+projects:
+  - path: path/to/my_terraform
+`),
+			expected: []*Project{
+				{
+					Path: "path/to/my_terraform",
+				},
+			},
+			expectedPolicies: Policies{
+				Paths: []string{"policies/*.rego", "other/policy.rego"},
+				Guardrails: []*Guardrail{
+					{
+						Name:                "prod",
+						Paths:               []string{"infra/prod"},
+						MaxMonthlyIncrease:  &maxMonthlyIncrease,
+						ForbidResourceTypes: []string{"aws_nat_gateway"},
+					},
+				},
+			},
+		},
 		{
 			name: "should return error if no projects given",
 			contents: []byte(`version: 0.1
@@ -113,6 +152,24 @@ projects:
 
 			require.Equal(t, tt.error, err)
 			require.EqualValues(t, tt.expected, c.Projects)
+			require.EqualValues(t, tt.expectedPolicies, c.Policies)
 		})
 	}
 }
+
+func TestDetectProjectMetadataProjectEnv(t *testing.T) {
+	t.Setenv("INFRACOST_TERRAFORM_WORKSPACE", "process")
+	t.Setenv("INFRACOST_VCS_SUB_PATH", "infra/process")
+
+	metadata := DetectProjectMetadata(t.TempDir(), &Project{
+		Env: map[string]string{
+			"INFRACOST_TERRAFORM_WORKSPACE": "project",
+		},
+	})
+
+	require.Equal(t, "project", metadata.TerraformWorkspace)
+	require.Equal(t, "infra/process", metadata.VCSSubPath)
+
+	metadata = DetectProjectMetadata(t.TempDir(), nil)
+	require.Equal(t, "process", metadata.TerraformWorkspace)
+}
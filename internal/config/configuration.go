@@ -13,12 +13,15 @@ import (
 var configurationVersion = "0.1"
 
 type Configuration struct {
-	Version               string `yaml:"version"`
-	Currency              string `yaml:"currency,omitempty"`
-	EnableDashboard       *bool  `yaml:"enable_dashboard,omitempty"`
-	DisableHCLParsing     *bool  `yaml:"disable_hcl_parsing,omitempty"`
-	TLSInsecureSkipVerify *bool  `yaml:"tls_insecure_skip_verify,omitempty"`
-	TLSCACertFile         string `yaml:"tls_ca_cert_file,omitempty"`
+	Version               string         `yaml:"version"`
+	Currency              string         `yaml:"currency,omitempty"`
+	SecondaryCurrency     string         `yaml:"secondary_currency,omitempty"`
+	ExchangeRatesFile     string         `yaml:"exchange_rates_file,omitempty"`
+	ExchangeRates         *ExchangeRates `yaml:"exchange_rates,omitempty"`
+	EnableDashboard       *bool          `yaml:"enable_dashboard,omitempty"`
+	DisableHCLParsing     *bool          `yaml:"disable_hcl_parsing,omitempty"`
+	TLSInsecureSkipVerify *bool          `yaml:"tls_insecure_skip_verify,omitempty"`
+	TLSCACertFile         string         `yaml:"tls_ca_cert_file,omitempty"`
 }
 
 func loadConfiguration(cfg *Config) error {
@@ -42,6 +45,33 @@ func loadConfiguration(cfg *Config) error {
 		cfg.Currency = "USD"
 	}
 
+	if cfg.SecondaryCurrency == "" {
+		cfg.SecondaryCurrency = cfg.Configuration.SecondaryCurrency
+	}
+
+	if cfg.ExchangeRatesFile == "" {
+		cfg.ExchangeRatesFile = cfg.Configuration.ExchangeRatesFile
+	}
+
+	if cfg.ExchangeRatesFile != "" {
+		cfg.ExchangeRates, err = LoadExchangeRatesFile(cfg.ExchangeRatesFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	if cfg.Configuration.ExchangeRates != nil {
+		err = cfg.Configuration.ExchangeRates.validate()
+		if err != nil {
+			return err
+		}
+
+		cfg.ExchangeRates, err = cfg.ExchangeRates.Merge(cfg.Configuration.ExchangeRates)
+		if err != nil {
+			return err
+		}
+	}
+
 	if cfg.Configuration.EnableDashboard != nil {
 		cfg.EnableDashboard = *cfg.Configuration.EnableDashboard
 	}
@@ -0,0 +1,184 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"gopkg.in/yaml.v2"
+)
+
+var defaultExchangeRateBase = "USD"
+
+// ExchangeRates are the currency exchange rates used to convert costs between currencies locally,
+// e.g. to combine Infracost JSON files in different currencies. The rates are read from the
+// exchange rates file and can be overridden in the configuration file:
+//
+//	base: USD
+//	date: "2022-06-01"
+//	rates:
+//	  EUR: 0.93
+//	  GBP: 0.79
+type ExchangeRates struct {
+	// Base is the currency the rates are relative to, defaults to USD.
+	Base string `yaml:"base,omitempty"`
+	// Date is the date the rates are from. It is recorded in the output when costs are converted.
+	Date string `yaml:"date,omitempty"`
+	// Rates are the number of units of each currency that equal one unit of the base currency.
+	Rates map[string]float64 `yaml:"rates,omitempty"`
+}
+
+// LoadExchangeRatesFile reads the exchange rates from a YAML or JSON file.
+func LoadExchangeRatesFile(path string) (*ExchangeRates, error) {
+	expanded, err := homedir.Expand(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to expand path")
+	}
+
+	data, err := os.ReadFile(expanded)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading exchange rates file")
+	}
+
+	var rates ExchangeRates
+
+	err = yaml.Unmarshal(data, &rates)
+	if err != nil {
+		return nil, errors.New("Error parsing exchange rates file: " + strings.TrimPrefix(err.Error(), "yaml: "))
+	}
+
+	return &rates, rates.validate()
+}
+
+func (r *ExchangeRates) validate() error {
+	for currency, rate := range r.Rates {
+		if rate <= 0 {
+			return fmt.Errorf("Invalid exchange rate %v for %s, rates must be greater than 0", rate, currency)
+		}
+	}
+
+	return nil
+}
+
+// Merge returns the exchange rates with the base, date and rates from override replacing the
+// ones in r. Either of them can be nil. If override has a different base the rates in r are
+// converted to it, so they must include a rate for the new base.
+func (r *ExchangeRates) Merge(override *ExchangeRates) (*ExchangeRates, error) {
+	if r == nil {
+		return override, nil
+	}
+
+	if override == nil {
+		return r, nil
+	}
+
+	rates, err := r.rebase(override.Base)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := &ExchangeRates{
+		Base:  rates.Base,
+		Date:  rates.Date,
+		Rates: make(map[string]float64, len(rates.Rates)+len(override.Rates)),
+	}
+
+	if override.Date != "" {
+		merged.Date = override.Date
+	}
+
+	for currency, rate := range rates.Rates {
+		merged.Rates[currency] = rate
+	}
+
+	for currency, rate := range override.Rates {
+		merged.Rates[currency] = rate
+	}
+
+	return merged, nil
+}
+
+// rebase returns the exchange rates converted to be relative to the base currency. It returns r
+// if base is empty or already the base of r.
+func (r *ExchangeRates) rebase(base string) (*ExchangeRates, error) {
+	oldBase := strings.ToUpper(r.Base)
+	if oldBase == "" {
+		oldBase = defaultExchangeRateBase
+	}
+
+	newBase := strings.ToUpper(base)
+	if newBase == "" || newBase == oldBase {
+		return r, nil
+	}
+
+	baseRate, err := r.baseRate(newBase)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot change the exchange rate base from %s to %s, no exchange rate for %s in the exchange rates file", oldBase, newBase, newBase)
+	}
+
+	rebased := &ExchangeRates{
+		Base:  base,
+		Date:  r.Date,
+		Rates: make(map[string]float64, len(r.Rates)),
+	}
+
+	oldBaseRate, _ := decimal.NewFromInt(1).Div(baseRate).Float64()
+	rebased.Rates[oldBase] = oldBaseRate
+
+	for currency, rate := range r.Rates {
+		if strings.ToUpper(currency) == newBase {
+			continue
+		}
+
+		rebasedRate, _ := decimal.NewFromFloat(rate).Div(baseRate).Float64()
+		rebased.Rates[currency] = rebasedRate
+	}
+
+	return rebased, nil
+}
+
+// Rate returns the rate to multiply costs in the from currency by to convert them to the to currency.
+func (r *ExchangeRates) Rate(from, to string) (decimal.Decimal, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return decimal.NewFromInt(1), nil
+	}
+
+	if r == nil {
+		return decimal.Zero, fmt.Errorf("No exchange rates are configured to convert %s to %s, set exchange_rates_file in your configuration", from, to)
+	}
+
+	fromRate, err := r.baseRate(from)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	toRate, err := r.baseRate(to)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	return toRate.Div(fromRate), nil
+}
+
+func (r *ExchangeRates) baseRate(currency string) (decimal.Decimal, error) {
+	base := strings.ToUpper(r.Base)
+	if base == "" {
+		base = defaultExchangeRateBase
+	}
+
+	if currency == base {
+		return decimal.NewFromInt(1), nil
+	}
+
+	for c, rate := range r.Rates {
+		if strings.ToUpper(c) == currency {
+			return decimal.NewFromFloat(rate), nil
+		}
+	}
+
+	return decimal.Zero, fmt.Errorf("No exchange rate for %s in the configured exchange rates", currency)
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExchangeRatesRate(t *testing.T) {
+	rates := &ExchangeRates{
+		Rates: map[string]float64{"EUR": 0.8, "gbp": 0.5},
+	}
+
+	tests := []struct {
+		from     string
+		to       string
+		expected string
+		err      string
+	}{
+		{from: "USD", to: "USD", expected: "1"},
+		{from: "USD", to: "EUR", expected: "0.8"},
+		{from: "EUR", to: "USD", expected: "1.25"},
+		{from: "eur", to: "GBP", expected: "0.625"},
+		{from: "USD", to: "JPY", err: "No exchange rate for JPY in the configured exchange rates"},
+	}
+
+	for _, tt := range tests {
+		rate, err := rates.Rate(tt.from, tt.to)
+		if tt.err != "" {
+			assert.EqualError(t, err, tt.err)
+			continue
+		}
+
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, rate.String(), "%s to %s", tt.from, tt.to)
+	}
+
+	var noRates *ExchangeRates
+	_, err := noRates.Rate("USD", "EUR")
+	assert.EqualError(t, err, "No exchange rates are configured to convert USD to EUR, set exchange_rates_file in your configuration")
+}
+
+func TestLoadExchangeRatesFile(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "rates.yml")
+	err := os.WriteFile(path, []byte(`base: EUR
+date: "2022-06-01"
+rates:
+  USD: 1.25
+  GBP: 0.85
+`), 0600)
+	require.NoError(t, err)
+
+	rates, err := LoadExchangeRatesFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, &ExchangeRates{
+		Base:  "EUR",
+		Date:  "2022-06-01",
+		Rates: map[string]float64{"USD": 1.25, "GBP": 0.85},
+	}, rates)
+
+	merged, err := rates.Merge(&ExchangeRates{
+		Date:  "2022-06-02",
+		Rates: map[string]float64{"GBP": 0.86},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &ExchangeRates{
+		Base:  "EUR",
+		Date:  "2022-06-02",
+		Rates: map[string]float64{"USD": 1.25, "GBP": 0.86},
+	}, merged)
+
+	jsonPath := filepath.Join(dir, "rates.json")
+	err = os.WriteFile(jsonPath, []byte(`{"rates": {"EUR": -1}}`), 0600)
+	require.NoError(t, err)
+
+	_, err = LoadExchangeRatesFile(jsonPath)
+	assert.EqualError(t, err, "Invalid exchange rate -1 for EUR, rates must be greater than 0")
+}
+
+func TestExchangeRatesMergeBase(t *testing.T) {
+	rates := &ExchangeRates{
+		Base:  "EUR",
+		Date:  "2022-06-01",
+		Rates: map[string]float64{"USD": 1.25, "GBP": 0.85},
+	}
+
+	merged, err := rates.Merge(&ExchangeRates{
+		Base:  "usd",
+		Rates: map[string]float64{"JPY": 130},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &ExchangeRates{
+		Base:  "usd",
+		Date:  "2022-06-01",
+		Rates: map[string]float64{"EUR": 0.8, "GBP": 0.68, "JPY": 130},
+	}, merged)
+
+	rate, err := merged.Rate("GBP", "EUR")
+	require.NoError(t, err)
+	expected, err := rates.Rate("GBP", "EUR")
+	require.NoError(t, err)
+	assert.Equal(t, expected.String(), rate.String())
+
+	merged, err = rates.Merge(&ExchangeRates{Base: "EUR", Rates: map[string]float64{"GBP": 0.86}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"USD": 1.25, "GBP": 0.86}, merged.Rates)
+
+	_, err = rates.Merge(&ExchangeRates{Base: "JPY", Rates: map[string]float64{"USD": 0.0077}})
+	assert.EqualError(t, err, "Cannot change the exchange rate base from EUR to JPY, no exchange rate for JPY in the exchange rates file")
+}
@@ -61,11 +61,32 @@ func (c *ProjectContext) SetFrom(d ProjectContexter) {
 	}
 }
 
-func DetectProjectMetadata(path string) *schema.ProjectMetadata {
-	vcsRepoURL := os.Getenv("INFRACOST_VCS_REPOSITORY_URL")
-	vcsSubPath := os.Getenv("INFRACOST_VCS_SUB_PATH")
-	vcsPullRequestURL := os.Getenv("INFRACOST_VCS_PULL_REQUEST_URL")
-	terraformWorkspace := os.Getenv("INFRACOST_TERRAFORM_WORKSPACE")
+// ProjectName returns the name set in the project config, or a name generated from the metadata if one
+// isn't set.
+func (c *ProjectContext) ProjectName(metadata *schema.ProjectMetadata) string {
+	if c.ProjectConfig != nil && c.ProjectConfig.Name != "" {
+		return c.ProjectConfig.Name
+	}
+
+	return schema.GenerateProjectName(metadata, c.RunContext.Config.EnableDashboard)
+}
+
+// DetectProjectMetadata returns the metadata of the project at path. The INFRACOST_ environment
+// variables that override the detected values are read from the env of projectCfg, which can be nil.
+func DetectProjectMetadata(path string, projectCfg *Project) *schema.ProjectMetadata {
+	getenv := func(key string) string {
+		if projectCfg == nil {
+			return os.Getenv(key)
+		}
+
+		v, _ := projectCfg.LookupEnv(key)
+		return v
+	}
+
+	vcsRepoURL := getenv("INFRACOST_VCS_REPOSITORY_URL")
+	vcsSubPath := getenv("INFRACOST_VCS_SUB_PATH")
+	vcsPullRequestURL := getenv("INFRACOST_VCS_PULL_REQUEST_URL")
+	terraformWorkspace := getenv("INFRACOST_TERRAFORM_WORKSPACE")
 
 	if vcsRepoURL == "" {
 		vcsRepoURL = ciVCSRepo()
@@ -0,0 +1,220 @@
+package credentials
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/hashicorp/hcl2/gohcl"
+	"github.com/hashicorp/hcl2/hclparse"
+	"github.com/mitchellh/go-homedir"
+	log "github.com/sirupsen/logrus"
+)
+
+// tokenEnvPrefix is the prefix of the environment variables Terraform reads host-specific tokens from.
+// See https://www.terraform.io/cli/config/config-file#environment-variable-credentials
+const tokenEnvPrefix = "TF_TOKEN_"
+
+// FindTerraformToken returns the API token for the given host using the same sources as the Terraform CLI.
+// In order of precedence these are:
+//  1. The TF_TOKEN_<host> environment variable
+//  2. The credentials blocks in the file set by TF_CLI_CONFIG_FILE
+//  3. The credentials in ~/.terraform.d/credentials.tfrc.json, which is written by terraform login
+//  4. The credentials blocks in ~/.terraformrc
+//
+// The env is the environment of the project, which overrides the process environment. It can be nil.
+// It returns an empty string if no token is found.
+func FindTerraformToken(host string, env map[string]string) string {
+	if token := credFromEnv(host, env); token != "" {
+		log.Debugf("Using Terraform credentials for %s from %s environment variable", host, TokenEnvName(host))
+		return token
+	}
+
+	if confFile := getenv(env, "TF_CLI_CONFIG_FILE"); confFile != "" {
+		log.Debugf("TF_CLI_CONFIG_FILE is set, checking %s for Terraform credentials", confFile)
+		token, err := credFromHCL(confFile, host)
+		if err != nil {
+			log.Debugf("Error reading Terraform config file %s: %v", confFile, err)
+		}
+		if token != "" {
+			return token
+		}
+	}
+
+	credFile := defaultCredFile()
+	if _, err := os.Stat(credFile); err == nil {
+		log.Debugf("Checking %s for Terraform credentials", credFile)
+		token, err := credFromJSON(credFile, host)
+		if err != nil {
+			log.Debugf("Error reading Terraform credentials file %s: %v", credFile, err)
+		}
+		if token != "" {
+			return token
+		}
+	}
+
+	confFile := defaultConfFile()
+	if _, err := os.Stat(confFile); err == nil {
+		log.Debugf("Checking %s for Terraform credentials", confFile)
+		token, err := credFromHCL(confFile, host)
+		if err != nil {
+			log.Debugf("Error reading Terraform config file %s: %v", confFile, err)
+		}
+		if token != "" {
+			return token
+		}
+	}
+
+	return ""
+}
+
+// TerraformConfigSet returns true if any of the sources FindTerraformToken reads from are set.
+func TerraformConfigSet(env map[string]string) bool {
+	if getenv(env, "TF_CLI_CONFIG_FILE") != "" {
+		return true
+	}
+
+	for _, e := range environ(env) {
+		if strings.HasPrefix(e, tokenEnvPrefix) {
+			return true
+		}
+	}
+
+	if _, err := os.Stat(defaultConfFile()); err == nil {
+		return true
+	}
+
+	if _, err := os.Stat(defaultCredFile()); err == nil {
+		return true
+	}
+
+	return false
+}
+
+// TokenEnvName returns the name of the environment variable that can be used to set the token for the given host.
+// Periods in the host are encoded as underscores and hyphens as double underscores, e.g. the token for
+// my-tfe.example.com is read from TF_TOKEN_my__tfe_example_com.
+func TokenEnvName(host string) string {
+	name := strings.ReplaceAll(host, "-", "__")
+	name = strings.ReplaceAll(name, ".", "_")
+	return tokenEnvPrefix + name
+}
+
+// credFromEnv finds the token for the host from the TF_TOKEN_ environment variables.
+// Hosts are compared case-insensitively and the hyphens in the host can either be
+// encoded as double underscores or left as is.
+func credFromEnv(host string, env map[string]string) string {
+	for _, e := range environ(env) {
+		if !strings.HasPrefix(e, tokenEnvPrefix) {
+			continue
+		}
+
+		parts := strings.SplitN(strings.TrimPrefix(e, tokenEnvPrefix), "=", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			continue
+		}
+
+		envHost := strings.ReplaceAll(parts[0], "__", "-")
+		envHost = strings.ReplaceAll(envHost, "_", ".")
+
+		if strings.EqualFold(envHost, host) {
+			return parts[1]
+		}
+	}
+
+	return ""
+}
+
+// getenv returns the value of the environment variable from env if it is set there, otherwise
+// from the process environment.
+func getenv(env map[string]string, key string) string {
+	if v, ok := env[key]; ok {
+		return v
+	}
+
+	return os.Getenv(key)
+}
+
+// environ returns the process environment with the variables in env added, in key=value form.
+// Variables in env are listed first so they take precedence when searching the list.
+func environ(env map[string]string) []string {
+	vars := make([]string, 0, len(env))
+	for k, v := range env {
+		vars = append(vars, k+"="+v)
+	}
+
+	return append(vars, os.Environ()...)
+}
+
+func credFromHCL(filename string, host string) (string, error) {
+	parser := hclparse.NewParser()
+	f, parseDiags := parser.ParseHCLFile(filename)
+	if parseDiags.HasErrors() {
+		return "", parseDiags
+	}
+
+	var conf struct {
+		Credentials []struct {
+			Name  string `hcl:"name,label"`
+			Token string `hcl:"token"`
+		} `hcl:"credentials,block"`
+	}
+
+	decodeDiags := gohcl.DecodeBody(f.Body, nil, &conf)
+	if decodeDiags.HasErrors() {
+		return "", decodeDiags
+	}
+
+	for _, c := range conf.Credentials {
+		if c.Name == host {
+			return c.Token, nil
+		}
+	}
+
+	return "", nil
+}
+
+func credFromJSON(filename, host string) (string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+
+	var conf struct {
+		Credentials map[string]struct {
+			Token string `json:"token"`
+		} `json:"credentials"`
+	}
+	err = json.Unmarshal(data, &conf)
+	if err != nil {
+		return "", err
+	}
+
+	if hostCred, ok := conf.Credentials[host]; ok {
+		return hostCred.Token, nil
+	}
+
+	return "", nil
+}
+
+func defaultConfFile() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("APPDATA"), "terraform.rc")
+	}
+
+	p, _ := homedir.Expand("~/.terraformrc")
+	return p
+}
+
+func defaultCredFile() string {
+	var dir string
+	if runtime.GOOS == "windows" {
+		dir = filepath.Join(os.Getenv("APPDATA"), "terraform.d")
+	} else {
+		dir, _ = homedir.Expand("~/.terraform.d")
+	}
+	return path.Join(dir, "credentials.tfrc.json")
+}
@@ -0,0 +1,51 @@
+package credentials
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenEnvName(t *testing.T) {
+	assert.Equal(t, "TF_TOKEN_app_terraform_io", TokenEnvName("app.terraform.io"))
+	assert.Equal(t, "TF_TOKEN_my__tfe_example_com", TokenEnvName("my-tfe.example.com"))
+}
+
+func TestFindTerraformTokenFromEnv(t *testing.T) {
+	t.Setenv("TF_TOKEN_app_terraform_io", "env-token")
+	t.Setenv("TF_TOKEN_my__tfe_example_com", "tfe-token")
+
+	assert.Equal(t, "env-token", FindTerraformToken("app.terraform.io", nil))
+	assert.Equal(t, "tfe-token", FindTerraformToken("my-tfe.example.com", nil))
+	assert.Equal(t, "tfe-token", FindTerraformToken("MY-TFE.example.com", nil))
+}
+
+func TestFindTerraformTokenFromConfigFile(t *testing.T) {
+	confFile := filepath.Join(t.TempDir(), "terraform.rc")
+	err := os.WriteFile(confFile, []byte(`
+credentials "tfe.example.com" {
+  token = "config-token"
+}
+`), 0600)
+	require.NoError(t, err)
+
+	t.Setenv("TF_CLI_CONFIG_FILE", confFile)
+
+	assert.Equal(t, "config-token", FindTerraformToken("tfe.example.com", nil))
+
+	t.Setenv("TF_TOKEN_tfe_example_com", "env-token")
+	assert.Equal(t, "env-token", FindTerraformToken("tfe.example.com", nil))
+}
+
+func TestFindTerraformTokenFromProjectEnv(t *testing.T) {
+	t.Setenv("TF_TOKEN_app_terraform_io", "process-token")
+
+	env := map[string]string{"TF_TOKEN_app_terraform_io": "project-token"}
+
+	assert.Equal(t, "project-token", FindTerraformToken("app.terraform.io", env))
+	assert.Equal(t, "process-token", FindTerraformToken("app.terraform.io", nil))
+	assert.True(t, TerraformConfigSet(map[string]string{"TF_TOKEN_tfe_example_com": "token"}))
+}
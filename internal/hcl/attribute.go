@@ -70,6 +70,72 @@ func (attr *Attribute) Value() (ctyVal cty.Value) {
 	return ctyVal
 }
 
+// UnresolvedReferences returns the references in the Attribute expression that could not be resolved to
+// a known value when the Attribute value is unknown. For example, this can be a variable that was never set
+// or the attribute of a data source that can't be computed offline. References to resource attributes are
+// not returned, since these are computed by the provider and so are unknown in a Terraform plan as well.
+func (attr *Attribute) UnresolvedReferences() (refs []string) {
+	if attr == nil {
+		return nil
+	}
+
+	defer func() {
+		if err := recover(); err != nil {
+			refs = nil
+		}
+	}()
+
+	// Values that fail to evaluate are stored in the Context as cty.NilVal, so
+	// these are treated as unknown as well.
+	val, diag := attr.HCLAttr.Expr.Value(attr.Ctx.Inner())
+	if !diag.HasErrors() && val != cty.NilVal && val.IsWhollyKnown() {
+		return nil
+	}
+
+	for _, traversal := range attr.HCLAttr.Expr.Variables() {
+		if !isResolvableRoot(traversal.RootName()) {
+			continue
+		}
+
+		v, diag := traversal.TraverseAbs(attr.Ctx.Inner())
+		if diag.HasErrors() || v == cty.NilVal || !v.IsWhollyKnown() {
+			refs = append(refs, traversalString(traversal))
+		}
+	}
+
+	return refs
+}
+
+// isResolvableRoot returns true if the traversal root name refers to a value that Infracost is expected to
+// resolve from the HCL, rather than a resource attribute that is only known after apply.
+func isResolvableRoot(name string) bool {
+	switch name {
+	case "var", "local", "data", "module", "count", "each", "path", "terraform":
+		return true
+	}
+
+	return false
+}
+
+func traversalString(traversal hcl.Traversal) string {
+	var sb strings.Builder
+
+	for _, t := range traversal {
+		switch v := t.(type) {
+		case hcl.TraverseRoot:
+			sb.WriteString(v.Name)
+		case hcl.TraverseAttr:
+			sb.WriteString("." + v.Name)
+		case hcl.TraverseIndex:
+			sb.WriteString("[" + getIndexValue(v) + "]")
+		case hcl.TraverseSplat:
+			sb.WriteString("[*]")
+		}
+	}
+
+	return sb.String()
+}
+
 // Name is a helper method to return the underlying hcl.Attribute Name
 func (attr *Attribute) Name() string {
 	return attr.HCLAttr.Name
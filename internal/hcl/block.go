@@ -46,6 +46,9 @@ var terraformSchemaV012 = &hcl.BodySchema{
 			Type:       "data",
 			LabelNames: []string{"type", "name"},
 		},
+		{
+			Type: "moved",
+		},
 	},
 }
 
@@ -111,6 +114,9 @@ type Block struct {
 	childBlocks Blocks
 	// verbose determines whether the block uses verbose debug logging.
 	verbose bool
+	// unknownAttributes are the names of the attributes that couldn't be resolved to a known value.
+	// These are set by the Evaluator once evaluation is complete.
+	unknownAttributes []string
 }
 
 // BlockBuilder handles generating new Blocks as part of the parsing and evaluation process.
@@ -321,6 +327,13 @@ func (b *Block) InjectBlock(block *Block, name string) {
 	b.childBlocks = append(b.childBlocks, block)
 }
 
+// UnknownAttributes returns the names of the Block attributes that could not be resolved to a known value
+// because they reference values that are unknown, e.g. a variable that was never set. Attributes of child
+// Blocks are prefixed with the child Block type, e.g. root_block_device.volume_size.
+func (b *Block) UnknownAttributes() []string {
+	return b.unknownAttributes
+}
+
 // IsCountExpanded returns if the Block has been expanded as part of a for_each or count evaluation.
 func (b *Block) IsCountExpanded() bool {
 	return b.expanded
@@ -383,6 +396,26 @@ func (b *Block) ModuleSource() string {
 	return value.AsString()
 }
 
+// ModuleBlock returns the module call Block of the Module this Block is part of, or nil if it is part of
+// the root Module.
+func (b *Block) ModuleBlock() *Block {
+	if b == nil {
+		return nil
+	}
+
+	return b.moduleBlock
+}
+
+// Range returns the location of the Block in its file, from the start of the block header to the closing brace.
+func (b *Block) Range() hcl.Range {
+	r := b.hclBlock.DefRange
+	if body, ok := b.hclBlock.Body.(*hclsyntax.Body); ok {
+		r.End = body.SrcRange.End
+	}
+
+	return r
+}
+
 // Provider returns the provider by first checking if it is explicitly set as an attribute, if it is not
 // the first word in the snake_case name of the type is returned.  E.g. the type 'aws_instance' would
 // return provider 'aws'
@@ -0,0 +1,208 @@
+package hcl
+
+import (
+	"crypto/sha1" // nolint:gosec
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	ctyJson "github.com/zclconf/go-cty/cty/json"
+)
+
+// DataSource is a data block that is being resolved by a DataSourceEvaluator.
+type DataSource struct {
+	// Block is the data block. Its attributes are evaluated with the Context of the module it is in.
+	Block *Block
+	// Provider is the evaluated config of the provider block the data source uses. This is an
+	// empty object if the provider is not configured.
+	Provider cty.Value
+	// RootPath is the path of the root module. Relative file paths are resolved against this
+	// since it is the directory Terraform would be run from.
+	RootPath string
+	// ModulePath is the path of the module the data block is in.
+	ModulePath string
+}
+
+// DataSourceEvaluator returns the attributes of a data source that can be computed offline, without
+// calling the provider. These are merged on top of the attributes set in the data block so that
+// references to them, e.g. data.aws_region.current.name, evaluate to a known value. It returns nil
+// if the attributes can't be computed.
+type DataSourceEvaluator func(d DataSource) map[string]cty.Value
+
+// DefaultDataSourceEvaluators are the DataSourceEvaluators that the Parser uses, keyed by data source type.
+// Any other data blocks only have the attributes they are given in the HCL, so references to their
+// computed attributes evaluate to unknown.
+var DefaultDataSourceEvaluators = map[string]DataSourceEvaluator{
+	"aws_availability_zones": evaluateAWSAvailabilityZones,
+	"aws_caller_identity":    evaluateAWSCallerIdentity,
+	"aws_region":             evaluateAWSRegion,
+	"local_file":             evaluateLocalFile,
+	"template_file":          evaluateTemplateFile,
+	"terraform_remote_state": evaluateTerraformRemoteState,
+}
+
+// evaluateLocalFile reads the file set by the filename attribute of a local_file data source.
+func evaluateLocalFile(d DataSource) map[string]cty.Value {
+	filename := stringAttr(d.Block, "filename")
+	if filename == "" {
+		return nil
+	}
+
+	if !filepath.IsAbs(filename) {
+		filename = filepath.Join(d.RootPath, filename)
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		log.Debugf("could not read local_file %s: %s", filename, err)
+		return nil
+	}
+
+	checksum := sha1.Sum(content) // nolint:gosec
+
+	return map[string]cty.Value{
+		"id":             cty.StringVal(hex.EncodeToString(checksum[:])),
+		"content":        cty.StringVal(string(content)),
+		"content_base64": cty.StringVal(base64.StdEncoding.EncodeToString(content)),
+	}
+}
+
+// evaluateTemplateFile renders the template attribute of a template_file data source using the vars attribute.
+func evaluateTemplateFile(d DataSource) map[string]cty.Value {
+	template := stringAttr(d.Block, "template")
+	if template == "" {
+		return nil
+	}
+
+	expr, diags := hclsyntax.ParseTemplate([]byte(template), d.Block.FullName(), hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		log.Debugf("could not parse template for %s: %s", d.Block.FullName(), diags.Error())
+		return nil
+	}
+
+	vars := make(map[string]cty.Value)
+	if attr := d.Block.GetAttribute("vars"); attr != nil {
+		v := attr.Value()
+		if v.CanIterateElements() && v.IsWhollyKnown() {
+			vars = v.AsValueMap()
+		}
+	}
+
+	rendered, diags := expr.Value(&hcl.EvalContext{
+		Variables: vars,
+		Functions: ExpFunctions(d.ModulePath),
+	})
+	if diags.HasErrors() || !rendered.IsWhollyKnown() || rendered.Type() != cty.String {
+		log.Debugf("could not render template for %s: %s", d.Block.FullName(), diags.Error())
+		return nil
+	}
+
+	return map[string]cty.Value{
+		"rendered": rendered,
+	}
+}
+
+// evaluateTerraformRemoteState reads the outputs of a terraform_remote_state data source that uses the local
+// backend from its state file. The defaults attribute is used for any outputs that aren't in the state file.
+func evaluateTerraformRemoteState(d DataSource) map[string]cty.Value {
+	if stringAttr(d.Block, "backend") != "local" {
+		return nil
+	}
+
+	statePath := "terraform.tfstate"
+	if attr := d.Block.GetAttribute("config"); attr != nil {
+		config := attr.Value()
+		if config.Type().IsObjectType() && config.Type().HasAttribute("path") {
+			p := config.GetAttr("path")
+			if p.Type() == cty.String && p.IsKnown() && !p.IsNull() {
+				statePath = p.AsString()
+			}
+		}
+	}
+
+	if !filepath.IsAbs(statePath) {
+		statePath = filepath.Join(d.RootPath, statePath)
+	}
+
+	outputs, err := readStateOutputs(statePath)
+	if err != nil {
+		log.Debugf("could not read outputs from state file %s for %s: %s", statePath, d.Block.FullName(), err)
+		outputs = make(map[string]cty.Value)
+	}
+
+	if attr := d.Block.GetAttribute("defaults"); attr != nil {
+		defaults := attr.Value()
+		if defaults.CanIterateElements() {
+			for k, v := range defaults.AsValueMap() {
+				if _, ok := outputs[k]; !ok {
+					outputs[k] = v
+				}
+			}
+		}
+	}
+
+	if len(outputs) == 0 {
+		return nil
+	}
+
+	return map[string]cty.Value{
+		"outputs": cty.ObjectVal(outputs),
+	}
+}
+
+func readStateOutputs(statePath string) (map[string]cty.Value, error) {
+	b, err := os.ReadFile(statePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var state struct {
+		Outputs map[string]struct {
+			Value json.RawMessage `json:"value"`
+			Type  json.RawMessage `json:"type"`
+		} `json:"outputs"`
+	}
+
+	err = json.Unmarshal(b, &state)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make(map[string]cty.Value, len(state.Outputs))
+	for name, output := range state.Outputs {
+		ty, err := ctyJson.UnmarshalType(output.Type)
+		if err != nil {
+			return nil, fmt.Errorf("invalid type for output %s: %w", name, err)
+		}
+
+		v, err := ctyJson.Unmarshal(output.Value, ty)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for output %s: %w", name, err)
+		}
+
+		outputs[name] = v
+	}
+
+	return outputs, nil
+}
+
+// stringAttr returns the value of the attribute if it is a known string, otherwise an empty string.
+func stringAttr(b *Block, name string) string {
+	attr := b.GetAttribute(name)
+	if attr == nil {
+		return ""
+	}
+
+	v := attr.Value()
+	if v == cty.NilVal || v.IsNull() || !v.IsKnown() || v.Type() != cty.String {
+		return ""
+	}
+
+	return v.AsString()
+}
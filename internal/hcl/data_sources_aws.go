@@ -0,0 +1,130 @@
+package hcl
+
+import (
+	"fmt"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// AWSPlaceholderAccountID is the account ID returned by aws_caller_identity data sources since we can't know the real one offline.
+const AWSPlaceholderAccountID = "123456789012"
+
+var (
+	// defaultAWSRegion is the region used for AWS data sources when the provider doesn't set one.
+	defaultAWSRegion = "us-east-1"
+	// awsDefaultZones are the zone suffixes used for regions that aren't in awsAvailabilityZones.
+	awsDefaultZones = []string{"a", "b", "c"}
+)
+
+// awsAvailabilityZones are the zone suffixes of the availability zones in each AWS region that are
+// available to new accounts. Some older accounts have access to different zones, but these are
+// only used to work out how many resources there are, so the exact names matter less than the count.
+var awsAvailabilityZones = map[string][]string{
+	"af-south-1":     {"a", "b", "c"},
+	"ap-east-1":      {"a", "b", "c"},
+	"ap-northeast-1": {"a", "c", "d"},
+	"ap-northeast-2": {"a", "b", "c", "d"},
+	"ap-northeast-3": {"a", "b", "c"},
+	"ap-south-1":     {"a", "b", "c"},
+	"ap-southeast-1": {"a", "b", "c"},
+	"ap-southeast-2": {"a", "b", "c"},
+	"ap-southeast-3": {"a", "b", "c"},
+	"ca-central-1":   {"a", "b", "d"},
+	"cn-north-1":     {"a", "b", "d"},
+	"cn-northwest-1": {"a", "b", "c"},
+	"eu-central-1":   {"a", "b", "c"},
+	"eu-north-1":     {"a", "b", "c"},
+	"eu-south-1":     {"a", "b", "c"},
+	"eu-west-1":      {"a", "b", "c"},
+	"eu-west-2":      {"a", "b", "c"},
+	"eu-west-3":      {"a", "b", "c"},
+	"me-south-1":     {"a", "b", "c"},
+	"sa-east-1":      {"a", "b", "c"},
+	"us-east-1":      {"a", "b", "c", "d", "e", "f"},
+	"us-east-2":      {"a", "b", "c"},
+	"us-gov-east-1":  {"a", "b", "c"},
+	"us-gov-west-1":  {"a", "b", "c"},
+	"us-west-1":      {"a", "c"},
+	"us-west-2":      {"a", "b", "c", "d"},
+}
+
+// evaluateAWSAvailabilityZones returns the availability zone names for the provider region,
+// without any zones in the exclude_names attribute.
+func evaluateAWSAvailabilityZones(d DataSource) map[string]cty.Value {
+	region := awsRegion(d)
+
+	zones, ok := awsAvailabilityZones[region]
+	if !ok {
+		zones = awsDefaultZones
+	}
+
+	excluded := make(map[string]bool)
+	if attr := d.Block.GetAttribute("exclude_names"); attr != nil {
+		v := attr.Value()
+		if v.CanIterateElements() && v.IsWhollyKnown() {
+			for _, name := range v.AsValueSlice() {
+				if name.Type() == cty.String {
+					excluded[name.AsString()] = true
+				}
+			}
+		}
+	}
+
+	names := make([]cty.Value, 0, len(zones))
+	for _, zone := range zones {
+		name := region + zone
+		if excluded[name] {
+			continue
+		}
+
+		names = append(names, cty.StringVal(name))
+	}
+
+	namesVal := cty.ListValEmpty(cty.String)
+	if len(names) > 0 {
+		namesVal = cty.ListVal(names)
+	}
+
+	return map[string]cty.Value{
+		"id":          cty.StringVal(region),
+		"names":       namesVal,
+		"group_names": cty.SetVal([]cty.Value{cty.StringVal(region)}),
+	}
+}
+
+// evaluateAWSCallerIdentity returns placeholder identifiers for an aws_caller_identity data source.
+func evaluateAWSCallerIdentity(d DataSource) map[string]cty.Value {
+	return map[string]cty.Value{
+		"id":         cty.StringVal(AWSPlaceholderAccountID),
+		"account_id": cty.StringVal(AWSPlaceholderAccountID),
+		"arn":        cty.StringVal(fmt.Sprintf("arn:aws:iam::%s:user/infracost", AWSPlaceholderAccountID)),
+		"user_id":    cty.StringVal("AIDAINFRACOSTPLACEHOLDER"),
+	}
+}
+
+// evaluateAWSRegion returns the attributes of an aws_region data source. This is the region in the
+// name attribute if it is set, otherwise the provider region.
+func evaluateAWSRegion(d DataSource) map[string]cty.Value {
+	region := stringAttr(d.Block, "name")
+	if region == "" {
+		region = awsRegion(d)
+	}
+
+	return map[string]cty.Value{
+		"id":       cty.StringVal(region),
+		"name":     cty.StringVal(region),
+		"endpoint": cty.StringVal(fmt.Sprintf("ec2.%s.amazonaws.com", region)),
+	}
+}
+
+// awsRegion returns the region set in the AWS provider config the data source uses, or the default region.
+func awsRegion(d DataSource) string {
+	if d.Provider.Type().IsObjectType() && d.Provider.Type().HasAttribute("region") {
+		region := d.Provider.GetAttr("region")
+		if region.Type() == cty.String && region.IsKnown() && !region.IsNull() {
+			return region.AsString()
+		}
+	}
+
+	return defaultAWSRegion
+}
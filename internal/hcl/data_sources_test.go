@@ -0,0 +1,164 @@
+package hcl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func parseOutputs(t *testing.T, path string) (*Module, map[string]cty.Value) {
+	t.Helper()
+
+	parser := New(filepath.Dir(path), OptionStopOnHCLError())
+	module, err := parser.ParseDirectory()
+	require.NoError(t, err)
+
+	outputs := make(map[string]cty.Value)
+	for _, b := range module.Blocks.OfType("output") {
+		outputs[b.Label()] = b.GetAttribute("value").Value()
+	}
+
+	return module, outputs
+}
+
+func Test_AWSDataSources(t *testing.T) {
+	path := createTestFile("main.tf", `
+provider "aws" {
+	region = "eu-west-2"
+}
+
+provider "aws" {
+	alias  = "us"
+	region = "us-west-2"
+}
+
+data "aws_availability_zones" "available" {}
+
+data "aws_availability_zones" "us" {
+	provider      = aws.us
+	exclude_names = ["us-west-2d"]
+}
+
+data "aws_region" "current" {}
+
+data "aws_caller_identity" "current" {}
+
+resource "aws_instance" "web" {
+	count             = length(data.aws_availability_zones.available.names)
+	availability_zone = data.aws_availability_zones.available.names[count.index]
+}
+
+output "us_zones" {
+	value = data.aws_availability_zones.us.names
+}
+
+output "region" {
+	value = data.aws_region.current.name
+}
+
+output "account_id" {
+	value = data.aws_caller_identity.current.account_id
+}
+`)
+
+	module, outputs := parseOutputs(t, path)
+
+	instances := module.Blocks.OfType("resource")
+	require.Len(t, instances, 3)
+	assert.Equal(t, "eu-west-2a", instances[0].GetAttribute("availability_zone").Value().AsString())
+	assert.Equal(t, "eu-west-2c", instances[2].GetAttribute("availability_zone").Value().AsString())
+
+	assert.Equal(t, cty.ListVal([]cty.Value{cty.StringVal("us-west-2a"), cty.StringVal("us-west-2b"), cty.StringVal("us-west-2c")}), outputs["us_zones"])
+	assert.Equal(t, cty.StringVal("eu-west-2"), outputs["region"])
+	assert.Equal(t, cty.StringVal("123456789012"), outputs["account_id"])
+}
+
+func Test_FileDataSources(t *testing.T) {
+	path := createTestFile("main.tf", `
+data "local_file" "config" {
+	filename = "config.txt"
+}
+
+data "template_file" "user_data" {
+	template = "instance_type=$${instance_type}"
+	vars = {
+		instance_type = "t3.medium"
+	}
+}
+
+data "terraform_remote_state" "network" {
+	backend = "local"
+	config = {
+		path = "network.tfstate"
+	}
+	defaults = {
+		subnet_count = 1
+		region       = "us-east-1"
+	}
+}
+
+output "config" {
+	value = data.local_file.config.content
+}
+
+output "user_data" {
+	value = data.template_file.user_data.rendered
+}
+
+output "subnet_count" {
+	value = data.terraform_remote_state.network.outputs.subnet_count
+}
+
+output "region" {
+	value = data.terraform_remote_state.network.outputs.region
+}
+`)
+	dir := filepath.Dir(path)
+
+	err := os.WriteFile(filepath.Join(dir, "config.txt"), []byte("hello"), os.ModePerm)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dir, "network.tfstate"), []byte(`{
+	"version": 4,
+	"outputs": {
+		"subnet_count": {
+			"value": 3,
+			"type": "number"
+		}
+	}
+}`), os.ModePerm)
+	require.NoError(t, err)
+
+	_, outputs := parseOutputs(t, path)
+
+	assert.Equal(t, cty.StringVal("hello"), outputs["config"])
+	assert.Equal(t, cty.StringVal("instance_type=t3.medium"), outputs["user_data"])
+	assert.True(t, outputs["subnet_count"].Equals(cty.NumberIntVal(3)).True())
+	assert.Equal(t, cty.StringVal("us-east-1"), outputs["region"])
+}
+
+func Test_OptionWithDataSourceEvaluators(t *testing.T) {
+	path := createTestFile("main.tf", `
+data "aws_region" "current" {}
+
+output "region" {
+	value = data.aws_region.current.name
+}
+`)
+
+	parser := New(filepath.Dir(path), OptionStopOnHCLError(), OptionWithDataSourceEvaluators(map[string]DataSourceEvaluator{
+		"aws_region": func(d DataSource) map[string]cty.Value {
+			return map[string]cty.Value{"name": cty.StringVal("custom")}
+		},
+	}))
+	module, err := parser.ParseDirectory()
+	require.NoError(t, err)
+
+	outputs := module.Blocks.OfType("output")
+	require.Len(t, outputs, 1)
+	assert.Equal(t, cty.StringVal("custom"), outputs[0].GetAttribute("value").Value())
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/hashicorp/hcl/v2"
@@ -49,6 +50,8 @@ type Evaluator struct {
 	workspace string
 	// blockBuilder handles generating blocks in the evaluation step.
 	blockBuilder BlockBuilder
+	// dataSourceEvaluators compute the attributes of data blocks that can be resolved offline, keyed by data source type.
+	dataSourceEvaluators map[string]DataSourceEvaluator
 }
 
 // NewEvaluator returns an Evaluator with Context initialised with top level variables.
@@ -62,9 +65,10 @@ func NewEvaluator(
 	visitedModules map[string]struct{},
 	workspace string,
 	blockBuilder BlockBuilder,
+	dataSourceEvaluators map[string]DataSourceEvaluator,
 ) *Evaluator {
 	ctx := NewContext(&hcl.EvalContext{
-		Functions: expFunctions(module.ModulePath),
+		Functions: ExpFunctions(module.ModulePath),
 	}, nil)
 
 	if visitedModules == nil {
@@ -82,13 +86,14 @@ func NewEvaluator(
 	}
 
 	return &Evaluator{
-		module:         module,
-		ctx:            ctx,
-		inputVars:      inputVars,
-		moduleMetadata: moduleMetadata,
-		visitedModules: visitedModules,
-		workspace:      workspace,
-		blockBuilder:   blockBuilder,
+		module:               module,
+		ctx:                  ctx,
+		inputVars:            inputVars,
+		moduleMetadata:       moduleMetadata,
+		visitedModules:       visitedModules,
+		workspace:            workspace,
+		blockBuilder:         blockBuilder,
+		dataSourceEvaluators: dataSourceEvaluators,
 	}
 }
 
@@ -110,10 +115,62 @@ func (e *Evaluator) Run() (*Module, error) {
 	e.module.Blocks = e.expandBlocks(e.module.Blocks)
 	e.evaluate(lastContext)
 
+	e.recordUnknownAttributes()
+
 	// returns all the evaluated Blocks under their given Module.
 	return e.collectModules(), nil
 }
 
+// recordUnknownAttributes records the attributes of each resource Block that could not be resolved to a known
+// value. These can cause resources to be priced incorrectly, so they are reported to the user.
+func (e *Evaluator) recordUnknownAttributes() {
+	for _, b := range e.module.Blocks.OfType("resource") {
+		b.unknownAttributes = unknownAttributes(b, "")
+
+		if len(b.unknownAttributes) > 0 {
+			log.Debugf("Could not resolve attributes for %s: %s", b.FullName(), strings.Join(b.unknownAttributes, ", "))
+		}
+	}
+}
+
+func unknownAttributes(b *Block, prefix string) []string {
+	var names []string
+	seen := make(map[string]bool)
+
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	for _, attr := range b.GetAttributes() {
+		refs := attr.UnresolvedReferences()
+		if len(refs) == 0 {
+			continue
+		}
+
+		log.Debugf("Attribute %s%s of %s references unknown values: %s", prefix, attr.Name(), b.FullName(), strings.Join(refs, ", "))
+		add(prefix + attr.Name())
+	}
+
+	for _, child := range b.Children() {
+		// dynamic blocks are expanded into child blocks, so the content of the dynamic block itself
+		// references iterator values that aren't set.
+		if child.Type() == "dynamic" {
+			continue
+		}
+
+		for _, name := range unknownAttributes(child, prefix+child.Type()+".") {
+			add(name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
 func (e *Evaluator) collectModules() *Module {
 	for _, definition := range e.moduleCalls {
 		e.module.Modules = append(e.module.Modules, definition.Module)
@@ -192,10 +249,63 @@ func (e *Evaluator) evaluateModules() {
 			e.visitedModules,
 			e.workspace,
 			e.blockBuilder,
+			e.dataSourceEvaluators,
 		)
 
 		moduleCall.Module, _ = moduleEvaluator.Run()
-		e.ctx.Set(moduleEvaluator.exportOutputs(), "module", moduleCall.Name)
+		moduleCall.outputs = moduleEvaluator.exportOutputs()
+	}
+
+	e.setModuleOutputs()
+}
+
+// setModuleOutputs sets the outputs of the moduleCalls on the Context. Instances of a module
+// expanded by count or for_each are grouped under the module name so that they can be referenced
+// by index, e.g. module.svc["a"].out or module.svc[0].out, as Terraform does.
+func (e *Evaluator) setModuleOutputs() {
+	indexed := make(map[string]map[string]cty.Value)
+	counted := make(map[string]map[int64]cty.Value)
+
+	for _, moduleCall := range e.moduleCalls {
+		if moduleCall.outputs == cty.NilVal {
+			continue
+		}
+
+		name, key := splitInstanceKey(moduleCall.Name)
+		switch {
+		case key == cty.NilVal:
+			e.ctx.Set(moduleCall.outputs, "module", name)
+		case key.Type() == cty.Number:
+			i, _ := key.AsBigFloat().Int64()
+			if counted[name] == nil {
+				counted[name] = make(map[int64]cty.Value)
+			}
+			counted[name][i] = moduleCall.outputs
+		default:
+			if indexed[name] == nil {
+				indexed[name] = make(map[string]cty.Value)
+			}
+			indexed[name][key.AsString()] = moduleCall.outputs
+		}
+	}
+
+	for name, instances := range indexed {
+		e.ctx.Set(cty.ObjectVal(instances), "module", name)
+	}
+
+	for name, instances := range counted {
+		keys := make([]int64, 0, len(instances))
+		for k := range instances {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+		vals := make([]cty.Value, len(keys))
+		for i, k := range keys {
+			vals[i] = instances[k]
+		}
+
+		e.ctx.Set(cty.TupleVal(vals), "module", name)
 	}
 }
 
@@ -341,17 +451,14 @@ func (e *Evaluator) evaluateVariable(b *Block) (cty.Value, error) {
 		return cty.NilVal, fmt.Errorf("empty label - cannot resolve")
 	}
 
-	attributes := b.AttributesAsMap()
-	if attributes == nil {
-		return cty.NilVal, fmt.Errorf("cannot resolve variable with no attributes")
-	}
+	ty := variableType(b)
 
 	if override, exists := e.inputVars[b.Label()]; exists {
-		return override, nil
+		return convertVariableValue(b.Label(), override, ty), nil
 	}
 
-	if def, exists := attributes["default"]; exists {
-		return def.Value(), nil
+	if def := b.GetAttribute("default"); def != nil {
+		return convertVariableValue(b.Label(), def.Value(), ty), nil
 	}
 
 	return cty.NilVal, fmt.Errorf("no value found")
@@ -369,6 +476,64 @@ func (e *Evaluator) evaluateOutput(b *Block) (cty.Value, error) {
 	return attribute.Value(), nil
 }
 
+// evaluateDataSource returns the values of the data block with any attributes that can be computed by the
+// DataSourceEvaluator for its type merged on top.
+func (e *Evaluator) evaluateDataSource(b *Block) cty.Value {
+	values := b.Values()
+
+	evaluate, ok := e.dataSourceEvaluators[b.TypeLabel()]
+	if !ok {
+		return values
+	}
+
+	computed := evaluate(DataSource{
+		Block:      b,
+		Provider:   e.providerConfig(b.Provider()),
+		RootPath:   e.module.RootPath,
+		ModulePath: e.module.ModulePath,
+	})
+	if len(computed) == 0 {
+		return values
+	}
+
+	valueMap := values.AsValueMap()
+	if valueMap == nil {
+		valueMap = make(map[string]cty.Value)
+	}
+
+	for k, v := range computed {
+		valueMap[k] = v
+	}
+
+	return cty.ObjectVal(valueMap)
+}
+
+// providerConfig returns the values of the provider block with the given name, e.g. aws or aws.west
+// for an aliased provider. Child modules inherit their provider config from their parents, so the parent
+// modules are also searched. An empty object is returned if no matching provider block is found.
+func (e *Evaluator) providerConfig(name string) cty.Value {
+	parts := strings.SplitN(name, ".", 2)
+
+	alias := ""
+	if len(parts) == 2 {
+		alias = parts[1]
+	}
+
+	for m := &e.module; m != nil; m = m.Parent {
+		for _, b := range m.Blocks.OfType("provider") {
+			if b.Label() != parts[0] {
+				continue
+			}
+
+			if stringAttr(b, "alias") == alias {
+				return b.Values()
+			}
+		}
+	}
+
+	return cty.EmptyObjectVal
+}
+
 func (e *Evaluator) getValuesByBlockType(blockType string) cty.Value {
 	blocksOfType := e.module.Blocks.OfType(blockType)
 	values := make(map[string]cty.Value)
@@ -412,7 +577,11 @@ func (e *Evaluator) getValuesByBlockType(blockType string) cty.Value {
 				valueMap = make(map[string]cty.Value)
 			}
 
-			valueMap[b.Labels()[1]] = b.Values()
+			if b.Type() == "data" {
+				valueMap[b.Labels()[1]] = e.evaluateDataSource(b)
+			} else {
+				valueMap[b.Labels()[1]] = b.Values()
+			}
 			values[b.Labels()[0]] = cty.ObjectVal(valueMap)
 		}
 
@@ -447,13 +616,25 @@ func (e *Evaluator) loadModule(b *Block) (*ModuleCall, error) {
 
 	if e.moduleMetadata != nil {
 		// if we have module metadata we can parse all the modules as they'll be cached locally!
+		// Look the module up by its key first, since different module calls can use the same source
+		// with a different version, then fall back to matching on the source.
+		key := moduleKey(b)
 		for _, module := range e.moduleMetadata.Modules {
-			reg := "registry.terraform.io/" + source
-			if module.Source == source || module.Source == reg {
+			if module.Key == key {
 				modulePath = filepath.Clean(filepath.Join(e.module.RootPath, module.Dir))
 				break
 			}
 		}
+
+		if modulePath == "" {
+			for _, module := range e.moduleMetadata.Modules {
+				reg := "registry.terraform.io/" + source
+				if module.Source == source || module.Source == reg {
+					modulePath = filepath.Clean(filepath.Join(e.module.RootPath, module.Dir))
+					break
+				}
+			}
+		}
 	}
 
 	if modulePath == "" {
@@ -510,9 +691,9 @@ func (e *Evaluator) loadModules() []*ModuleCall {
 	return moduleDefinitions
 }
 
-// expFunctions returns the set of functions that should be used to when evaluating
+// ExpFunctions returns the set of functions that should be used to when evaluating
 // expressions in the receiving scope.
-func expFunctions(baseDir string) map[string]function.Function {
+func ExpFunctions(baseDir string) map[string]function.Function {
 	return map[string]function.Function{
 		"abs":              stdlib.AbsoluteFunc,
 		"abspath":          funcs.AbsPathFunc,
@@ -1,5 +1,13 @@
 package hcl
 
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
 // ModuleCall represents a call to a defined Module by a parent Module.
 type ModuleCall struct {
 	// Name the name of the module as specified a the point of definition.
@@ -10,6 +18,9 @@ type ModuleCall struct {
 	Definition *Block
 	// Module contains the parsed root module that represents this ModuleCall.
 	Module *Module
+
+	// outputs holds the evaluated outputs of Module once it has been run.
+	outputs cty.Value
 }
 
 // Module encapsulates all the Blocks that are part of a Module in a Terraform project.
@@ -23,4 +34,42 @@ type Module struct {
 
 	Modules []*Module
 	Parent  *Module
+
+	// MissingVariables are the names of the required variables of the root Module that were not set by
+	// any tfvars file, cmd line input var or TF_VAR_ environment variable. These evaluate as unknown.
+	MissingVariables []string
+}
+
+var instanceKeyRegex = regexp.MustCompile(`^([^\[]+)\[(.+)\]$`)
+
+// splitInstanceKey splits the label of a module expanded by count or for_each into the module
+// name and the instance key, e.g. svc["a"] returns svc and "a". The key is cty.NilVal if the
+// label has no index.
+func splitInstanceKey(label string) (string, cty.Value) {
+	m := instanceKeyRegex.FindStringSubmatch(label)
+	if m == nil {
+		return label, cty.NilVal
+	}
+
+	if s, err := strconv.Unquote(m[2]); err == nil {
+		return m[1], cty.StringVal(s)
+	}
+
+	if i, err := strconv.ParseInt(m[2], 10, 64); err == nil {
+		return m[1], cty.NumberIntVal(i)
+	}
+
+	return m[1], cty.StringVal(m[2])
+}
+
+// moduleKey returns the key of the module block in the modules manifest, e.g. parent.child.
+// Count and for_each indexes are removed since every instance of a module call uses the same module.
+func moduleKey(b *Block) string {
+	var parts []string
+	for m := b; m != nil; m = m.moduleBlock {
+		name, _ := splitInstanceKey(m.TypeLabel())
+		parts = append([]string{name}, parts...)
+	}
+
+	return strings.Join(parts, ".")
 }
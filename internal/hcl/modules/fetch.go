@@ -2,8 +2,10 @@ package modules
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	getter "github.com/hashicorp/go-getter"
@@ -11,23 +13,32 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// forcedGetterRegex matches sources that force a getter, e.g. git::https://example.com/repo.git
+var forcedGetterRegex = regexp.MustCompile(`^([A-Za-z0-9]+)::(.+)$`)
+
 // PackageFetcher downloads modules from a remote source to the given destination
 // This supports all the non-local and non-Terraform registry sources listed here: https://www.terraform.io/language/modules/sources
 type PackageFetcher struct {
 	cache map[string]string
+	// env is the environment of the project, which is passed to git so it can access private repositories
+	env map[string]string
 }
 
-// NewPackageFetcher constructs a new package fetcher
-func NewPackageFetcher() *PackageFetcher {
+// NewPackageFetcher constructs a new package fetcher. The env is the environment of the project
+// being loaded, which is set for the git commands run to download modules. It can be nil.
+func NewPackageFetcher(env map[string]string) *PackageFetcher {
 	return &PackageFetcher{
 		cache: make(map[string]string),
+		env:   env,
 	}
 }
 
 // fetch downloads the remote module using the go-getter library
 // See: https://github.com/hashicorp/go-getter
 func (r *PackageFetcher) fetch(moduleAddr string, dest string) error {
-	if prevDest, ok := r.cache[moduleAddr]; ok {
+	// The previous destination might not exist anymore if it was a temporary directory
+	// that has since been moved into the shared module cache.
+	if prevDest, ok := r.cache[moduleAddr]; ok && isDir(prevDest) {
 		log.Debugf("Module %s already downloaded, copying from '%s' to '%s'", moduleAddr, prevDest, dest)
 
 		err := os.Mkdir(dest, os.ModePerm)
@@ -62,18 +73,90 @@ func (r *PackageFetcher) fetch(moduleAddr string, dest string) error {
 	// I'm not sure if we really need it, but added it just in case/
 	decompressors["tar.tbz2"] = new(getter.TarBzip2Decompressor)
 
+	getters := map[string]getter.Getter{}
+	for k, g := range getter.Getters {
+		getters[k] = g
+	}
+	// Use our own git getter so git is run with the project env, since the go-getter one
+	// only uses the process environment.
+	getters["git"] = &gitGetter{env: r.env}
+
 	client := getter.Client{
 		Src:           moduleAddr,
 		Dst:           dest,
 		Pwd:           dest,
 		Mode:          getter.ClientModeDir,
 		Decompressors: decompressors,
-		// We don't need to specify any of the Getters, since Terraform uses the same as the default Getter values,
-		// but if we do need to at some point we can specify them here:
-		// Getters: getters,
+		Getters:       getters,
+	}
+
+	// git is run with the project env added to the current environment so private git repositories can be accessed
+	// with the user's SSH agent, GIT_SSH_COMMAND or git credential helpers, the same as with terraform init.
+	if isGitSource(moduleAddr) {
+		log.Debugf("Downloading git module %s (SSH_AUTH_SOCK set: %t, GIT_SSH_COMMAND set: %t)", moduleAddr, r.getenv("SSH_AUTH_SOCK") != "", r.getenv("GIT_SSH_COMMAND") != "")
 	}
 
 	r.cache[moduleAddr] = dest
 
 	return client.Get()
 }
+
+// getenv returns the value of the environment variable from the project env if it is set there,
+// otherwise from the process environment.
+func (r *PackageFetcher) getenv(key string) string {
+	if v, ok := r.env[key]; ok {
+		return v
+	}
+
+	return os.Getenv(key)
+}
+
+// remoteModuleError returns an error for a remote module that failed to download which names the
+// module and the host it was downloaded from. Private repositories are the most common cause of
+// git modules failing to download, so for these it also explains how to set up authentication.
+func remoteModuleError(key string, moduleAddr string, err error) error {
+	host := sourceHost(moduleAddr)
+
+	if isGitSource(moduleAddr) {
+		return fmt.Errorf("Failed to download module %s from %s: %w. If this is a private repository make sure git can authenticate with %s, for example by adding your key to an SSH agent, setting GIT_SSH_COMMAND or configuring a git credential helper", key, host, err, host)
+	}
+
+	return fmt.Errorf("Failed to download module %s from %s: %w", key, host, err)
+}
+
+// detectSource returns the getter and the URL go-getter will use to download the module source.
+func detectSource(moduleAddr string) (string, string) {
+	src, err := getter.Detect(moduleAddr, "", getter.Detectors)
+	if err != nil {
+		return "", moduleAddr
+	}
+
+	if m := forcedGetterRegex.FindStringSubmatch(src); m != nil {
+		return m[1], m[2]
+	}
+
+	u, err := url.Parse(src)
+	if err != nil {
+		return "", src
+	}
+
+	return u.Scheme, src
+}
+
+// isGitSource returns true if the module source is downloaded with git.
+func isGitSource(moduleAddr string) bool {
+	g, _ := detectSource(moduleAddr)
+	return g == "git"
+}
+
+// sourceHost returns the host of the module source, or the source itself if the host can't be found.
+func sourceHost(moduleAddr string) string {
+	_, src := detectSource(moduleAddr)
+
+	u, err := url.Parse(src)
+	if err != nil || u.Host == "" {
+		return moduleAddr
+	}
+
+	return u.Host
+}
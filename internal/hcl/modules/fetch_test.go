@@ -0,0 +1,48 @@
+package modules
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchGitModuleUsesProjectEnv(t *testing.T) {
+	repoDir := t.TempDir()
+	gitCmd := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoDir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	gitCmd("init", "-q")
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "main.tf"), []byte("// Placeholder file\n"), 0600))
+	gitCmd("add", "main.tf")
+	gitCmd("commit", "-q", "-m", "init")
+	gitCmd("tag", "v1.0.0")
+
+	// The project env rewrites the remote URL to the local repository, so the module can only
+	// be downloaded if the env is passed to git.
+	env := map[string]string{
+		"GIT_CONFIG_COUNT":   "1",
+		"GIT_CONFIG_KEY_0":   "url." + repoDir + ".insteadOf",
+		"GIT_CONFIG_VALUE_0": "https://git.example.invalid/my-org/module.git",
+	}
+
+	dest := filepath.Join(t.TempDir(), "module")
+	err := NewPackageFetcher(env).fetch("git::https://git.example.invalid/my-org/module.git?ref=v1.0.0", dest)
+	require.NoError(t, err)
+
+	contents, err := os.ReadFile(filepath.Join(dest, "main.tf"))
+	require.NoError(t, err)
+	assert.Equal(t, "// Placeholder file\n", string(contents))
+
+	dest = filepath.Join(t.TempDir(), "module")
+	err = NewPackageFetcher(nil).fetch("git::https://git.example.invalid/my-org/module.git?ref=v1.0.0", dest)
+	assert.Error(t, err)
+}
@@ -0,0 +1,178 @@
+package modules
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	getter "github.com/hashicorp/go-getter"
+)
+
+// gitRemoteHeadRegex matches the default branch in the output of git ls-remote --symref.
+var gitRemoteHeadRegex = regexp.MustCompile(`ref: refs/heads/([^\s]+).*`)
+
+// gitGetter downloads modules from git repositories. It supports the same ref, depth and sshkey
+// query parameters as the go-getter GitGetter, but runs git with the environment of the project,
+// so project env values like GIT_SSH_COMMAND, SSH_AUTH_SOCK or GIT_CONFIG_* are used to access private
+// repositories without setting them in the process environment.
+type gitGetter struct {
+	getter.GitGetter
+	env map[string]string
+}
+
+// Get clones the repository in the URL into dst and checks out the ref if one is set.
+func (g *gitGetter) Get(dst string, u *url.URL) error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("git must be available and on the PATH")
+	}
+
+	var ref, sshKey string
+	depth := 0
+
+	q := u.Query()
+	if len(q) > 0 {
+		ref = q.Get("ref")
+		q.Del("ref")
+
+		sshKey = q.Get("sshkey")
+		q.Del("sshkey")
+
+		if n, err := strconv.Atoi(q.Get("depth")); err == nil {
+			depth = n
+		}
+		q.Del("depth")
+
+		newU := *u
+		u = &newU
+		u.RawQuery = q.Encode()
+	}
+
+	var sshKeyFile string
+	if sshKey != "" {
+		raw, err := base64.StdEncoding.DecodeString(sshKey)
+		if err != nil {
+			return fmt.Errorf("Failed to decode sshkey: %w", err)
+		}
+
+		f, err := ioutil.TempFile("", "infracost-git-key")
+		if err != nil {
+			return err
+		}
+		sshKeyFile = f.Name()
+		defer os.Remove(sshKeyFile)
+
+		if err := os.Chmod(sshKeyFile, 0600); err != nil {
+			return err
+		}
+
+		_, err = f.Write(raw)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	if depth > 0 && ref == "" {
+		ref = g.remoteDefaultBranch(u, sshKeyFile)
+	}
+
+	args := []string{"clone"}
+	if depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(depth), "--branch", ref)
+	}
+	args = append(args, u.String(), dst)
+
+	err := g.run("", sshKeyFile, args...)
+	if err != nil {
+		return err
+	}
+
+	if depth < 1 && ref != "" {
+		err = g.run(dst, sshKeyFile, "checkout", ref)
+		if err != nil {
+			return err
+		}
+	}
+
+	args = []string{"submodule", "update", "--init", "--recursive"}
+	if depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(depth))
+	}
+
+	return g.run(dst, sshKeyFile, args...)
+}
+
+// GetFile is not supported since modules are always downloaded as directories.
+func (g *gitGetter) GetFile(dst string, u *url.URL) error {
+	return fmt.Errorf("Downloading a single file with git is not supported")
+}
+
+// remoteDefaultBranch returns the default branch of the remote repository, or master if it can't be found.
+func (g *gitGetter) remoteDefaultBranch(u *url.URL, sshKeyFile string) string {
+	var stdout bytes.Buffer
+
+	cmd := exec.Command("git", "ls-remote", "--symref", u.String(), "HEAD")
+	cmd.Env = g.environ(sshKeyFile)
+	cmd.Stdout = &stdout
+
+	err := cmd.Run()
+	m := gitRemoteHeadRegex.FindStringSubmatch(stdout.String())
+	if err != nil || m == nil {
+		return "master"
+	}
+
+	return m[1]
+}
+
+// run runs git with the args in the dir, returning the output of git in the error if it fails.
+func (g *gitGetter) run(dir string, sshKeyFile string, args ...string) error {
+	var buf bytes.Buffer
+
+	cmd := exec.CommandContext(g.Context(), "git", args...)
+	cmd.Dir = dir
+	cmd.Env = g.environ(sshKeyFile)
+	cmd.Stdout = &buf
+	cmd.Stderr = &buf
+
+	err := cmd.Run()
+	if err != nil {
+		return fmt.Errorf("%s exited with %w: %s", cmd.String(), err, strings.TrimSpace(buf.String()))
+	}
+
+	return nil
+}
+
+// environ returns the process environment with the project env added. If an SSH key file is set
+// then it is added to the GIT_SSH_COMMAND.
+func (g *gitGetter) environ(sshKeyFile string) []string {
+	env := os.Environ()
+	for k, v := range g.env {
+		env = append(env, fmt.Sprintf("%s=%s", k, v))
+	}
+
+	if sshKeyFile != "" {
+		sshCmd, ok := g.env["GIT_SSH_COMMAND"]
+		if !ok {
+			sshCmd = os.Getenv("GIT_SSH_COMMAND")
+		}
+		if sshCmd == "" {
+			sshCmd = "ssh"
+		}
+
+		if runtime.GOOS == "windows" {
+			sshKeyFile = strings.ReplaceAll(sshKeyFile, `\`, `/`)
+		}
+
+		env = append(env, fmt.Sprintf("GIT_SSH_COMMAND=%s -i %s", sshCmd, sshKeyFile))
+	}
+
+	return env
+}
@@ -25,25 +25,33 @@ var (
 // ModuleLoader handles the loading of Terraform modules. It supports local, registry and other remote modules.
 //
 // The path should be the root directory of the Terraform project. We use a distinct module loader per Terraform project,
-// because at the moment the cache is per project. The cache reads the manifest.json file from the path's
-// .infracost/terraform_modules directory. We could implement a global cache in the future, but for now have decided
-// to go with the same approach as Terraform.
+// because the manifest is per project. The cache reads the manifest.json file from the path's
+// .infracost/terraform_modules directory.
+//
+// If a SharedCache is set then registry modules and remote modules pinned to a commit SHA or version tag are downloaded into the shared
+// cache instead of the project's .infracost/terraform_modules directory, and the manifest points to the module in the
+// shared cache. This means projects that use the same module version only download it once.
 type ModuleLoader struct {
 	Path           string
 	cache          *Cache
+	sharedCache    *SharedCache
 	packageFetcher *PackageFetcher
 	registryLoader *RegistryLoader
 }
 
-// NewModuleLoader constructs a new module loader
-func NewModuleLoader(path string) *ModuleLoader {
-	fetcher := NewPackageFetcher()
+// NewModuleLoader constructs a new module loader. The sharedCache can be nil, in which case
+// modules are downloaded to the project's .infracost/terraform_modules directory. The env is the
+// environment of the project, which is used to find registry credentials and is set for the git
+// commands run to download modules. It can be nil.
+func NewModuleLoader(path string, sharedCache *SharedCache, env map[string]string) *ModuleLoader {
+	fetcher := NewPackageFetcher(env)
 
 	return &ModuleLoader{
 		Path:           path,
 		cache:          NewCache(),
+		sharedCache:    sharedCache,
 		packageFetcher: fetcher,
-		registryLoader: NewRegistryLoader(fetcher),
+		registryLoader: NewRegistryLoader(fetcher, env),
 	}
 }
 
@@ -107,6 +115,23 @@ func (m *ModuleLoader) Load() (*Manifest, error) {
 	return manifest, nil
 }
 
+// LoadSource loads the module with the given source and version and returns the directory containing it.
+// The source can be a local path relative to the loader's path, a registry module or any other remote module
+// source. This is used for projects whose root module is itself a module source, such as Terragrunt configs
+// that set terraform.source.
+func (m *ModuleLoader) LoadSource(key string, source string, version string) (string, error) {
+	manifestModule, err := m.loadModule(&tfconfig.ModuleCall{
+		Name:    key,
+		Source:  source,
+		Version: version,
+	}, m.Path, "")
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Clean(filepath.Join(m.Path, manifestModule.Dir)), nil
+}
+
 // loadModules recursively loads the modules from the given path.
 func (m *ModuleLoader) loadModules(path string, prefix string) ([]*ManifestModule, error) {
 	manifestModules := make([]*ManifestModule, 0)
@@ -177,52 +202,96 @@ func (m *ModuleLoader) loadModule(moduleCall *tfconfig.ModuleCall, parentPath st
 		return manifestModule, nil
 	}
 
-	dest := filepath.Join(m.downloadDir(), key)
-
-	// Since we're downloading the module, make sure any old installation of it is removed
-	// since this can cause issues with go-getter
-	err = os.RemoveAll(dest)
-	if err != nil && !os.IsNotExist(err) {
-		return nil, fmt.Errorf("error cleaning up existing module from '%s': %w", dest, err)
-	}
-
 	moduleAddr, submodulePath, err := splitModuleSubDir(moduleCall.Source)
 	if err != nil {
 		return nil, err
 	}
 
-	moduleDownloadDir, err := filepath.Rel(m.Path, dest)
-	if err != nil {
-		return nil, err
-	}
-	manifestModule.Dir = path.Clean(filepath.Join(moduleDownloadDir, submodulePath))
-
 	lookupResult, err := m.registryLoader.lookupModule(moduleAddr, moduleCall.Version)
 	if err == nil {
 		log.Debugf("Downloading module %s from registry URL %s", key, lookupResult.DownloadURL)
-		err = m.registryLoader.downloadModule(lookupResult.DownloadURL, dest)
+		moduleDir, err := m.download(key, lookupResult.Source, lookupResult.Version, true, func(dest string) error {
+			return m.registryLoader.downloadModule(lookupResult.DownloadURL, dest)
+		})
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("Failed to download module %s from %s: %w", key, lookupResult.Source, err)
 		}
 
 		// The moduleCall.Source might not have the registry hostname if it is using the default registry
 		// so we set the source here to the lookup result's source which always includes the registry hostname.
 		manifestModule.Source = joinModuleSubDir(lookupResult.Source, submodulePath)
-
+		manifestModule.Dir = path.Clean(filepath.Join(moduleDir, submodulePath))
 		manifestModule.Version = lookupResult.Version
 		return manifestModule, nil
 	}
 
+	// The module is a registry module but the registry needs credentials, so there's no point trying to
+	// download it as a remote module.
+	var authErr *RegistryAuthError
+	if errors.As(err, &authErr) {
+		return nil, fmt.Errorf("Failed to load module %s from %s: %w", key, moduleCall.Source, err)
+	}
+
 	log.Debugf("Module %s not recognized as registry module, treating as remote module: %s", key, err.Error())
 	log.Debugf("Downloading module %s from remote %s", key, moduleCall.Source)
-	err = m.packageFetcher.fetch(moduleAddr, dest)
+	moduleDir, err := m.download(key, moduleAddr, "", isPinnedRemoteSource(moduleAddr), func(dest string) error {
+		return m.packageFetcher.fetch(moduleAddr, dest)
+	})
 	if err != nil {
-		return nil, err
+		return nil, remoteModuleError(key, moduleAddr, err)
 	}
 
+	manifestModule.Dir = path.Clean(filepath.Join(moduleDir, submodulePath))
 	return manifestModule, nil
 }
 
+// download downloads a module using the given download function and returns the directory
+// it was downloaded to, relative to the project path. If the module can be shared and a shared
+// cache is set, the module is downloaded into the shared cache. Otherwise it is downloaded
+// into the project's download directory.
+func (m *ModuleLoader) download(key string, source string, version string, shareable bool, download func(dest string) error) (string, error) {
+	dest := filepath.Join(m.downloadDir(), key)
+
+	if m.sharedCache != nil && shareable {
+		cacheDir, err := m.sharedCache.get(source, version, download)
+		if err != nil {
+			return "", err
+		}
+
+		dest = cacheDir
+	} else {
+		// Since we're downloading the module, make sure any old installation of it is removed
+		// since this can cause issues with go-getter
+		err := os.RemoveAll(dest)
+		if err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("error cleaning up existing module from '%s': %w", dest, err)
+		}
+
+		err = download(dest)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return relPath(m.Path, dest)
+}
+
+// relPath returns the target path relative to the base path, making both absolute
+// first since the shared cache directory is usually outside the project.
+func relPath(base string, target string) (string, error) {
+	absBase, err := filepath.Abs(base)
+	if err != nil {
+		return "", err
+	}
+
+	absTarget, err := filepath.Abs(target)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Rel(absBase, absTarget)
+}
+
 // isLocalModule checks if the module is a local module by checking
 // if the module source starts with any known local prefixes
 func (m *ModuleLoader) isLocalModule(moduleCall *tfconfig.ModuleCall) bool {
@@ -1,6 +1,7 @@
 package modules
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"sort"
@@ -15,7 +16,7 @@ func testLoaderE2E(t *testing.T, path string, expectedModules []*ManifestModule,
 		assert.NoError(t, err)
 	}
 
-	moduleLoader := NewModuleLoader(path)
+	moduleLoader := NewModuleLoader(path, nil, nil)
 
 	manifest, err := moduleLoader.Load()
 	if !assert.NoError(t, err) {
@@ -188,3 +189,15 @@ func TestWithCachedModules(t *testing.T) {
 	assert.Equal(t, string(regModContents), "// Placeholder file\n")
 	assert.Equal(t, string(gitModContents), "// Placeholder file\n")
 }
+
+func TestRemoteModuleError(t *testing.T) {
+	err := remoteModuleError("vpc", "git::ssh://git@github.com/my-org/private-module.git?ref=v1.0.0", errors.New("exit status 128"))
+	assert.Contains(t, err.Error(), "Failed to download module vpc from github.com: exit status 128")
+	assert.Contains(t, err.Error(), "SSH agent")
+
+	err = remoteModuleError("vpc", "git@gitlab.com:my-org/private-module.git", errors.New("exit status 128"))
+	assert.Contains(t, err.Error(), "Failed to download module vpc from gitlab.com")
+
+	err = remoteModuleError("bucket", "https://example.com/module.zip", errors.New("bad response code: 403"))
+	assert.Equal(t, "Failed to download module bucket from example.com: bad response code: 403", err.Error())
+}
@@ -11,6 +11,9 @@ import (
 	"strings"
 
 	goversion "github.com/hashicorp/go-version"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/infracost/infracost/internal/credentials"
 )
 
 var defaultRegistryHost = "registry.terraform.io"
@@ -26,15 +29,41 @@ type RegistryLookupResult struct {
 	DownloadURL string
 }
 
+// RegistryAuthError is returned when a registry rejects a request because it requires credentials
+// that were either not set or are not valid for the module.
+type RegistryAuthError struct {
+	Host       string
+	StatusCode int
+	// TokenSet is true if a token for the host was sent with the request
+	TokenSet bool
+}
+
+func (e *RegistryAuthError) Error() string {
+	if e.TokenSet {
+		return fmt.Sprintf("registry %s rejected the credentials set for it (status code %d). Check the token in your Terraform CLI config file or %s environment variable has access to this module", e.Host, e.StatusCode, credentials.TokenEnvName(e.Host))
+	}
+
+	return fmt.Sprintf("registry %s requires authentication (status code %d). Add a credentials block for %s to your Terraform CLI config file, run terraform login %s, or set the %s environment variable", e.Host, e.StatusCode, e.Host, e.Host, credentials.TokenEnvName(e.Host))
+}
+
 // RegistryLoader is a loader that can lookup modules from a Terraform Registry and download them to the given destination
 type RegistryLoader struct {
 	packageFetcher *PackageFetcher
+	httpClient     *http.Client
+	// tokens caches the registry tokens by host so the Terraform credential files are only read once per host
+	tokens map[string]string
+	// env is the environment of the project, which is used to find TF_TOKEN_ variables before the process environment
+	env map[string]string
 }
 
-// NewRegistryLoader constructs a registry loader
-func NewRegistryLoader(packageFetcher *PackageFetcher) *RegistryLoader {
+// NewRegistryLoader constructs a registry loader. The env is the environment of the project being
+// loaded, which can be nil.
+func NewRegistryLoader(packageFetcher *PackageFetcher, env map[string]string) *RegistryLoader {
 	return &RegistryLoader{
 		packageFetcher: packageFetcher,
+		httpClient:     &http.Client{},
+		tokens:         make(map[string]string),
+		env:            env,
 	}
 }
 
@@ -59,7 +88,7 @@ func (r *RegistryLoader) lookupModule(moduleAddr string, versionConstraints stri
 	// We now need to check the registry to see if the module exists and if it has a version
 	moduleURL := fmt.Sprintf("https://%s/v1/modules/%s/%s/%s", host, namespace, moduleName, target)
 
-	versions, err := r.fetchModuleVersions(moduleURL)
+	versions, err := r.fetchModuleVersions(host, moduleURL)
 	if err != nil {
 		return nil, err
 	}
@@ -81,14 +110,17 @@ func (r *RegistryLoader) lookupModule(moduleAddr string, versionConstraints stri
 }
 
 // fetchModuleVersions fetches the list of versions from the registry endpoint for the given module URL
-func (r *RegistryLoader) fetchModuleVersions(moduleURL string) ([]string, error) {
-	httpClient := &http.Client{}
-	resp, err := httpClient.Get(moduleURL + "/versions")
+func (r *RegistryLoader) fetchModuleVersions(host string, moduleURL string) ([]string, error) {
+	resp, err := r.get(host, moduleURL+"/versions")
 	if err != nil {
 		return nil, fmt.Errorf("Failed to fetch registry module versions: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return nil, &RegistryAuthError{Host: host, StatusCode: resp.StatusCode, TokenSet: r.token(host) != ""}
+	}
+
 	if resp.StatusCode != 200 {
 		return nil, fmt.Errorf("Module versions endpoint returned status code %d", resp.StatusCode)
 	}
@@ -127,13 +159,21 @@ func (r *RegistryLoader) fetchModuleVersions(moduleURL string) ([]string, error)
 // downloadModule downloads the module to the loader's destination
 // It first calls the download URL to get the X-Terraform-Get header which contains a source we can use with go-getter to download the module
 func (r *RegistryLoader) downloadModule(downloadURL string, dest string) error {
-	httpClient := &http.Client{}
-	resp, err := httpClient.Get(downloadURL)
+	u, err := url.Parse(downloadURL)
+	if err != nil {
+		return fmt.Errorf("Invalid registry module download URL: %w", err)
+	}
+
+	resp, err := r.get(u.Host, downloadURL)
 	if err != nil {
 		return fmt.Errorf("Failed to download registry module: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return &RegistryAuthError{Host: u.Host, StatusCode: resp.StatusCode, TokenSet: r.token(u.Host) != ""}
+	}
+
 	source := resp.Header.Get("X-Terraform-Get")
 	if source == "" {
 		return errors.New("download URL has no X-Terraform-Get header")
@@ -142,6 +182,35 @@ func (r *RegistryLoader) downloadModule(downloadURL string, dest string) error {
 	return r.packageFetcher.fetch(source, dest)
 }
 
+// get calls the registry URL, adding the token for the registry host if one is set.
+func (r *RegistryLoader) get(host string, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if token := r.token(host); token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	}
+
+	return r.httpClient.Do(req)
+}
+
+// token returns the Terraform credentials token for the registry host, or an empty string if there isn't one.
+func (r *RegistryLoader) token(host string) string {
+	if token, ok := r.tokens[host]; ok {
+		return token
+	}
+
+	token := credentials.FindTerraformToken(host, r.env)
+	if token != "" {
+		log.Debugf("Using Terraform credentials for registry %s", host)
+	}
+
+	r.tokens[host] = token
+	return token
+}
+
 // findLatestMatchingVersion returns the latest version from a list of versions that matches the given constraint.
 // The constraints can be in any format that go-version understands, for example: "1.2.0", "~> 1.0", ">= 1.0, < 1.4"
 // If the constraints are empty then the latest version is returned
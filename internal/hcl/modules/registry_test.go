@@ -1,9 +1,17 @@
 package modules
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFindLatestMatchingVersion(t *testing.T) {
@@ -70,3 +78,58 @@ func TestNormalizeHost(t *testing.T) {
 		assert.Equal(t, test.expected, actual)
 	}
 }
+
+func newPrivateRegistryServer(token string) *httptest.Server {
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if r.URL.Path == "/v1/modules/my-org/vpc/aws/versions" {
+			fmt.Fprint(w, `{"modules":[{"versions":[{"version":"1.0.0"},{"version":"1.1.0"}]}]}`)
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+}
+
+func TestLookupModulePrivateRegistry(t *testing.T) {
+	server := newPrivateRegistryServer("secret")
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	confFile := filepath.Join(t.TempDir(), "terraform.rc")
+	err := os.WriteFile(confFile, []byte(fmt.Sprintf("credentials %q {\n  token = \"secret\"\n}\n", host)), 0600)
+	require.NoError(t, err)
+	t.Setenv("TF_CLI_CONFIG_FILE", confFile)
+
+	r := NewRegistryLoader(NewPackageFetcher(nil), nil)
+	r.httpClient = server.Client()
+
+	result, err := r.lookupModule(host+"/my-org/vpc/aws", "~> 1.0")
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.0", result.Version)
+	assert.Equal(t, fmt.Sprintf("https://%s/v1/modules/my-org/vpc/aws/1.1.0/download", host), result.DownloadURL)
+}
+
+func TestLookupModulePrivateRegistryMissingCredentials(t *testing.T) {
+	server := newPrivateRegistryServer("secret")
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	r := NewRegistryLoader(NewPackageFetcher(nil), nil)
+	r.httpClient = server.Client()
+
+	_, err := r.lookupModule(host+"/my-org/vpc/aws", "")
+
+	var authErr *RegistryAuthError
+	require.True(t, errors.As(err, &authErr))
+	assert.Equal(t, host, authErr.Host)
+	assert.Equal(t, http.StatusUnauthorized, authErr.StatusCode)
+	assert.False(t, authErr.TokenSet)
+	assert.Contains(t, err.Error(), fmt.Sprintf("registry %s requires authentication", host))
+}
@@ -0,0 +1,165 @@
+package modules
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// lockRetryInterval is how often we retry acquiring a module lock held by another process
+	lockRetryInterval = 100 * time.Millisecond
+	// lockStaleAge is the age after which a lock file is assumed to be left over from a process that was killed
+	lockStaleAge = 10 * time.Minute
+	// commitSHARegex matches a full or abbreviated git commit SHA
+	commitSHARegex = regexp.MustCompile(`^[0-9a-fA-F]{7,40}$`)
+	// versionTagRegex matches a version tag, e.g. v1.2.3, 1.2 or v2.0.0-rc1
+	versionTagRegex = regexp.MustCompile(`^v?[0-9]+(\.[0-9]+)*([-+][0-9A-Za-z.-]+)?$`)
+)
+
+// SharedCache is a content-addressed cache of remote modules that is shared by all projects, both within a
+// run and across runs. Each module is stored in a directory named after a hash of its source and resolved
+// version, so the same module version is only downloaded once no matter how many projects use it.
+//
+// A lock file is held while a module is downloaded so that parallel project runs, or separate Infracost
+// processes, don't download the same module into the cache at the same time. Modules are downloaded to a
+// temporary directory and then renamed into place, so a module directory in the cache is always complete.
+type SharedCache struct {
+	dir string
+}
+
+// NewSharedCache constructs a new shared module cache stored in the given directory
+func NewSharedCache(dir string) *SharedCache {
+	return &SharedCache{
+		dir: dir,
+	}
+}
+
+// cacheKey returns the content address for a module source and resolved version.
+func (c *SharedCache) cacheKey(source string, version string) string {
+	h := sha256.Sum256([]byte(source + "@" + version))
+	return hex.EncodeToString(h[:])
+}
+
+// get returns the directory in the cache containing the module for the given source and version.
+// If the module is not in the cache, download is called to download it.
+func (c *SharedCache) get(source string, version string, download func(dest string) error) (string, error) {
+	key := c.cacheKey(source, version)
+	moduleDir := filepath.Join(c.dir, key)
+
+	if isDir(moduleDir) {
+		log.Debugf("Module %s %s found in shared module cache at '%s'", source, version, moduleDir)
+		return moduleDir, nil
+	}
+
+	err := os.MkdirAll(c.dir, os.ModePerm)
+	if err != nil {
+		return "", fmt.Errorf("Failed to create module cache directory '%s': %w", c.dir, err)
+	}
+
+	unlock, err := c.lock(key)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	// Another process might have downloaded the module while we were waiting for the lock
+	if isDir(moduleDir) {
+		log.Debugf("Module %s %s found in shared module cache at '%s'", source, version, moduleDir)
+		return moduleDir, nil
+	}
+
+	tmpDir, err := os.MkdirTemp(c.dir, key+".tmp")
+	if err != nil {
+		return "", fmt.Errorf("Failed to create temporary module directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	dest := filepath.Join(tmpDir, "module")
+
+	err = download(dest)
+	if err != nil {
+		return "", err
+	}
+
+	err = os.Rename(dest, moduleDir)
+	if err != nil {
+		// If a download took longer than lockStaleAge then another process could have taken over the
+		// lock and added the module first, in which case we use theirs and our download is removed.
+		if isDir(moduleDir) {
+			log.Debugf("Module %s %s was added to the shared module cache at '%s' by another process", source, version, moduleDir)
+			return moduleDir, nil
+		}
+
+		return "", fmt.Errorf("Failed to move module into the module cache '%s': %w", moduleDir, err)
+	}
+
+	log.Debugf("Added module %s %s to shared module cache at '%s'", source, version, moduleDir)
+
+	return moduleDir, nil
+}
+
+// lock acquires the lock for the given cache key by creating a lock file. It waits until any
+// existing lock is released, or removes it if it is stale. It returns a function that releases the lock.
+func (c *SharedCache) lock(key string) (func(), error) {
+	lockPath := filepath.Join(c.dir, key+".lock")
+
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			f.Close()
+
+			return func() {
+				err := os.Remove(lockPath)
+				if err != nil {
+					log.Debugf("Error removing module cache lock '%s': %s", lockPath, err)
+				}
+			}, nil
+		}
+
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("Failed to acquire module cache lock '%s': %w", lockPath, err)
+		}
+
+		info, err := os.Stat(lockPath)
+		if err == nil && time.Since(info.ModTime()) > lockStaleAge {
+			log.Debugf("Removing stale module cache lock '%s'", lockPath)
+			_ = os.Remove(lockPath)
+			continue
+		}
+
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// isPinnedRemoteSource returns true if the remote module source is pinned to a ref that can't change,
+// so it can be safely stored in the shared module cache and reused across runs. Only commit SHAs and
+// version tags are treated as pinned, since any other ref could be a branch that moves between runs.
+func isPinnedRemoteSource(moduleAddr string) bool {
+	_, src := detectSource(moduleAddr)
+
+	u, err := url.Parse(src)
+	if err != nil {
+		return false
+	}
+
+	ref := u.Query().Get("ref")
+
+	return commitSHARegex.MatchString(ref) || versionTagRegex.MatchString(ref)
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+
+	return info.IsDir()
+}
@@ -0,0 +1,126 @@
+package modules
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSharedCacheGetDownloadsOnce(t *testing.T) {
+	cache := NewSharedCache(t.TempDir())
+
+	var downloads int32
+	download := func(dest string) error {
+		atomic.AddInt32(&downloads, 1)
+		time.Sleep(10 * time.Millisecond)
+
+		err := os.MkdirAll(dest, os.ModePerm)
+		if err != nil {
+			return err
+		}
+
+		return os.WriteFile(filepath.Join(dest, "main.tf"), []byte(""), 0600)
+	}
+
+	var wg sync.WaitGroup
+	dirs := make([]string, 10)
+
+	for i := range dirs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			dir, err := cache.get("registry.terraform.io/terraform-aws-modules/vpc/aws", "3.14.0", download)
+			assert.NoError(t, err)
+			dirs[i] = dir
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), downloads)
+	for _, dir := range dirs {
+		assert.Equal(t, dirs[0], dir)
+	}
+	assert.FileExists(t, filepath.Join(dirs[0], "main.tf"))
+
+	other, err := cache.get("registry.terraform.io/terraform-aws-modules/vpc/aws", "3.15.0", download)
+	require.NoError(t, err)
+	assert.NotEqual(t, dirs[0], other)
+	assert.Equal(t, int32(2), downloads)
+}
+
+func TestSharedCacheRemovesStaleLock(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewSharedCache(dir)
+
+	lockPath := filepath.Join(dir, cache.cacheKey("git::https://example.com/module.git?ref=v1", "")+".lock")
+	require.NoError(t, os.WriteFile(lockPath, []byte(""), 0600))
+
+	stale := time.Now().Add(-2 * lockStaleAge)
+	require.NoError(t, os.Chtimes(lockPath, stale, stale))
+
+	moduleDir, err := cache.get("git::https://example.com/module.git?ref=v1", "", func(dest string) error {
+		return os.MkdirAll(dest, os.ModePerm)
+	})
+	require.NoError(t, err)
+
+	assert.DirExists(t, moduleDir)
+	assert.NoFileExists(t, lockPath)
+}
+
+func TestSharedCacheModuleAddedDuringDownload(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewSharedCache(dir)
+
+	source := "git::https://example.com/module.git?ref=v1"
+	moduleDir := filepath.Join(dir, cache.cacheKey(source, ""))
+
+	got, err := cache.get(source, "", func(dest string) error {
+		// Simulate another process that took over the stale lock and added the module first
+		err := os.MkdirAll(moduleDir, os.ModePerm)
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(filepath.Join(moduleDir, "main.tf"), []byte("// theirs"), 0600)
+		if err != nil {
+			return err
+		}
+
+		err = os.MkdirAll(dest, os.ModePerm)
+		if err != nil {
+			return err
+		}
+
+		return os.WriteFile(filepath.Join(dest, "main.tf"), []byte("// ours"), 0600)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, moduleDir, got)
+
+	b, err := os.ReadFile(filepath.Join(moduleDir, "main.tf"))
+	require.NoError(t, err)
+	assert.Equal(t, "// theirs", string(b))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestIsPinnedRemoteSource(t *testing.T) {
+	assert.True(t, isPinnedRemoteSource("git::https://example.com/module.git?ref=v1.0.0"))
+	assert.True(t, isPinnedRemoteSource("git::https://example.com/module.git?depth=1&ref=v1.0.0"))
+	assert.True(t, isPinnedRemoteSource("git::https://example.com/module.git?ref=1.2"))
+	assert.True(t, isPinnedRemoteSource("git::https://example.com/module.git?ref=v2.0.0-rc1"))
+	assert.True(t, isPinnedRemoteSource("git::https://example.com/module.git?ref=51d462976d84fdea54b47d80dcabbf680badcdb8"))
+	assert.True(t, isPinnedRemoteSource("git@github.com:my-org/module.git?ref=51d4629"))
+	assert.False(t, isPinnedRemoteSource("git::https://example.com/module.git"))
+	assert.False(t, isPinnedRemoteSource("git::https://example.com/module.git?ref=main"))
+	assert.False(t, isPinnedRemoteSource("git::https://example.com/module.git?ref=feature/new-vpc"))
+	assert.False(t, isPinnedRemoteSource("git::https://example.com/module.git?ref=release-2022"))
+}
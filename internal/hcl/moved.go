@@ -0,0 +1,52 @@
+package hcl
+
+import (
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Moved represents a moved block that records the previous address of a resource or module call
+// that was renamed or moved, e.g.
+//
+//	moved {
+//	  from = aws_instance.web
+//	  to   = module.web.aws_instance.web
+//	}
+//
+// The addresses are relative to the Module the moved block is defined in.
+type Moved struct {
+	From string
+	To   string
+}
+
+// MovedBlocks returns the moved blocks of the Module. Moved blocks where from or to isn't an
+// address are skipped.
+func (m *Module) MovedBlocks() []Moved {
+	var moved []Moved
+
+	for _, b := range m.Blocks.OfType("moved") {
+		from := movedAddress(b, "from")
+		to := movedAddress(b, "to")
+		if from == "" || to == "" {
+			continue
+		}
+
+		moved = append(moved, Moved{From: from, To: to})
+	}
+
+	return moved
+}
+
+func movedAddress(b *Block, name string) string {
+	attr := b.GetAttribute(name)
+	if attr == nil {
+		return ""
+	}
+
+	traversal, diag := hcl.AbsTraversalForExpr(attr.HCLAttr.Expr)
+	if diag.HasErrors() {
+		log.Debugf("moved block %s is not an address: %s", name, diag.Error())
+		return ""
+	}
+
+	return traversalString(traversal)
+}
@@ -50,21 +50,31 @@ func OptionStopOnHCLError() Option {
 	}
 }
 
-// OptionWithInputVars takes a cmd line var input values and converts them to cty.Value
-// It then sets these as the Parser starting inputVars which be used at the root module evaluation.
+// OptionWithInputVars takes cmd line var input values in the name=value format and sets these as the
+// Parser starting inputVars which be used at the root module evaluation. The values are converted
+// to cty.Value when the directory is parsed, once the type constraints of the variables are known.
 func OptionWithInputVars(vs []string) Option {
 	return func(p *Parser) {
-		ctyVars := make(map[string]cty.Value)
+		inputVars := make(map[string]string)
 		for _, v := range vs {
-			pieces := strings.Split(v, "=")
+			pieces := strings.SplitN(v, "=", 2)
 			if len(pieces) != 2 {
 				continue
 			}
 
-			ctyVars[pieces[0]] = cty.StringVal(pieces[1])
+			inputVars[pieces[0]] = pieces[1]
 		}
 
-		p.inputVars = ctyVars
+		p.inputVars = inputVars
+	}
+}
+
+// OptionWithInputValues sets input variable values that have already been evaluated, such as the inputs of
+// a Terragrunt config. These have the lowest precedence, so they're overridden by TF_VAR_ environment variables,
+// tfvars files and cmd line input vars, and are converted to the type constraint of each variable.
+func OptionWithInputValues(values map[string]cty.Value) Option {
+	return func(p *Parser) {
+		p.inputValues = values
 	}
 }
 
@@ -74,6 +84,33 @@ func OptionWithWorkspaceName(workspaceName string) Option {
 	}
 }
 
+// OptionWithModuleCacheDir sets the directory of the shared module cache. Remote modules are downloaded
+// into this directory so that they can be reused by other projects and later runs.
+func OptionWithModuleCacheDir(dir string) Option {
+	return func(p *Parser) {
+		p.moduleCacheDir = dir
+	}
+}
+
+// OptionWithEnv sets the environment variables of the project. These are used instead of the process
+// environment variables with the same name, e.g. for TF_VAR_ input variables and registry credentials,
+// so that projects parsed in parallel can have different environments.
+func OptionWithEnv(env map[string]string) Option {
+	return func(p *Parser) {
+		p.env = env
+	}
+}
+
+// OptionWithDataSourceEvaluators adds DataSourceEvaluators to the Parser, replacing any of the
+// DefaultDataSourceEvaluators for the same data source types.
+func OptionWithDataSourceEvaluators(evaluators map[string]DataSourceEvaluator) Option {
+	return func(p *Parser) {
+		for dataType, evaluator := range evaluators {
+			p.dataSourceEvaluators[dataType] = evaluator
+		}
+	}
+}
+
 func OptionWithBlockBuilder(blockBuilder BlockBuilder) Option {
 	return func(p *Parser) {
 		p.blockBuilder = blockBuilder
@@ -82,14 +119,17 @@ func OptionWithBlockBuilder(blockBuilder BlockBuilder) Option {
 
 // Parser is a tool for parsing terraform templates at a given file system location.
 type Parser struct {
-	initialPath     string
-	defaultVarFiles []string
-	tfvarsPaths     []string
-	inputVars       map[string]cty.Value
-	stopOnHCLError  bool
-	workspaceName   string
-	moduleLoader    *modules.ModuleLoader
-	blockBuilder    BlockBuilder
+	initialPath          string
+	defaultVarFiles      []string
+	tfvarsPaths          []string
+	inputVars            map[string]string
+	inputValues          map[string]cty.Value
+	stopOnHCLError       bool
+	workspaceName        string
+	moduleCacheDir       string
+	env                  map[string]string
+	blockBuilder         BlockBuilder
+	dataSourceEvaluators map[string]DataSourceEvaluator
 }
 
 // New creates a new Parser with the provided options, it inits the workspace as under the default name
@@ -98,10 +138,14 @@ func New(initialPath string, options ...Option) *Parser {
 	p := &Parser{
 		initialPath:   initialPath,
 		workspaceName: "default",
-		moduleLoader:  modules.NewModuleLoader(initialPath),
 		blockBuilder:  BlockBuilder{SetAttributes: []SetAttributesFunc{SetUUIDAttributes}},
 	}
 
+	p.dataSourceEvaluators = make(map[string]DataSourceEvaluator, len(DefaultDataSourceEvaluators))
+	for dataType, evaluator := range DefaultDataSourceEvaluators {
+		p.dataSourceEvaluators[dataType] = evaluator
+	}
+
 	var defaultVarFiles []string
 
 	defaultTfFile := path.Join(initialPath, "terraform.tfvars")
@@ -157,13 +201,23 @@ func (p *Parser) ParseDirectory() (*Module, error) {
 	}
 
 	log.Debug("Loading TFVars...")
-	inputVars, err := p.loadVars(p.tfvarsPaths)
+	inputVars, err := p.loadVars(blocks, p.tfvarsPaths)
 	if err != nil {
 		return nil, err
 	}
 
+	missing := missingVariables(blocks, inputVars)
+	if len(missing) > 0 {
+		log.Debugf("Required variables not set: %s", strings.Join(missing, ", "))
+	}
+
 	// load the modules. This downloads any remote modules to the local file system
-	modulesManifest, err := p.moduleLoader.Load()
+	var sharedCache *modules.SharedCache
+	if p.moduleCacheDir != "" {
+		sharedCache = modules.NewSharedCache(p.moduleCacheDir)
+	}
+
+	modulesManifest, err := modules.NewModuleLoader(p.initialPath, sharedCache, p.env).Load()
 	if err != nil {
 		return nil, fmt.Errorf("Error loading Terraform modules: %s", err)
 	}
@@ -189,6 +243,7 @@ func (p *Parser) ParseDirectory() (*Module, error) {
 		nil,
 		p.workspaceName,
 		p.blockBuilder,
+		p.dataSourceEvaluators,
 	)
 
 	root, err := evaluator.Run()
@@ -196,6 +251,8 @@ func (p *Parser) ParseDirectory() (*Module, error) {
 		return nil, err
 	}
 
+	root.MissingVariables = missing
+
 	return root, nil
 }
 
@@ -228,8 +285,28 @@ func (p *Parser) parseDirectoryFiles(files []*hcl.File) (Blocks, error) {
 	return blocks, nil
 }
 
-func (p *Parser) loadVars(filenames []string) (map[string]cty.Value, error) {
+// loadVars loads the input variables for the root module using the same precedence as Terraform,
+// from lowest to highest:
+//  1. input values set with OptionWithInputValues, e.g. Terragrunt inputs
+//  2. TF_VAR_ environment variables
+//  3. terraform.tfvars and terraform.tfvars.json files
+//  4. *.auto.tfvars and *.auto.tfvars.json files in lexical order
+//  5. the given tfvars files in the order they are given
+//  6. the cmd line input vars
+func (p *Parser) loadVars(blocks Blocks, filenames []string) (map[string]cty.Value, error) {
 	combinedVars := make(map[string]cty.Value)
+	types := variableTypes(blocks)
+
+	for k, v := range p.inputValues {
+		ty, ok := types[k]
+		if !ok {
+			continue
+		}
+
+		combinedVars[k] = convertVariableValue(k, v, ty)
+	}
+
+	p.combineRawVars(envInputVars(p.env), types, combinedVars)
 
 	for _, name := range p.defaultVarFiles {
 		err := loadAndCombineVars(name, combinedVars)
@@ -246,13 +323,30 @@ func (p *Parser) loadVars(filenames []string) (map[string]cty.Value, error) {
 		}
 	}
 
-	for k, v := range p.inputVars {
-		combinedVars[k] = v
-	}
+	p.combineRawVars(p.inputVars, types, combinedVars)
 
 	return combinedVars, nil
 }
 
+// combineRawVars parses the raw input variable values using the variable type constraints and adds them
+// to combinedVars. Values for variables that aren't declared in the root module are ignored.
+func (p *Parser) combineRawVars(rawVars map[string]string, types map[string]cty.Type, combinedVars map[string]cty.Value) {
+	for k, raw := range rawVars {
+		ty, ok := types[k]
+		if !ok {
+			continue
+		}
+
+		v, err := parseVariableValue(k, raw, ty)
+		if err != nil {
+			log.Warnf("skipping input variable: %s", err)
+			continue
+		}
+
+		combinedVars[k] = v
+	}
+}
+
 func loadAndCombineVars(filename string, combinedVars map[string]cty.Value) error {
 	vars, err := loadVarFile(filename)
 	if err != nil {
@@ -220,6 +220,369 @@ output "mod_result" {
 	assert.Equal(t, "ok", childValAttr.Value().AsString())
 }
 
+func Test_ModuleInstanceAddressing(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"main/main.tf": `
+module "svc" {
+	source   = "../svc"
+	for_each = toset(["a", "b"])
+	name     = each.key
+}
+
+output "a_name" {
+	value = module.svc["a"].name
+}
+
+output "b_disks" {
+	value = module.svc["b"].disks
+}
+`,
+		"svc/main.tf": `
+variable "name" {}
+
+resource "aws_instance" "web" {
+	instance_type = "t3.micro"
+	tags = {
+		Name = var.name
+	}
+}
+
+module "disk" {
+	source = "../disk"
+	count  = 2
+	size   = count.index + 10
+}
+
+output "name" {
+	value = var.name
+}
+
+output "disks" {
+	value = [module.disk[0].size, module.disk[1].size]
+}
+`,
+		"disk/main.tf": `
+variable "size" {}
+
+resource "aws_ebs_volume" "disk" {
+	size = var.size
+}
+
+output "size" {
+	value = var.size
+}
+`,
+	}
+
+	for name, contents := range files {
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		require.NoError(t, os.WriteFile(p, []byte(contents), os.ModePerm))
+	}
+
+	parser := New(filepath.Join(dir, "main"), OptionStopOnHCLError())
+	rootModule, err := parser.ParseDirectory()
+	require.NoError(t, err)
+
+	var resources []string
+	var moduleNames []string
+	var collect func(m *Module)
+	collect = func(m *Module) {
+		for _, b := range m.Blocks.OfType("resource") {
+			resources = append(resources, b.FullName())
+		}
+		for _, child := range m.Modules {
+			moduleNames = append(moduleNames, child.Name)
+			collect(child)
+		}
+	}
+	collect(rootModule)
+
+	assert.ElementsMatch(t, []string{
+		`module.svc["a"]`,
+		`module.svc["a"].module.disk[0]`,
+		`module.svc["a"].module.disk[1]`,
+		`module.svc["b"]`,
+		`module.svc["b"].module.disk[0]`,
+		`module.svc["b"].module.disk[1]`,
+	}, moduleNames)
+
+	assert.ElementsMatch(t, []string{
+		`module.svc["a"].aws_instance.web`,
+		`module.svc["a"].module.disk[0].aws_ebs_volume.disk`,
+		`module.svc["a"].module.disk[1].aws_ebs_volume.disk`,
+		`module.svc["b"].aws_instance.web`,
+		`module.svc["b"].module.disk[0].aws_ebs_volume.disk`,
+		`module.svc["b"].module.disk[1].aws_ebs_volume.disk`,
+	}, resources)
+
+	outputs := make(map[string]cty.Value)
+	for _, b := range rootModule.Blocks.OfType("output") {
+		outputs[b.Label()] = b.GetAttribute("value").Value()
+	}
+
+	assert.Equal(t, "a", outputs["a_name"].AsString())
+
+	disks := outputs["b_disks"].AsValueSlice()
+	require.Len(t, disks, 2)
+	assert.True(t, disks[0].Equals(cty.NumberIntVal(10)).True())
+	assert.True(t, disks[1].Equals(cty.NumberIntVal(11)).True())
+}
+
+func Test_InputVarPrecedence(t *testing.T) {
+	path := createTestFile("main.tf", `
+variable "from_input" {}
+
+variable "from_env" {}
+
+variable "from_tfvars" {}
+
+variable "from_cli" {}
+
+output "from_input" {
+	value = var.from_input
+}
+
+output "from_env" {
+	value = var.from_env
+}
+
+output "from_tfvars" {
+	value = var.from_tfvars
+}
+
+output "from_cli" {
+	value = var.from_cli
+}
+`)
+	dir := filepath.Dir(path)
+
+	err := os.WriteFile(filepath.Join(dir, "terraform.tfvars"), []byte(`
+from_tfvars = "tfvars"
+from_cli    = "tfvars"
+`), os.ModePerm)
+	require.NoError(t, err)
+
+	t.Setenv("TF_VAR_from_env", "env")
+	t.Setenv("TF_VAR_from_tfvars", "env")
+	t.Setenv("TF_VAR_from_cli", "env")
+
+	parser := New(
+		dir,
+		OptionStopOnHCLError(),
+		OptionWithInputVars([]string{"from_cli=cli"}),
+		OptionWithInputValues(map[string]cty.Value{
+			"from_input": cty.StringVal("input"),
+			"from_env":   cty.StringVal("input"),
+		}),
+	)
+	module, err := parser.ParseDirectory()
+	require.NoError(t, err)
+
+	outputs := make(map[string]string)
+	for _, b := range module.Blocks.OfType("output") {
+		outputs[b.Label()] = b.GetAttribute("value").Value().AsString()
+	}
+
+	assert.Equal(t, map[string]string{
+		"from_input":  "input",
+		"from_env":    "env",
+		"from_tfvars": "tfvars",
+		"from_cli":    "cli",
+	}, outputs)
+	assert.Empty(t, module.MissingVariables)
+}
+
+func Test_InputVarsFromProjectEnv(t *testing.T) {
+	path := createTestFile("main.tf", `
+variable "region" {}
+
+variable "instance_type" {}
+
+output "region" {
+	value = var.region
+}
+
+output "instance_type" {
+	value = var.instance_type
+}
+`)
+
+	t.Setenv("TF_VAR_region", "us-east-1")
+	t.Setenv("TF_VAR_instance_type", "t3.micro")
+
+	parser := New(filepath.Dir(path), OptionStopOnHCLError(), OptionWithEnv(map[string]string{
+		"TF_VAR_instance_type": "m5.large",
+	}))
+	module, err := parser.ParseDirectory()
+	require.NoError(t, err)
+
+	outputs := make(map[string]string)
+	for _, b := range module.Blocks.OfType("output") {
+		outputs[b.Label()] = b.GetAttribute("value").Value().AsString()
+	}
+
+	assert.Equal(t, map[string]string{
+		"region":        "us-east-1",
+		"instance_type": "m5.large",
+	}, outputs)
+
+	assert.Equal(t, "t3.micro", os.Getenv("TF_VAR_instance_type"))
+}
+
+func Test_InputVarTypeConversion(t *testing.T) {
+	path := createTestFile("main.tf", `
+variable "instance_count" {
+	type = number
+}
+
+variable "zones" {
+	type = list(string)
+}
+
+variable "tags" {
+	type = map(string)
+}
+
+variable "size" {
+	type    = number
+	default = "10"
+}
+
+output "instance_count" {
+	value = var.instance_count
+}
+
+output "zones" {
+	value = var.zones
+}
+
+output "tags" {
+	value = var.tags
+}
+
+output "size" {
+	value = var.size
+}
+`)
+
+	t.Setenv("TF_VAR_zones", `["us-east-1a", "us-east-1b"]`)
+
+	parser := New(filepath.Dir(path), OptionStopOnHCLError(), OptionWithInputVars([]string{
+		"instance_count=3",
+		`tags={Name="web", Env="prod"}`,
+	}))
+	module, err := parser.ParseDirectory()
+	require.NoError(t, err)
+
+	outputs := make(map[string]cty.Value)
+	for _, b := range module.Blocks.OfType("output") {
+		outputs[b.Label()] = b.GetAttribute("value").Value()
+	}
+
+	assert.True(t, outputs["instance_count"].Equals(cty.NumberIntVal(3)).True())
+	assert.Equal(t, cty.ListVal([]cty.Value{cty.StringVal("us-east-1a"), cty.StringVal("us-east-1b")}), outputs["zones"])
+	assert.Equal(t, cty.MapVal(map[string]cty.Value{"Name": cty.StringVal("web"), "Env": cty.StringVal("prod")}), outputs["tags"])
+	assert.True(t, outputs["size"].Equals(cty.NumberIntVal(10)).True())
+}
+
+func Test_MissingVariables(t *testing.T) {
+	path := createTestFile("main.tf", `
+variable "region" {}
+
+variable "instance_type" {
+	type = string
+}
+
+variable "ami" {
+	default = "ami-123"
+}
+
+variable "set_by_cli" {}
+`)
+
+	parser := New(filepath.Dir(path), OptionStopOnHCLError(), OptionWithInputVars([]string{"set_by_cli=foo"}))
+	module, err := parser.ParseDirectory()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"instance_type", "region"}, module.MissingVariables)
+}
+
+func Test_UnknownAttributes(t *testing.T) {
+	path := createTestFile("main.tf", `
+variable "instance_type" {}
+
+variable "volume_size" {}
+
+locals {
+	volume_size = var.volume_size * 2
+}
+
+resource "aws_security_group" "web" {
+	name = "web"
+}
+
+resource "aws_instance" "web" {
+	ami                    = "ami-123"
+	instance_type          = var.instance_type
+	vpc_security_group_ids = [aws_security_group.web.id]
+	subnet_id              = aws_subnet.missing.id
+	monitoring             = try(var.monitoring, false)
+
+	root_block_device {
+		volume_size = local.volume_size
+	}
+}
+`)
+
+	parser := New(filepath.Dir(path), OptionStopOnHCLError())
+	module, err := parser.ParseDirectory()
+	require.NoError(t, err)
+
+	unknown := make(map[string][]string)
+	for _, b := range module.Blocks.OfType("resource") {
+		unknown[b.FullName()] = b.UnknownAttributes()
+	}
+
+	assert.Equal(t, []string{"instance_type", "root_block_device.volume_size"}, unknown["aws_instance.web"])
+	assert.Empty(t, unknown["aws_security_group.web"])
+}
+
+func Test_MovedBlocks(t *testing.T) {
+	path := createTestFile("main.tf", `
+resource "aws_instance" "app" {
+	instance_type = "t3.micro"
+}
+
+moved {
+	from = aws_instance.web
+	to   = aws_instance.app
+}
+
+moved {
+	from = aws_instance.old["a"]
+	to   = module.web.aws_instance.web[0]
+}
+
+moved {
+	from = "not an address"
+	to   = aws_instance.app
+}
+`)
+
+	parser := New(filepath.Dir(path), OptionStopOnHCLError())
+	module, err := parser.ParseDirectory()
+	require.NoError(t, err)
+
+	assert.Len(t, module.Blocks.OfType("resource"), 1)
+	assert.Equal(t, []Moved{
+		{From: "aws_instance.web", To: "aws_instance.app"},
+		{From: `aws_instance.old["a"]`, To: "module.web.aws_instance.web[0]"},
+	}, module.MovedBlocks())
+}
+
 func createTestFile(filename, contents string) string {
 	dir, err := ioutil.TempDir(os.TempDir(), "infracost")
 	if err != nil {
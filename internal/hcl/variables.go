@@ -0,0 +1,130 @@
+package hcl
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// envVarPrefix is the prefix of the environment variables that Terraform reads input variables from.
+const envVarPrefix = "TF_VAR_"
+
+// envInputVars returns the raw values of the input variables set with TF_VAR_ environment variables.
+// Variables set in the project env override those in the process environment.
+func envInputVars(env map[string]string) map[string]string {
+	vars := make(map[string]string)
+
+	environ := os.Environ()
+	for k, v := range env {
+		environ = append(environ, k+"="+v)
+	}
+
+	for _, e := range environ {
+		if !strings.HasPrefix(e, envVarPrefix) {
+			continue
+		}
+
+		pieces := strings.SplitN(strings.TrimPrefix(e, envVarPrefix), "=", 2)
+		if len(pieces) != 2 || pieces[0] == "" {
+			continue
+		}
+
+		vars[pieces[0]] = pieces[1]
+	}
+
+	return vars
+}
+
+// variableType returns the type constraint of a variable block. Variables without
+// a type constraint, or with one that can't be parsed, accept any type.
+func variableType(b *Block) cty.Type {
+	attr := b.GetAttribute("type")
+	if attr == nil {
+		return cty.DynamicPseudoType
+	}
+
+	ty, diags := typeexpr.TypeConstraint(attr.HCLAttr.Expr)
+	if diags.HasErrors() {
+		log.Debugf("could not parse type constraint for variable %s: %s", b.Label(), diags.Error())
+		return cty.DynamicPseudoType
+	}
+
+	return ty
+}
+
+// parseVariableValue parses a raw value set on the command line or in a TF_VAR_ environment variable.
+// Following Terraform, values of variables with a primitive type, or no type constraint, are used
+// as literal strings and all other values are parsed as HCL expressions, e.g. '["a", "b"]'.
+func parseVariableValue(name string, raw string, ty cty.Type) (cty.Value, error) {
+	if ty == cty.DynamicPseudoType || ty.IsPrimitiveType() {
+		return cty.StringVal(raw), nil
+	}
+
+	expr, diags := hclsyntax.ParseExpression([]byte(raw), fmt.Sprintf("<value for var.%s>", name), hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return cty.NilVal, fmt.Errorf("invalid value for variable %s: %s", name, diags.Error())
+	}
+
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() {
+		return cty.NilVal, fmt.Errorf("invalid value for variable %s: %s", name, diags.Error())
+	}
+
+	return val, nil
+}
+
+// convertVariableValue converts the value of a variable to its type constraint, e.g. the string
+// "2" to the number 2. If the value can't be converted it is returned unchanged.
+func convertVariableValue(name string, val cty.Value, ty cty.Type) cty.Value {
+	if ty == cty.DynamicPseudoType || val == cty.NilVal {
+		return val
+	}
+
+	converted, err := convert.Convert(val, ty)
+	if err != nil {
+		log.Debugf("could not convert value of variable %s to %s: %s", name, ty.FriendlyName(), err)
+		return val
+	}
+
+	return converted
+}
+
+// variableTypes returns the type constraints of the variable blocks keyed by variable name.
+func variableTypes(blocks Blocks) map[string]cty.Type {
+	types := make(map[string]cty.Type)
+
+	for _, b := range blocks.OfType("variable") {
+		types[b.Label()] = variableType(b)
+	}
+
+	return types
+}
+
+// missingVariables returns the sorted names of the variables that are required, since they
+// have no default, but were not set by any of the input variables.
+func missingVariables(blocks Blocks, inputVars map[string]cty.Value) []string {
+	var missing []string
+
+	for _, b := range blocks.OfType("variable") {
+		if b.Label() == "" || b.GetAttribute("default") != nil {
+			continue
+		}
+
+		if _, ok := inputVars[b.Label()]; ok {
+			continue
+		}
+
+		missing = append(missing, b.Label())
+	}
+
+	sort.Strings(missing)
+
+	return missing
+}
@@ -15,17 +15,27 @@ import (
 )
 
 var minOutputVersion = "0.2"
-var maxOutputVersion = "0.2"
+var maxOutputVersion = "0.3"
 
 type ReportInput struct {
 	Metadata map[string]string
 	Root     Root
 }
 
+// Load parses an Infracost JSON file. Resources in files from older versions of the output schema
+// are given IDs so they can be used like the current version.
 func Load(data []byte) (Root, error) {
 	var out Root
 	err := json.Unmarshal(data, &out)
-	return out, err
+	if err != nil {
+		return out, err
+	}
+
+	for i := range out.Projects {
+		out.Projects[i].setResourceIDs()
+	}
+
+	return out, nil
 }
 
 func LoadPaths(paths []string) ([]ReportInput, error) {
@@ -96,6 +106,7 @@ func Combine(inputs []ReportInput) (Root, error) {
 
 	projects := make([]Project, 0)
 	summaries := make([]*Summary, 0, len(inputs))
+	exchangeRates := make([]ExchangeRate, 0)
 	currency := ""
 
 	for _, input := range inputs {
@@ -109,6 +120,12 @@ func Combine(inputs []ReportInput) (Root, error) {
 
 		summaries = append(summaries, input.Root.Summary)
 
+		for _, rate := range input.Root.ExchangeRates {
+			if !containsExchangeRate(exchangeRates, rate) {
+				exchangeRates = append(exchangeRates, rate)
+			}
+		}
+
 		if input.Root.TotalHourlyCost != nil {
 			if totalHourlyCost == nil {
 				totalHourlyCost = decimalPtr(decimal.Zero)
@@ -166,9 +183,23 @@ func Combine(inputs []ReportInput) (Root, error) {
 	combined.TimeGenerated = time.Now()
 	combined.Summary = MergeSummaries(summaries)
 
+	if len(exchangeRates) > 0 {
+		combined.ExchangeRates = exchangeRates
+	}
+
 	return combined, nil
 }
 
+func containsExchangeRate(rates []ExchangeRate, rate ExchangeRate) bool {
+	for _, r := range rates {
+		if r.From == rate.From && r.To == rate.To && r.Rate.Equal(rate.Rate) && r.Date == rate.Date {
+			return true
+		}
+	}
+
+	return false
+}
+
 func checkCurrency(inputCurrency, fileCurrency string) (string, error) {
 	if fileCurrency == "" {
 		fileCurrency = "USD" // default to USD
@@ -0,0 +1,155 @@
+package output
+
+import (
+	"github.com/shopspring/decimal"
+
+	"github.com/infracost/infracost/internal/schema"
+)
+
+// Compare returns the diff between two Infracost JSON outputs, e.g. from last week's main branch and
+// today's. Projects are matched by their name and metadata path, and the current breakdowns of the
+// matching projects are diffed the same way as a Terraform plan, so the past breakdown of the output
+// comes from the from output and the breakdown from the to output. Projects that are only in one of
+// the outputs are shown as added or removed.
+func Compare(from, to Root) (Root, error) {
+	currency, err := checkCurrency("", from.Currency)
+	if err != nil {
+		return Root{}, err
+	}
+
+	currency, err = checkCurrency(currency, to.Currency)
+	if err != nil {
+		return Root{}, err
+	}
+
+	fromProjects := make(map[string]Project, len(from.Projects))
+	for _, p := range from.Projects {
+		fromProjects[compareProjectKey(p)] = p
+	}
+
+	projects := make([]*schema.Project, 0, len(to.Projects))
+	summaries := make([]*Summary, 0, len(to.Projects))
+
+	seen := make(map[string]bool, len(to.Projects))
+
+	for _, p := range to.Projects {
+		key := compareProjectKey(p)
+		seen[key] = true
+
+		project := schema.NewProject(p.Name, p.Metadata)
+		project.Resources = schemaResources(p.Breakdown)
+
+		if fromProject, ok := fromProjects[key]; ok {
+			project.PastResources = schemaResources(fromProject.Breakdown)
+		}
+
+		projects = append(projects, project)
+		summaries = append(summaries, p.Summary)
+	}
+
+	for _, p := range from.Projects {
+		if seen[compareProjectKey(p)] {
+			continue
+		}
+
+		project := schema.NewProject(p.Name, p.Metadata)
+		project.PastResources = schemaResources(p.Breakdown)
+
+		// Removed projects have no current resources, so they get an empty summary instead of
+		// the one from the from output, which would otherwise be counted as current.
+		projects = append(projects, project)
+		summaries = append(summaries, &Summary{})
+	}
+
+	for _, project := range projects {
+		project.CalculateDiff()
+	}
+
+	out, err := ToOutputFormat(projects)
+	if err != nil {
+		return Root{}, err
+	}
+
+	// The resources built from the JSON don't include the skipped resources, so the summaries are
+	// taken from the outputs instead of being rebuilt.
+	for i := range out.Projects {
+		out.Projects[i].Summary = summaries[i]
+		out.Projects[i].fullSummary = nil
+	}
+
+	out.Currency = currency
+	out.Summary = MergeSummaries(summaries)
+	out.FullSummary = nil
+
+	return out, nil
+}
+
+func compareProjectKey(p Project) string {
+	path := ""
+	if p.Metadata != nil {
+		path = p.Metadata.Path
+	}
+
+	return p.Name + "\x00" + path
+}
+
+// schemaResources converts the resources of a breakdown back to schema resources so they can be diffed.
+// The prices and quantities in the output already have the unit multiplier applied so it is set to 1.
+func schemaResources(b *Breakdown) []*schema.Resource {
+	if b == nil {
+		return nil
+	}
+
+	resources := make([]*schema.Resource, 0, len(b.Resources))
+	for _, r := range b.Resources {
+		resources = append(resources, schemaResource(r))
+	}
+
+	return resources
+}
+
+func schemaResource(r Resource) *schema.Resource {
+	costComponents := make([]*schema.CostComponent, 0, len(r.CostComponents))
+	for _, c := range r.CostComponents {
+		costComponent := &schema.CostComponent{
+			Name:                c.Name,
+			Unit:                c.Unit,
+			UnitMultiplier:      decimal.NewFromInt(1),
+			ProductFilter:       c.ProductFilter,
+			PriceFilter:         c.PriceFilter,
+			HourlyQuantity:      c.HourlyQuantity,
+			MonthlyQuantity:     c.MonthlyQuantity,
+			MonthlyDiscountPerc: c.MonthlyDiscountPerc,
+			HourlyCost:          c.HourlyCost,
+			MonthlyCost:         c.MonthlyCost,
+		}
+		costComponent.SetPrice(c.Price)
+		costComponent.SetPriceHash(c.PriceHash)
+		costComponent.SetMissingPrice(c.IsMissingPrice)
+		if c.IsCustomPrice {
+			price := c.Price
+			costComponent.SetCustomPrice(&price)
+		}
+
+		costComponents = append(costComponents, costComponent)
+	}
+
+	subResources := make([]*schema.Resource, 0, len(r.SubResources))
+	for _, s := range r.SubResources {
+		subResources = append(subResources, schemaResource(s))
+	}
+
+	return &schema.Resource{
+		Name:              r.Name,
+		MovedFrom:         r.MovedFrom,
+		ResourceType:      ResourceType(r.Name),
+		Metadata:          r.Metadata,
+		AppliedUsage:      r.Usage,
+		Tags:              r.Tags,
+		UnknownAttributes: r.UnknownAttributes,
+		HourlyCost:        r.HourlyCost,
+		MonthlyCost:       r.MonthlyCost,
+		CostComponents:    costComponents,
+		SubResources:      subResources,
+	}
+}
@@ -0,0 +1,88 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/infracost/infracost/internal/schema"
+)
+
+func compareTestProject(name, path string, price float64) Project {
+	totalResources := 1
+
+	return Project{
+		Name:     name,
+		Metadata: &schema.ProjectMetadata{Path: path},
+		Summary:  &Summary{TotalResources: &totalResources},
+		Breakdown: &Breakdown{
+			Resources: []Resource{
+				{
+					Name:        "aws_instance.web",
+					HourlyCost:  decimalPtr(decimal.NewFromFloat(price)),
+					MonthlyCost: decimalPtr(decimal.NewFromFloat(price * 730)),
+					CostComponents: []CostComponent{
+						{Name: "Instance usage", Unit: "hours", Price: decimal.NewFromFloat(price), HourlyQuantity: decimalPtr(decimal.NewFromFloat(1)), MonthlyQuantity: decimalPtr(decimal.NewFromFloat(730)), HourlyCost: decimalPtr(decimal.NewFromFloat(price)), MonthlyCost: decimalPtr(decimal.NewFromFloat(price * 730))},
+					},
+				},
+			},
+			TotalHourlyCost:  decimalPtr(decimal.NewFromFloat(price)),
+			TotalMonthlyCost: decimalPtr(decimal.NewFromFloat(price * 730)),
+		},
+	}
+}
+
+func TestCompare(t *testing.T) {
+	from := Root{
+		Currency: "USD",
+		Projects: []Project{
+			compareTestProject("infra", "infra/dev", 0.1),
+			compareTestProject("infra", "infra/prod", 0.2),
+			compareTestProject("old", "old", 0.3),
+		},
+	}
+	to := Root{
+		Currency: "USD",
+		Projects: []Project{
+			compareTestProject("infra", "infra/dev", 0.1),
+			compareTestProject("infra", "infra/prod", 0.4),
+			compareTestProject("new", "new", 0.5),
+		},
+	}
+
+	out, err := Compare(from, to)
+	require.NoError(t, err)
+
+	require.Len(t, out.Projects, 4)
+
+	assert.Equal(t, "infra/dev", out.Projects[0].Metadata.Path)
+	assert.Empty(t, out.Projects[0].Diff.Resources)
+
+	assert.Equal(t, "infra/prod", out.Projects[1].Metadata.Path)
+	assert.Equal(t, "146", out.Projects[1].PastBreakdown.TotalMonthlyCost.String())
+	assert.Equal(t, "292", out.Projects[1].Breakdown.TotalMonthlyCost.String())
+	require.Len(t, out.Projects[1].Diff.Resources, 1)
+	assert.Equal(t, "0.2", out.Projects[1].Diff.Resources[0].CostComponents[0].Price.String())
+	assert.Equal(t, "146", out.Projects[1].Diff.Resources[0].MonthlyCost.String())
+
+	assert.Equal(t, "new", out.Projects[2].Name)
+	assert.Equal(t, "365", out.Projects[2].Diff.TotalMonthlyCost.String())
+
+	assert.Equal(t, "old", out.Projects[3].Name)
+	assert.Equal(t, "-219", out.Projects[3].Diff.TotalMonthlyCost.String())
+	assert.Empty(t, out.Projects[3].Breakdown.Resources)
+	assert.Nil(t, out.Projects[3].Summary.TotalResources)
+
+	assert.Equal(t, 3, *out.Summary.TotalResources)
+
+	assert.Equal(t, "438", out.PastTotalMonthlyCost.String())
+	assert.Equal(t, "730", out.TotalMonthlyCost.String())
+	assert.Equal(t, "292", out.DiffTotalMonthlyCost.String())
+}
+
+func TestCompareCurrencyMismatch(t *testing.T) {
+	_, err := Compare(Root{Currency: "USD"}, Root{Currency: "EUR"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,272 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+)
+
+// costComponentRow is a cost component of a resource with its current and past values. It is a row of the
+// CSV output and of the cost components sheet of the XLSX output.
+type costComponentRow struct {
+	projectIndex        int
+	Project             string
+	Resource            string
+	ResourceType        string
+	Tags                map[string]string
+	CostComponent       string
+	Unit                string
+	Price               decimal.Decimal
+	MonthlyQuantity     *decimal.Decimal
+	HourlyCost          *decimal.Decimal
+	MonthlyCost         *decimal.Decimal
+	PastMonthlyQuantity *decimal.Decimal
+	PastHourlyCost      *decimal.Decimal
+	PastMonthlyCost     *decimal.Decimal
+}
+
+func (r costComponentRow) diffMonthlyQuantity() *decimal.Decimal {
+	return diffDecimal(r.PastMonthlyQuantity, r.MonthlyQuantity)
+}
+
+func (r costComponentRow) diffHourlyCost() *decimal.Decimal {
+	return diffDecimal(r.PastHourlyCost, r.HourlyCost)
+}
+
+func (r costComponentRow) diffMonthlyCost() *decimal.Decimal {
+	return diffDecimal(r.PastMonthlyCost, r.MonthlyCost)
+}
+
+// diffDecimal returns the change from past to current, treating a missing value as zero. It returns nil
+// if both values are missing, e.g. for usage-based costs that have no usage.
+func diffDecimal(past, current *decimal.Decimal) *decimal.Decimal {
+	if past == nil && current == nil {
+		return nil
+	}
+
+	d := decimal.Zero
+	if current != nil {
+		d = *current
+	}
+
+	if past != nil {
+		d = d.Sub(*past)
+	}
+
+	return &d
+}
+
+// costComponentEntry is a cost component found when flattening a breakdown, along with the resource it
+// belongs to. Subresource cost components are named after the path of subresources, e.g. root_block_device >
+// Storage (general purpose SSD, gp2), and use the tags of their top level resource.
+type costComponentEntry struct {
+	key           string
+	resource      string
+	tags          map[string]string
+	costComponent string
+	component     CostComponent
+}
+
+func flattenBreakdown(b *Breakdown) []costComponentEntry {
+	if b == nil {
+		return nil
+	}
+
+	var entries []costComponentEntry
+	for _, r := range b.Resources {
+		entries = append(entries, flattenResource(r.Name, r.Tags, "", r)...)
+	}
+
+	return entries
+}
+
+func flattenResource(address string, tags map[string]string, prefix string, r Resource) []costComponentEntry {
+	entries := make([]costComponentEntry, 0, len(r.CostComponents))
+
+	for _, c := range r.CostComponents {
+		name := prefix + c.Name
+		entries = append(entries, costComponentEntry{
+			key:           address + "\x00" + name,
+			resource:      address,
+			tags:          tags,
+			costComponent: name,
+			component:     c,
+		})
+	}
+
+	for _, s := range r.SubResources {
+		entries = append(entries, flattenResource(address, tags, prefix+s.Name+" > ", s)...)
+	}
+
+	return entries
+}
+
+// costComponentRows returns a row for each cost component in the projects. Cost components that are in the
+// past breakdown but not the current breakdown, e.g. from removed resources, are added after the current ones
+// of the project.
+func costComponentRows(out Root, opts Options) []costComponentRow {
+	var rows []costComponentRow
+
+	for i, p := range out.Projects {
+		label := p.Label(opts.DashboardEnabled)
+
+		current := flattenBreakdown(p.Breakdown)
+		past := flattenBreakdown(p.PastBreakdown)
+
+		pastByKey := make(map[string]costComponentEntry, len(past))
+		for _, e := range past {
+			pastByKey[e.key] = e
+		}
+
+		seen := make(map[string]bool, len(current))
+
+		for _, e := range current {
+			seen[e.key] = true
+
+			row := newCostComponentRow(i, label, e)
+			row.MonthlyQuantity = e.component.MonthlyQuantity
+			row.HourlyCost = e.component.HourlyCost
+			row.MonthlyCost = e.component.MonthlyCost
+
+			if pastEntry, ok := pastByKey[e.key]; ok {
+				row.PastMonthlyQuantity = pastEntry.component.MonthlyQuantity
+				row.PastHourlyCost = pastEntry.component.HourlyCost
+				row.PastMonthlyCost = pastEntry.component.MonthlyCost
+			}
+
+			rows = append(rows, row)
+		}
+
+		for _, e := range past {
+			if seen[e.key] {
+				continue
+			}
+
+			row := newCostComponentRow(i, label, e)
+			row.PastMonthlyQuantity = e.component.MonthlyQuantity
+			row.PastHourlyCost = e.component.HourlyCost
+			row.PastMonthlyCost = e.component.MonthlyCost
+
+			rows = append(rows, row)
+		}
+	}
+
+	return rows
+}
+
+func newCostComponentRow(projectIndex int, project string, e costComponentEntry) costComponentRow {
+	return costComponentRow{
+		projectIndex:  projectIndex,
+		Project:       project,
+		Resource:      e.resource,
+		ResourceType:  ResourceType(e.resource),
+		Tags:          e.tags,
+		CostComponent: e.costComponent,
+		Unit:          e.component.Unit,
+		Price:         e.component.Price,
+	}
+}
+
+// costComponentHeader returns the column titles of the cost component rows.
+func costComponentHeader(currency string) []string {
+	return []string{
+		"Project",
+		"Resource",
+		"Resource type",
+		"Tags",
+		"Cost component",
+		"Unit",
+		formatTitleWithCurrency("Price", currency),
+		"Monthly quantity",
+		formatTitleWithCurrency("Hourly cost", currency),
+		formatTitleWithCurrency("Monthly cost", currency),
+		"Past monthly quantity",
+		formatTitleWithCurrency("Past hourly cost", currency),
+		formatTitleWithCurrency("Past monthly cost", currency),
+		"Diff monthly quantity",
+		formatTitleWithCurrency("Diff hourly cost", currency),
+		formatTitleWithCurrency("Diff monthly cost", currency),
+	}
+}
+
+// textColumns is the number of columns at the start of the cost component rows that hold text, the rest
+// hold numbers.
+const textColumns = 6
+
+func (r costComponentRow) values() []string {
+	price := r.Price
+	return []string{
+		r.Project,
+		r.Resource,
+		r.ResourceType,
+		formatTags(r.Tags),
+		r.CostComponent,
+		r.Unit,
+		formatDecimalValue(&price),
+		formatDecimalValue(r.MonthlyQuantity),
+		formatDecimalValue(r.HourlyCost),
+		formatDecimalValue(r.MonthlyCost),
+		formatDecimalValue(r.PastMonthlyQuantity),
+		formatDecimalValue(r.PastHourlyCost),
+		formatDecimalValue(r.PastMonthlyCost),
+		formatDecimalValue(r.diffMonthlyQuantity()),
+		formatDecimalValue(r.diffHourlyCost()),
+		formatDecimalValue(r.diffMonthlyCost()),
+	}
+}
+
+// formatDecimalValue returns the value without any currency or thousands separators so that spreadsheets
+// can read it as a number. Missing values are returned as an empty string.
+func formatDecimalValue(d *decimal.Decimal) string {
+	if d == nil {
+		return ""
+	}
+
+	return d.String()
+}
+
+// formatTags returns the tags sorted by key in the format key1=value1; key2=value2.
+func formatTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", k, tags[k]))
+	}
+
+	return strings.Join(pairs, "; ")
+}
+
+// ToCSV returns a CSV with a row for each cost component, with its current, past and diff values.
+func ToCSV(out Root, opts Options) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	err := w.Write(costComponentHeader(out.Currency))
+	if err != nil {
+		return nil, errors.Wrap(err, "Error writing CSV header")
+	}
+
+	for _, row := range costComponentRows(out, opts) {
+		err = w.Write(row.values())
+		if err != nil {
+			return nil, errors.Wrap(err, "Error writing CSV row")
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, errors.Wrap(err, "Error writing CSV")
+	}
+
+	return buf.Bytes(), nil
+}
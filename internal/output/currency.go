@@ -0,0 +1,150 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// ExchangeRate is the rate used to convert costs from one currency to another.
+type ExchangeRate struct {
+	From string          `json:"from"`
+	To   string          `json:"to"`
+	Rate decimal.Decimal `json:"rate"`
+	// Date is the date of the exchange rate, if known.
+	Date string `json:"date,omitempty"`
+}
+
+// SecondaryCurrency holds the total costs converted to a second currency, so they can be shown
+// next to the costs in the main currency.
+type SecondaryCurrency struct {
+	Currency             string           `json:"currency"`
+	ExchangeRate         ExchangeRate     `json:"exchangeRate"`
+	TotalHourlyCost      *decimal.Decimal `json:"totalHourlyCost"`
+	TotalMonthlyCost     *decimal.Decimal `json:"totalMonthlyCost"`
+	PastTotalHourlyCost  *decimal.Decimal `json:"pastTotalHourlyCost"`
+	PastTotalMonthlyCost *decimal.Decimal `json:"pastTotalMonthlyCost"`
+	DiffTotalHourlyCost  *decimal.Decimal `json:"diffTotalHourlyCost"`
+	DiffTotalMonthlyCost *decimal.Decimal `json:"diffTotalMonthlyCost"`
+}
+
+// Convert returns the cost converted to the secondary currency, or nil if the cost is nil.
+func (s *SecondaryCurrency) Convert(d *decimal.Decimal) *decimal.Decimal {
+	return convertCost(d, s.ExchangeRate.Rate)
+}
+
+func rootCurrency(out Root) string {
+	if out.Currency == "" {
+		return "USD"
+	}
+
+	return strings.ToUpper(out.Currency)
+}
+
+// ConvertCurrency returns a copy of out with all the costs and prices converted using the exchange rate.
+// The exchange rate is recorded in the ExchangeRates of the result so the conversion can be audited.
+func ConvertCurrency(out Root, rate ExchangeRate) (Root, error) {
+	if !strings.EqualFold(rootCurrency(out), rate.From) {
+		return out, fmt.Errorf("Can't convert %s costs using a %s to %s exchange rate", rootCurrency(out), rate.From, rate.To)
+	}
+
+	if strings.EqualFold(rate.From, rate.To) {
+		return out, nil
+	}
+
+	converted := out
+	converted.Currency = rate.To
+	converted.TotalHourlyCost = convertCost(out.TotalHourlyCost, rate.Rate)
+	converted.TotalMonthlyCost = convertCost(out.TotalMonthlyCost, rate.Rate)
+	converted.PastTotalHourlyCost = convertCost(out.PastTotalHourlyCost, rate.Rate)
+	converted.PastTotalMonthlyCost = convertCost(out.PastTotalMonthlyCost, rate.Rate)
+	converted.DiffTotalHourlyCost = convertCost(out.DiffTotalHourlyCost, rate.Rate)
+	converted.DiffTotalMonthlyCost = convertCost(out.DiffTotalMonthlyCost, rate.Rate)
+
+	converted.Projects = make([]Project, len(out.Projects))
+	for i, p := range out.Projects {
+		p.PastBreakdown = convertBreakdown(p.PastBreakdown, rate.Rate)
+		p.Breakdown = convertBreakdown(p.Breakdown, rate.Rate)
+		p.Diff = convertBreakdown(p.Diff, rate.Rate)
+		converted.Projects[i] = p
+	}
+
+	converted.ExchangeRates = append(append([]ExchangeRate{}, out.ExchangeRates...), rate)
+	// The secondary currency rate is relative to the old currency, so it has to be added again if needed.
+	converted.SecondaryCurrency = nil
+
+	return converted, nil
+}
+
+// AddSecondaryCurrency sets the SecondaryCurrency of out to the total costs converted using the exchange rate.
+func AddSecondaryCurrency(out *Root, rate ExchangeRate) {
+	out.SecondaryCurrency = &SecondaryCurrency{
+		Currency:             rate.To,
+		ExchangeRate:         rate,
+		TotalHourlyCost:      convertCost(out.TotalHourlyCost, rate.Rate),
+		TotalMonthlyCost:     convertCost(out.TotalMonthlyCost, rate.Rate),
+		PastTotalHourlyCost:  convertCost(out.PastTotalHourlyCost, rate.Rate),
+		PastTotalMonthlyCost: convertCost(out.PastTotalMonthlyCost, rate.Rate),
+		DiffTotalHourlyCost:  convertCost(out.DiffTotalHourlyCost, rate.Rate),
+		DiffTotalMonthlyCost: convertCost(out.DiffTotalMonthlyCost, rate.Rate),
+	}
+}
+
+func convertBreakdown(b *Breakdown, rate decimal.Decimal) *Breakdown {
+	if b == nil {
+		return nil
+	}
+
+	return &Breakdown{
+		Resources:        convertResources(b.Resources, rate),
+		TotalHourlyCost:  convertCost(b.TotalHourlyCost, rate),
+		TotalMonthlyCost: convertCost(b.TotalMonthlyCost, rate),
+	}
+}
+
+func convertResources(resources []Resource, rate decimal.Decimal) []Resource {
+	if resources == nil {
+		return nil
+	}
+
+	converted := make([]Resource, len(resources))
+	for i, r := range resources {
+		r.HourlyCost = convertCost(r.HourlyCost, rate)
+		r.MonthlyCost = convertCost(r.MonthlyCost, rate)
+
+		if r.CostComponents != nil {
+			costComponents := make([]CostComponent, len(r.CostComponents))
+			for j, c := range r.CostComponents {
+				c.Price = c.Price.Mul(rate)
+				c.HourlyCost = convertCost(c.HourlyCost, rate)
+				c.MonthlyCost = convertCost(c.MonthlyCost, rate)
+				costComponents[j] = c
+			}
+			r.CostComponents = costComponents
+		}
+
+		r.SubResources = convertResources(r.SubResources, rate)
+		converted[i] = r
+	}
+
+	return converted
+}
+
+func convertCost(d *decimal.Decimal, rate decimal.Decimal) *decimal.Decimal {
+	if d == nil {
+		return nil
+	}
+
+	return decimalPtr(d.Mul(rate))
+}
+
+// formatSecondaryCost returns the cost converted to the secondary currency of out, formatted to be
+// shown next to the cost in the main currency, or an empty string if there is no secondary currency.
+func formatSecondaryCost(out Root, d *decimal.Decimal) string {
+	if out.SecondaryCurrency == nil || d == nil {
+		return ""
+	}
+
+	return fmt.Sprintf(" (%s)", formatCost2DP(out.SecondaryCurrency.Currency, out.SecondaryCurrency.Convert(d)))
+}
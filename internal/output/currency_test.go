@@ -0,0 +1,74 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertCurrency(t *testing.T) {
+	out := Root{
+		Currency:         "USD",
+		TotalMonthlyCost: decimalPtr(decimal.NewFromInt(100)),
+		Projects: []Project{
+			{
+				Name: "project",
+				Breakdown: &Breakdown{
+					TotalMonthlyCost: decimalPtr(decimal.NewFromInt(100)),
+					Resources: []Resource{
+						{
+							Name:        "aws_instance.web",
+							MonthlyCost: decimalPtr(decimal.NewFromInt(100)),
+							CostComponents: []CostComponent{
+								{Name: "Instance usage", Price: decimal.NewFromInt(2), MonthlyCost: decimalPtr(decimal.NewFromInt(100))},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	rate := ExchangeRate{From: "USD", To: "EUR", Rate: decimal.NewFromFloat(0.9), Date: "2022-06-01"}
+
+	converted, err := ConvertCurrency(out, rate)
+	require.NoError(t, err)
+
+	assert.Equal(t, "EUR", converted.Currency)
+	assert.Equal(t, []ExchangeRate{rate}, converted.ExchangeRates)
+	assert.Equal(t, "90", converted.TotalMonthlyCost.String())
+	assert.Nil(t, converted.TotalHourlyCost)
+	assert.Nil(t, converted.Projects[0].PastBreakdown)
+
+	breakdown := converted.Projects[0].Breakdown
+	assert.Equal(t, "90", breakdown.TotalMonthlyCost.String())
+	assert.Equal(t, "90", breakdown.Resources[0].MonthlyCost.String())
+	assert.Equal(t, "1.8", breakdown.Resources[0].CostComponents[0].Price.String())
+
+	// The input isn't changed
+	assert.Equal(t, "USD", out.Currency)
+	assert.Equal(t, "100", out.Projects[0].Breakdown.Resources[0].CostComponents[0].MonthlyCost.String())
+
+	_, err = ConvertCurrency(converted, rate)
+	assert.EqualError(t, err, "Can't convert EUR costs using a USD to EUR exchange rate")
+}
+
+func TestAddSecondaryCurrency(t *testing.T) {
+	out := Root{
+		Currency:             "USD",
+		TotalMonthlyCost:     decimalPtr(decimal.NewFromInt(200)),
+		PastTotalMonthlyCost: decimalPtr(decimal.NewFromInt(100)),
+		DiffTotalMonthlyCost: decimalPtr(decimal.NewFromInt(100)),
+	}
+
+	AddSecondaryCurrency(&out, ExchangeRate{From: "USD", To: "GBP", Rate: decimal.NewFromFloat(0.8)})
+
+	require.NotNil(t, out.SecondaryCurrency)
+	assert.Equal(t, "GBP", out.SecondaryCurrency.Currency)
+	assert.Equal(t, "160", out.SecondaryCurrency.TotalMonthlyCost.String())
+	assert.Equal(t, "80", out.SecondaryCurrency.DiffTotalMonthlyCost.String())
+	assert.Nil(t, out.SecondaryCurrency.TotalHourlyCost)
+	assert.Equal(t, " (£80.00)", formatSecondaryCost(out, out.DiffTotalMonthlyCost))
+}
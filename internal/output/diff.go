@@ -14,12 +14,14 @@ const (
 	UPDATED = iota
 	ADDED
 	REMOVED
+	MOVED
 )
 
 func ToDiff(out Root, opts Options) ([]byte, error) {
 	s := ""
 
 	noDiffProjects := make([]string, 0)
+	hasMoved := false
 
 	for i, project := range out.Projects {
 		if project.Diff == nil {
@@ -42,7 +44,16 @@ func ToDiff(out Root, opts Options) ([]byte, error) {
 		)
 
 		for _, diffResource := range project.Diff.Resources {
-			oldResource := findResourceByName(project.PastBreakdown.Resources, diffResource.Name)
+			var oldResource *Resource
+			if diffResource.MovedFrom != "" {
+				hasMoved = true
+				oldResource = findResourceByName(project.PastBreakdown.Resources, diffResource.MovedFrom)
+			}
+
+			// The past resource already has the new address if Terraform applied the move to the prior state.
+			if oldResource == nil {
+				oldResource = findResourceByName(project.PastBreakdown.Resources, diffResource.Name)
+			}
 			newResource := findResourceByName(project.Breakdown.Resources, diffResource.Name)
 
 			s += resourceToDiff(out.Currency, diffResource, oldResource, newResource, true)
@@ -65,6 +76,7 @@ func ToDiff(out Root, opts Options) ([]byte, error) {
 			formatTitleWithCurrency(formatCostChange(out.Currency, project.Diff.TotalMonthlyCost), out.Currency),
 			ui.FaintStringf("(%s → %s)", formatCost(out.Currency, oldCost), formatCost(out.Currency, newCost)),
 		)
+		s += formatSecondaryCost(out, project.Diff.TotalMonthlyCost)
 
 		percent := formatPercentChange(oldCost, newCost)
 		if percent != "" {
@@ -85,11 +97,15 @@ func ToDiff(out Root, opts Options) ([]byte, error) {
 
 	s += "──────────────────────────────────\n"
 	if len(noDiffProjects) != len(out.Projects) {
-		s += fmt.Sprintf("Key: %s changed, %s added, %s removed\n",
+		key := fmt.Sprintf("Key: %s changed, %s added, %s removed",
 			opChar(UPDATED),
 			opChar(ADDED),
 			opChar(REMOVED),
 		)
+		if hasMoved {
+			key += fmt.Sprintf(", %s moved", opChar(MOVED))
+		}
+		s += key + "\n"
 	}
 
 	unsupportedMsg := out.summaryMessage(opts.ShowSkipped)
@@ -100,6 +116,10 @@ func ToDiff(out Root, opts Options) ([]byte, error) {
 		s += unsupportedMsg
 	}
 
+	if policyMsg := policyChecksMessage(opts.PolicyChecks); policyMsg != "" {
+		s += "\n──────────────────────────────────\n" + policyMsg
+	}
+
 	return []byte(s), nil
 }
 
@@ -111,6 +131,8 @@ func resourceToDiff(currency string, diffResource Resource, oldResource *Resourc
 		op = ADDED
 	} else if newResource == nil {
 		op = REMOVED
+	} else if diffResource.MovedFrom != "" {
+		op = MOVED
 	}
 
 	var oldCost *decimal.Decimal
@@ -128,6 +150,10 @@ func resourceToDiff(currency string, diffResource Resource, oldResource *Resourc
 		nameLabel = ui.BoldString(nameLabel)
 	}
 
+	if op == MOVED {
+		nameLabel += ui.FaintStringf(" (moved from %s)", diffResource.MovedFrom)
+	}
+
 	s += fmt.Sprintf("%s %s\n", opChar(op), nameLabel)
 
 	if isTopLevel {
@@ -226,6 +252,8 @@ func opChar(op int) string {
 		return color.GreenString("+")
 	case REMOVED:
 		return color.RedString("-")
+	case MOVED:
+		return color.CyanString("→")
 	default:
 		return color.YellowString("~")
 	}
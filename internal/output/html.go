@@ -3,24 +3,167 @@ package output
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"html/template"
 	"strings"
 
-	"github.com/infracost/infracost/internal/ui"
-	"github.com/shopspring/decimal"
-
 	"github.com/Masterminds/sprig"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
 
 	log "github.com/sirupsen/logrus"
 )
 
+// htmlReport is the data embedded as JSON in the HTML report, which is rendered by its script so the
+// resources can be sorted, filtered and drilled into without a server.
+type htmlReport struct {
+	Currency  string               `json:"currency"`
+	Fields    []string             `json:"fields"`
+	HasDiff   bool                 `json:"hasDiff"`
+	Resources []htmlReportResource `json:"resources"`
+}
+
+type htmlReportResource struct {
+	Project         string                    `json:"project,omitempty"`
+	Name            string                    `json:"name"`
+	MovedFrom       string                    `json:"movedFrom,omitempty"`
+	ResourceType    string                    `json:"resourceType,omitempty"`
+	Tags            map[string]string         `json:"tags,omitempty"`
+	MonthlyCost     *decimal.Decimal          `json:"monthlyCost"`
+	PastMonthlyCost *decimal.Decimal          `json:"pastMonthlyCost,omitempty"`
+	DiffMonthlyCost *decimal.Decimal          `json:"diffMonthlyCost,omitempty"`
+	CostComponents  []htmlReportCostComponent `json:"costComponents,omitempty"`
+	SubResources    []htmlReportResource      `json:"subresources,omitempty"`
+}
+
+type htmlReportCostComponent struct {
+	Name            string           `json:"name"`
+	Unit            string           `json:"unit"`
+	MonthlyQuantity *decimal.Decimal `json:"monthlyQuantity"`
+	Price           decimal.Decimal  `json:"price"`
+	HourlyCost      *decimal.Decimal `json:"hourlyCost"`
+	MonthlyCost     *decimal.Decimal `json:"monthlyCost"`
+}
+
+// htmlReportJSON returns a row for each top level resource of the projects. Resources that are only in
+// the past breakdown, i.e. removed resources, are included so the diff view can show them.
+func htmlReportJSON(out Root, opts Options) (template.JS, error) {
+	report := htmlReport{
+		Currency:  out.Currency,
+		Fields:    opts.Fields,
+		Resources: []htmlReportResource{},
+	}
+
+	for _, p := range out.Projects {
+		label := p.Label(opts.DashboardEnabled)
+
+		past := map[string]Resource{}
+		if p.PastBreakdown != nil {
+			report.HasDiff = true
+			for _, r := range p.PastBreakdown.Resources {
+				past[r.Name] = r
+			}
+		}
+
+		seen := map[string]bool{}
+
+		if p.Breakdown != nil {
+			for _, r := range p.Breakdown.Resources {
+				seen[r.Name] = true
+
+				res := newHTMLReportResource(r)
+				res.Project = label
+				res.ResourceType = ResourceType(r.Name)
+
+				if p.PastBreakdown != nil {
+					var pastCost *decimal.Decimal
+					if pastRes, ok := past[r.MovedFrom]; ok && r.MovedFrom != "" {
+						seen[r.MovedFrom] = true
+						pastCost = pastRes.MonthlyCost
+					} else if pastRes, ok := past[r.Name]; ok {
+						pastCost = pastRes.MonthlyCost
+					}
+
+					res.PastMonthlyCost = pastCost
+					res.DiffMonthlyCost = diffDecimal(pastCost, r.MonthlyCost)
+				}
+
+				report.Resources = append(report.Resources, res)
+			}
+		}
+
+		if p.PastBreakdown != nil {
+			for _, r := range p.PastBreakdown.Resources {
+				if seen[r.Name] {
+					continue
+				}
+
+				res := newHTMLReportResource(r)
+				res.Project = label
+				res.ResourceType = ResourceType(r.Name)
+				res.MonthlyCost = nil
+				res.PastMonthlyCost = r.MonthlyCost
+				res.DiffMonthlyCost = diffDecimal(r.MonthlyCost, nil)
+
+				report.Resources = append(report.Resources, res)
+			}
+		}
+	}
+
+	b, err := json.Marshal(report)
+	if err != nil {
+		return "", errors.Wrap(err, "Error generating HTML report data")
+	}
+
+	return template.JS(b), nil // nolint:gosec
+}
+
+func newHTMLReportResource(r Resource) htmlReportResource {
+	res := htmlReportResource{
+		Name:        r.Name,
+		MovedFrom:   r.MovedFrom,
+		Tags:        r.Tags,
+		MonthlyCost: r.MonthlyCost,
+	}
+
+	for _, c := range r.CostComponents {
+		res.CostComponents = append(res.CostComponents, htmlReportCostComponent{
+			Name:            c.Name,
+			Unit:            c.Unit,
+			MonthlyQuantity: c.MonthlyQuantity,
+			Price:           c.Price,
+			HourlyCost:      c.HourlyCost,
+			MonthlyCost:     c.MonthlyCost,
+		})
+	}
+
+	for _, s := range r.SubResources {
+		res.SubResources = append(res.SubResources, newHTMLReportResource(s))
+	}
+
+	return res
+}
+
 func ToHTML(out Root, opts Options) ([]byte, error) {
 	var buf bytes.Buffer
 	bufw := bufio.NewWriter(&buf)
 
-	tmpl := template.New("base")
+	name := "base"
+	t := HTMLTemplate
+
+	if opts.TemplatePath != "" {
+		var err error
+		name = opts.TemplatePath
+		t, err = readTemplate(opts.TemplatePath)
+		if err != nil {
+			return []byte{}, err
+		}
+	}
+
+	tmpl := template.New(name)
 	tmpl.Funcs(sprig.FuncMap())
+	tmpl.Funcs(templateFuncs(out, opts))
 	tmpl.Funcs(template.FuncMap{
 		"safeHTML": func(s interface{}) template.HTML {
 			return template.HTML(fmt.Sprint(s)) // nolint:gosec
@@ -30,8 +173,7 @@ func ToHTML(out Root, opts Options) ([]byte, error) {
 			safe = strings.ReplaceAll(safe, "\n", "<br />")
 			return template.HTML(safe) // nolint:gosec
 		},
-		"stripColor": ui.StripColor,
-		"contains":   contains,
+		"contains": contains,
 		"hasCost": func(cc []CostComponent, sr []Resource, resourceName string) bool {
 			if len(cc) > 0 || len(sr) > 0 {
 				return true
@@ -42,28 +184,27 @@ func ToHTML(out Root, opts Options) ([]byte, error) {
 		},
 		"filterZeroValComponents": filterZeroValComponents,
 		"filterZeroValResources":  filterZeroValResources,
-		"formatCost2DP":           func(d *decimal.Decimal) string { return formatCost2DP(out.Currency, d) },
-		"formatPrice":             func(d decimal.Decimal) string { return formatPrice(out.Currency, d) },
-		"formatTitleWithCurrency": func(title string) string { return formatTitleWithCurrency(title, out.Currency) },
-		"formatQuantity":          formatQuantity,
-		"projectLabel": func(p Project) string {
-			return p.Label(opts.DashboardEnabled)
-		},
 	})
-	tmpl, err := tmpl.Parse(HTMLTemplate)
+	tmpl, err := tmpl.Parse(t)
 	if err != nil {
-		return []byte{}, err
+		return []byte{}, templateError(err)
 	}
 
 	summaryMessage := out.summaryMessage(opts.ShowSkipped)
 
+	reportData, err := htmlReportJSON(out, opts)
+	if err != nil {
+		return []byte{}, err
+	}
+
 	err = tmpl.Execute(bufw, struct {
 		Root           Root
 		SummaryMessage string
 		Options        Options
-	}{out, summaryMessage, opts})
+		ReportData     template.JS
+	}{out, summaryMessage, opts, reportData})
 	if err != nil {
-		return []byte{}, err
+		return []byte{}, templateError(err)
 	}
 
 	bufw.Flush()
@@ -0,0 +1,91 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTMLReportJSON(t *testing.T) {
+	dec := func(f float64) *decimal.Decimal {
+		d := decimal.NewFromFloat(f)
+		return &d
+	}
+
+	out := Root{
+		Currency: "USD",
+		Projects: []Project{
+			{
+				Name: "infra/prod",
+				Breakdown: &Breakdown{
+					Resources: []Resource{
+						{
+							Name:        "aws_instance.web",
+							Tags:        map[string]string{"team": "<web>"},
+							MonthlyCost: dec(10),
+							CostComponents: []CostComponent{
+								{Name: "Instance usage", Unit: "hours", MonthlyQuantity: dec(730), Price: decimal.NewFromFloat(0.01), MonthlyCost: dec(7.3)},
+							},
+							SubResources: []Resource{
+								{Name: "root_block_device", MonthlyCost: dec(2.7)},
+							},
+						},
+						{Name: "aws_nat_gateway.main", MonthlyCost: dec(30)},
+					},
+				},
+				PastBreakdown: &Breakdown{
+					Resources: []Resource{
+						{Name: "aws_instance.web", MonthlyCost: dec(5)},
+						{Name: "aws_eip.old", MonthlyCost: dec(4)},
+					},
+				},
+			},
+		},
+	}
+
+	js, err := htmlReportJSON(out, Options{Fields: []string{"monthlyCost"}})
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+  "currency": "USD",
+  "fields": ["monthlyCost"],
+  "hasDiff": true,
+  "resources": [
+    {
+      "project": "infra/prod",
+      "name": "aws_instance.web",
+      "resourceType": "aws_instance",
+      "tags": {"team": "<web>"},
+      "monthlyCost": "10",
+      "pastMonthlyCost": "5",
+      "diffMonthlyCost": "5",
+      "costComponents": [
+        {"name": "Instance usage", "unit": "hours", "monthlyQuantity": "730", "price": "0.01", "hourlyCost": null, "monthlyCost": "7.3"}
+      ],
+      "subresources": [
+        {"name": "root_block_device", "monthlyCost": "2.7"}
+      ]
+    },
+    {
+      "project": "infra/prod",
+      "name": "aws_nat_gateway.main",
+      "resourceType": "aws_nat_gateway",
+      "monthlyCost": "30",
+      "diffMonthlyCost": "30"
+    },
+    {
+      "project": "infra/prod",
+      "name": "aws_eip.old",
+      "resourceType": "aws_eip",
+      "monthlyCost": null,
+      "pastMonthlyCost": "4",
+      "diffMonthlyCost": "-4"
+    }
+  ]
+}`, string(js))
+
+	// The data is embedded in a script tag so HTML characters must be escaped.
+	assert.NotContains(t, string(js), "<web>")
+}
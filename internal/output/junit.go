@@ -0,0 +1,116 @@
+package output
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      string        `xml:"line,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// ToJUnit returns the policy results as a JUnit XML report so they can be shown by CI systems such as
+// Jenkins and GitLab. There is a test suite for each policy and a test case for each policy and resource.
+// Warnings are reported as passed test cases with the warning in their output since they don't fail the run.
+func ToJUnit(out Root, opts Options) ([]byte, error) {
+	report := junitTestSuites{
+		Name: "Infracost policies",
+	}
+
+	suiteIndexes := map[string]int{}
+
+	addResults := func(results []PolicyCheckResult, severity string) {
+		for _, r := range results {
+			name := policyName(r)
+
+			i, ok := suiteIndexes[name]
+			if !ok {
+				i = len(report.Suites)
+				suiteIndexes[name] = i
+				report.Suites = append(report.Suites, junitTestSuite{Name: name})
+			}
+
+			tc := junitTestCase{
+				Name:      r.Resource,
+				ClassName: r.Project,
+			}
+			if tc.Name == "" {
+				tc.Name = r.Message
+			}
+			if tc.ClassName == "" {
+				tc.ClassName = name
+			}
+
+			filename, line := r.resourceSource(out)
+			tc.File = filename
+			if line > 0 {
+				tc.Line = strconv.Itoa(line)
+			}
+
+			switch severity {
+			case PolicySeverityError:
+				text := r.Message
+				if r.Remediation != "" {
+					text += "\nRemediation: " + r.Remediation
+				}
+
+				tc.Failure = &junitFailure{Message: r.Message, Type: severity, Text: text}
+				report.Suites[i].Failures++
+				report.Failures++
+			case PolicySeverityWarn:
+				tc.SystemOut = "Warning: " + r.Message
+				if r.Remediation != "" {
+					tc.SystemOut += "\nRemediation: " + r.Remediation
+				}
+			}
+
+			report.Suites[i].TestCases = append(report.Suites[i].TestCases, tc)
+			report.Suites[i].Tests++
+			report.Tests++
+		}
+	}
+
+	addResults(opts.PolicyChecks.Failures, PolicySeverityError)
+	addResults(opts.PolicyChecks.Warnings, PolicySeverityWarn)
+	addResults(opts.PolicyChecks.Passed, "")
+
+	b, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "Error generating JUnit XML")
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.Write(b)
+
+	return buf.Bytes(), nil
+}
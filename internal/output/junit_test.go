@@ -0,0 +1,30 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToJUnit(t *testing.T) {
+	b, err := ToJUnit(policyTestRoot(), policyTestOptions())
+	require.NoError(t, err)
+
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="Infracost policies" tests="3" failures="1">
+  <testsuite name="prod" tests="1" failures="1">
+    <testcase name="aws_instance.web" classname="infra/prod" file="infra/prod/main.tf" line="12">
+      <failure message="prod: Monthly cost exceeds the maximum" type="error">prod: Monthly cost exceeds the maximum&#xA;Remediation: Use a smaller instance</failure>
+    </testcase>
+  </testsuite>
+  <testsuite name="tags" tests="1" failures="0">
+    <testcase name="aws_nat_gateway.main" classname="infra/prod">
+      <system-out>Warning: tags: Resource is missing required tags: team</system-out>
+    </testcase>
+  </testsuite>
+  <testsuite name="Cost policy" tests="1" failures="0">
+    <testcase name="Cost increase is within budget" classname="Cost policy"></testcase>
+  </testsuite>
+</testsuites>`, string(b))
+}
@@ -65,7 +65,12 @@ func formatCostChangeSentence(currency string, pastCost, cost *decimal.Decimal,
 }
 
 func ToMarkdown(out Root, opts Options, markdownOpts MarkdownOptions) ([]byte, error) {
-	diff, err := ToDiff(out, opts)
+	// Policy checks are rendered separately by the markdown templates so don't
+	// include them in the diff output as well.
+	diffOpts := opts
+	diffOpts.PolicyChecks = PolicyCheck{}
+
+	diff, err := ToDiff(out, diffOpts)
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed to generate diff")
 	}
@@ -73,38 +78,27 @@ func ToMarkdown(out Root, opts Options, markdownOpts MarkdownOptions) ([]byte, e
 	var buf bytes.Buffer
 	bufw := bufio.NewWriter(&buf)
 
-	tmpl := template.New("base")
-	tmpl.Funcs(sprig.TxtFuncMap())
-	tmpl.Funcs(template.FuncMap{
-		"formatCost": func(d *decimal.Decimal) string {
-			if d == nil || d.IsZero() {
-				return formatWholeDecimalCurrency(out.Currency, decimal.Zero)
-			}
-			return formatCost(out.Currency, d)
-		},
-		"formatCostChange": func(pastCost, cost *decimal.Decimal) string {
-			return formatMarkdownCostChange(out.Currency, pastCost, cost, false)
-		},
-		"formatCostChangeSentence": formatCostChangeSentence,
-		"hasDiff": func(p Project) bool {
-			if p.Diff == nil || len(p.Diff.Resources) == 0 {
-				return false
-			}
-			return true
-		},
-		"projectLabel": func(p Project) string {
-			return p.Label(opts.DashboardEnabled)
-		},
-		"truncateMiddle": truncateMiddle,
-	})
-
+	name := "base"
 	t := CommentMarkdownWithHTMLTemplate
 	if markdownOpts.BasicSyntax {
 		t = CommentMarkdownTemplate
 	}
+
+	if opts.TemplatePath != "" {
+		name = opts.TemplatePath
+		t, err = readTemplate(opts.TemplatePath)
+		if err != nil {
+			return []byte{}, err
+		}
+	}
+
+	tmpl := template.New(name)
+	tmpl.Funcs(sprig.TxtFuncMap())
+	tmpl.Funcs(templateFuncs(out, opts))
+
 	tmpl, err = tmpl.Parse(t)
 	if err != nil {
-		return []byte{}, err
+		return []byte{}, templateError(err)
 	}
 
 	skippedProjectCount := 0
@@ -120,14 +114,16 @@ func ToMarkdown(out Root, opts Options, markdownOpts MarkdownOptions) ([]byte, e
 		DiffOutput          string
 		Options             Options
 		MarkdownOptions     MarkdownOptions
+		ApprovalRequired    bool
 	}{
 		out,
 		skippedProjectCount,
 		ui.StripColor(string(diff)),
 		opts,
-		markdownOpts})
+		markdownOpts,
+		markdownOpts.ApprovalRequired(out)})
 	if err != nil {
-		return []byte{}, err
+		return []byte{}, templateError(err)
 	}
 
 	bufw.Flush()
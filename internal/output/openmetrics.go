@@ -0,0 +1,151 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// MetricsLabelLevelResource adds the project, resource type and resource labels to the cost metrics.
+	MetricsLabelLevelResource = "resource"
+	// MetricsLabelLevelResourceType adds the project and resource type labels to the cost metrics, so the
+	// resource costs are summed by resource type.
+	MetricsLabelLevelResourceType = "resource_type"
+	// MetricsLabelLevelProject only adds the project label to the cost metrics, so the resource costs are
+	// summed by project.
+	MetricsLabelLevelProject = "project"
+)
+
+// MetricsLabelLevels are the valid values of Options.MetricsLabelLevel.
+var MetricsLabelLevels = []string{MetricsLabelLevelResource, MetricsLabelLevelResourceType, MetricsLabelLevelProject}
+
+// metric is a gauge metric family and its samples.
+type metric struct {
+	name    string
+	help    string
+	samples []*metricSample
+	index   map[string]*metricSample
+}
+
+type metricSample struct {
+	labels [][2]string
+	value  decimal.Decimal
+}
+
+func newMetric(name, help string) *metric {
+	return &metric{
+		name:  name,
+		help:  help,
+		index: map[string]*metricSample{},
+	}
+}
+
+// add adds the value to the sample with the labels, so samples that end up with the same labels
+// after the labels are capped are summed.
+func (m *metric) add(labels [][2]string, value decimal.Decimal) {
+	key := fmt.Sprintf("%q", labels)
+
+	s, ok := m.index[key]
+	if !ok {
+		s = &metricSample{labels: labels}
+		m.index[key] = s
+		m.samples = append(m.samples, s)
+	}
+
+	s.value = s.value.Add(value)
+}
+
+func (m *metric) write(buf *bytes.Buffer) {
+	fmt.Fprintf(buf, "# HELP %s %s\n", m.name, m.help)
+	fmt.Fprintf(buf, "# TYPE %s gauge\n", m.name)
+
+	for _, s := range m.samples {
+		labels := make([]string, 0, len(s.labels))
+		for _, l := range s.labels {
+			labels = append(labels, fmt.Sprintf("%s=\"%s\"", l[0], escapeMetricLabelValue(l[1])))
+		}
+
+		fmt.Fprintf(buf, "%s{%s} %s\n", m.name, strings.Join(labels, ","), s.value.String())
+	}
+}
+
+var metricLabelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeMetricLabelValue(s string) string {
+	return metricLabelValueReplacer.Replace(s)
+}
+
+// resourceMetricLabels returns the labels of the resource cost metrics for the label level.
+func resourceMetricLabels(projectLabels [][2]string, address, level string) [][2]string {
+	labels := make([][2]string, 0, len(projectLabels)+2)
+	labels = append(labels, projectLabels...)
+
+	switch level {
+	case MetricsLabelLevelResource:
+		labels = append(labels, [2]string{"resource_type", ResourceType(address)}, [2]string{"resource", address})
+	case MetricsLabelLevelResourceType:
+		labels = append(labels, [2]string{"resource_type", ResourceType(address)})
+	}
+
+	return labels
+}
+
+// ToOpenMetrics returns the costs as gauges in the OpenMetrics text format so they can be scraped or pushed
+// to a Prometheus Pushgateway and charted over time. The labels of the resource cost metrics are capped at
+// the level set by Options.MetricsLabelLevel to limit the number of time series.
+func ToOpenMetrics(out Root, opts Options) ([]byte, error) {
+	level := opts.MetricsLabelLevel
+	if level == "" {
+		level = MetricsLabelLevelResource
+	}
+
+	if !contains(MetricsLabelLevels, level) {
+		return nil, fmt.Errorf("Invalid metrics label level %s, valid values are %s", level, strings.Join(MetricsLabelLevels, ", "))
+	}
+
+	currency := out.Currency
+	if currency == "" {
+		currency = "USD"
+	}
+
+	monthlyCost := newMetric("infracost_monthly_cost", "Estimated monthly cost.")
+	hourlyCost := newMetric("infracost_hourly_cost", "Estimated hourly cost.")
+	pastMonthlyCost := newMetric("infracost_past_monthly_cost", "Estimated monthly cost before the changes.")
+	diffMonthlyCost := newMetric("infracost_diff_monthly_cost", "Estimated monthly cost change.")
+
+	for _, p := range out.Projects {
+		projectLabels := [][2]string{{"currency", currency}, {"project", p.Label(opts.DashboardEnabled)}}
+
+		if p.Breakdown != nil {
+			for _, r := range p.Breakdown.Resources {
+				labels := resourceMetricLabels(projectLabels, r.Name, level)
+
+				if r.MonthlyCost != nil {
+					monthlyCost.add(labels, *r.MonthlyCost)
+				}
+				if r.HourlyCost != nil {
+					hourlyCost.add(labels, *r.HourlyCost)
+				}
+			}
+		}
+
+		if p.PastBreakdown != nil && p.PastBreakdown.TotalMonthlyCost != nil {
+			pastMonthlyCost.add(projectLabels, *p.PastBreakdown.TotalMonthlyCost)
+		}
+
+		if p.Diff != nil && p.Diff.TotalMonthlyCost != nil {
+			diffMonthlyCost.add(projectLabels, *p.Diff.TotalMonthlyCost)
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, m := range []*metric{monthlyCost, hourlyCost, pastMonthlyCost, diffMonthlyCost} {
+		m.write(&buf)
+	}
+	buf.WriteString("# EOF\n")
+
+	return buf.Bytes(), nil
+}
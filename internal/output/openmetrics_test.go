@@ -0,0 +1,76 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openMetricsTestRoot() Root {
+	return Root{
+		Currency: "EUR",
+		Projects: []Project{
+			{
+				Name: `infra "prod"`,
+				PastBreakdown: &Breakdown{
+					TotalMonthlyCost: decimalPtr(decimal.NewFromFloat(100)),
+				},
+				Breakdown: &Breakdown{
+					Resources: []Resource{
+						{Name: "aws_instance.web", HourlyCost: decimalPtr(decimal.NewFromFloat(0.1)), MonthlyCost: decimalPtr(decimal.NewFromFloat(73))},
+						{Name: "aws_instance.api", HourlyCost: decimalPtr(decimal.NewFromFloat(0.2)), MonthlyCost: decimalPtr(decimal.NewFromFloat(146))},
+						{Name: `module.db["a"].aws_db_instance.db`, HourlyCost: decimalPtr(decimal.NewFromFloat(0.5)), MonthlyCost: decimalPtr(decimal.NewFromFloat(365))},
+						{Name: "aws_lambda_function.hello"},
+					},
+				},
+				Diff: &Breakdown{
+					TotalMonthlyCost: decimalPtr(decimal.NewFromFloat(484)),
+				},
+			},
+		},
+	}
+}
+
+func TestToOpenMetrics(t *testing.T) {
+	b, err := ToOpenMetrics(openMetricsTestRoot(), Options{})
+	require.NoError(t, err)
+
+	assert.Equal(t, `# HELP infracost_monthly_cost Estimated monthly cost.
+# TYPE infracost_monthly_cost gauge
+infracost_monthly_cost{currency="EUR",project="infra \"prod\"",resource_type="aws_instance",resource="aws_instance.web"} 73
+infracost_monthly_cost{currency="EUR",project="infra \"prod\"",resource_type="aws_instance",resource="aws_instance.api"} 146
+infracost_monthly_cost{currency="EUR",project="infra \"prod\"",resource_type="aws_db_instance",resource="module.db[\"a\"].aws_db_instance.db"} 365
+# HELP infracost_hourly_cost Estimated hourly cost.
+# TYPE infracost_hourly_cost gauge
+infracost_hourly_cost{currency="EUR",project="infra \"prod\"",resource_type="aws_instance",resource="aws_instance.web"} 0.1
+infracost_hourly_cost{currency="EUR",project="infra \"prod\"",resource_type="aws_instance",resource="aws_instance.api"} 0.2
+infracost_hourly_cost{currency="EUR",project="infra \"prod\"",resource_type="aws_db_instance",resource="module.db[\"a\"].aws_db_instance.db"} 0.5
+# HELP infracost_past_monthly_cost Estimated monthly cost before the changes.
+# TYPE infracost_past_monthly_cost gauge
+infracost_past_monthly_cost{currency="EUR",project="infra \"prod\""} 100
+# HELP infracost_diff_monthly_cost Estimated monthly cost change.
+# TYPE infracost_diff_monthly_cost gauge
+infracost_diff_monthly_cost{currency="EUR",project="infra \"prod\""} 484
+# EOF
+`, string(b))
+}
+
+func TestToOpenMetricsLabelLevel(t *testing.T) {
+	b, err := ToOpenMetrics(openMetricsTestRoot(), Options{MetricsLabelLevel: MetricsLabelLevelResourceType})
+	require.NoError(t, err)
+
+	assert.Contains(t, string(b), `infracost_monthly_cost{currency="EUR",project="infra \"prod\"",resource_type="aws_instance"} 219
+infracost_monthly_cost{currency="EUR",project="infra \"prod\"",resource_type="aws_db_instance"} 365
+`)
+
+	b, err = ToOpenMetrics(openMetricsTestRoot(), Options{MetricsLabelLevel: MetricsLabelLevelProject})
+	require.NoError(t, err)
+
+	assert.Contains(t, string(b), `infracost_monthly_cost{currency="EUR",project="infra \"prod\""} 584
+# HELP infracost_hourly_cost`)
+
+	_, err = ToOpenMetrics(openMetricsTestRoot(), Options{MetricsLabelLevel: "cost_component"})
+	assert.Error(t, err)
+}
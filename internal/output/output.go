@@ -2,9 +2,15 @@ package output
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"path"
+	"path/filepath"
 	"regexp"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -15,7 +21,12 @@ import (
 	"github.com/infracost/infracost/internal/usage"
 )
 
-var outputVersion = "0.2"
+var outputVersion = "0.3"
+
+// Version returns the version of the Infracost JSON output schema.
+func Version() string {
+	return outputVersion
+}
 
 type Root struct {
 	Version              string           `json:"version"`
@@ -31,8 +42,12 @@ type Root struct {
 	DiffTotalMonthlyCost *decimal.Decimal `json:"diffTotalMonthlyCost"`
 	TimeGenerated        time.Time        `json:"timeGenerated"`
 	Summary              *Summary         `json:"summary"`
-	FullSummary          *Summary         `json:"-"`
-	IsCIRun              bool             `json:"-"`
+	// ExchangeRates are the rates the costs were converted with from other currencies.
+	ExchangeRates []ExchangeRate `json:"exchangeRates,omitempty"`
+	// SecondaryCurrency holds the total costs in a second currency, if one is configured.
+	SecondaryCurrency *SecondaryCurrency `json:"secondaryCurrency,omitempty"`
+	FullSummary       *Summary           `json:"-"`
+	IsCIRun           bool               `json:"-"`
 }
 
 type Project struct {
@@ -82,16 +97,33 @@ type CostComponent struct {
 	Price           decimal.Decimal  `json:"price"`
 	HourlyCost      *decimal.Decimal `json:"hourlyCost"`
 	MonthlyCost     *decimal.Decimal `json:"monthlyCost"`
+	// PriceHash identifies the price in the Cloud Pricing API. It is empty for custom and missing prices.
+	PriceHash     string                `json:"priceHash,omitempty"`
+	ProductFilter *schema.ProductFilter `json:"productFilter,omitempty"`
+	PriceFilter   *schema.PriceFilter   `json:"priceFilter,omitempty"`
+	// MonthlyDiscountPerc is the discount applied to the monthly cost, e.g. 0.1 for 10%.
+	MonthlyDiscountPerc float64 `json:"monthlyDiscountPerc,omitempty"`
+	// IsCustomPrice is true if the price was set by the user instead of coming from the Cloud Pricing API.
+	IsCustomPrice bool `json:"isCustomPrice,omitempty"`
+	// IsMissingPrice is true if no price was found, so the price was set to 0.
+	IsMissingPrice bool `json:"isMissingPrice,omitempty"`
 }
 
 type Resource struct {
-	Name           string            `json:"name"`
-	Tags           map[string]string `json:"tags,omitempty"`
-	Metadata       map[string]string `json:"metadata"`
-	HourlyCost     *decimal.Decimal  `json:"hourlyCost"`
-	MonthlyCost    *decimal.Decimal  `json:"monthlyCost"`
-	CostComponents []CostComponent   `json:"costComponents,omitempty"`
-	SubResources   []Resource        `json:"subresources,omitempty"`
+	// ID is a stable identifier for the resource, derived from the project name and the resource address,
+	// so the same resource can be tracked across runs.
+	ID                string            `json:"id"`
+	Name              string            `json:"name"`
+	MovedFrom         string            `json:"movedFrom,omitempty"`
+	Tags              map[string]string `json:"tags,omitempty"`
+	Metadata          map[string]string `json:"metadata"`
+	UnknownAttributes []string          `json:"unknownAttributes,omitempty"`
+	// Usage are the usage values, e.g. from the usage file, that were used to calculate the costs.
+	Usage          map[string]interface{} `json:"usage,omitempty"`
+	HourlyCost     *decimal.Decimal       `json:"hourlyCost"`
+	MonthlyCost    *decimal.Decimal       `json:"monthlyCost"`
+	CostComponents []CostComponent        `json:"costComponents,omitempty"`
+	SubResources   []Resource             `json:"subresources,omitempty"`
 }
 
 type Summary struct {
@@ -124,6 +156,11 @@ type Options struct {
 	Fields           []string
 	IncludeHTML      bool
 	PolicyChecks     PolicyCheck
+	// TemplatePath is the path to a Go template that is rendered instead of the built-in markdown or
+	// HTML templates, or as a plain text output.
+	TemplatePath string
+	// MetricsLabelLevel caps the labels of the OpenMetrics cost metrics, see MetricsLabelLevels.
+	MetricsLabelLevel string
 }
 
 // PolicyCheck holds information if a given run has any policy checks enabled.
@@ -131,7 +168,8 @@ type Options struct {
 type PolicyCheck struct {
 	Enabled  bool
 	Failures PolicyCheckFailures
-	Passed   []string
+	Warnings PolicyCheckResults
+	Passed   PolicyCheckResults
 }
 
 // HasFailed returns if the PolicyCheck has any cost policy failures
@@ -139,8 +177,175 @@ func (p PolicyCheck) HasFailed() bool {
 	return len(p.Failures) > 0
 }
 
+// Merge adds the results of another PolicyCheck to this one.
+func (p *PolicyCheck) Merge(other PolicyCheck) {
+	p.Enabled = p.Enabled || other.Enabled
+	p.Failures = append(p.Failures, other.Failures...)
+	p.Warnings = append(p.Warnings, other.Warnings...)
+	p.Passed = append(p.Passed, other.Passed...)
+}
+
+// HasWarnings returns if the PolicyCheck has any cost policy warnings. Warnings
+// are shown in the output but do not fail the run.
+func (p PolicyCheck) HasWarnings() bool {
+	return len(p.Warnings) > 0
+}
+
+const (
+	// PolicySeverityError marks a policy result that fails the run.
+	PolicySeverityError = "error"
+	// PolicySeverityWarn marks a policy result that is reported but does not fail the run.
+	PolicySeverityWarn = "warn"
+)
+
+// PolicyCheckResult is a single result returned from a cost policy evaluation.
+// Resource and Project are optional and are used to point the user at what
+// caused the policy to fail. Policy is the name of the guardrail or Rego policy
+// that returned the result, if it is known.
+type PolicyCheckResult struct {
+	Policy      string
+	Message     string
+	Resource    string
+	Project     string
+	Severity    string
+	Remediation string
+}
+
+// String returns the result message prefixed by the project and resource it applies to.
+func (r PolicyCheckResult) String() string {
+	msg := r.Message
+	if r.Resource != "" {
+		msg = fmt.Sprintf("%s: %s", r.Resource, msg)
+	}
+
+	if r.Project != "" {
+		msg = fmt.Sprintf("[%s] %s", r.Project, msg)
+	}
+
+	return msg
+}
+
+// resourceSource returns the file and line where the resource of the result is
+// declared. These are read from the filename and startLine resource metadata,
+// so they are empty if the metadata was not recorded for the resource.
+func (r PolicyCheckResult) resourceSource(out Root) (string, int) {
+	if r.Resource == "" {
+		return "", 0
+	}
+
+	for _, p := range out.Projects {
+		if r.Project != "" && p.Name != r.Project {
+			continue
+		}
+
+		if p.Breakdown == nil {
+			continue
+		}
+
+		for _, res := range p.Breakdown.Resources {
+			if res.Name != r.Resource {
+				continue
+			}
+
+			line, _ := strconv.Atoi(res.Metadata["startLine"])
+
+			filename := res.Metadata["filename"]
+			if filename == "" {
+				return "", line
+			}
+
+			// The filename is relative to the project directory, so it's prefixed with the project path
+			// to make it relative to the repo like the project paths used by the other results.
+			dir := projectRelPath(p)
+			if path.Ext(dir) != "" {
+				dir = path.Dir(dir)
+			}
+
+			return path.Join(dir, filename), line
+		}
+	}
+
+	return "", 0
+}
+
+// projectRelPath returns the path of the project relative to the repo if it is known, otherwise the
+// path it was run with, e.g. the Terraform directory or plan JSON file.
+func projectRelPath(p Project) string {
+	if p.Metadata == nil {
+		return ""
+	}
+
+	if p.Metadata.VCSSubPath != "" {
+		return filepath.ToSlash(p.Metadata.VCSSubPath)
+	}
+
+	return filepath.ToSlash(strings.TrimPrefix(p.Metadata.Path, "./"))
+}
+
+// PolicyCheckResults is a list of policy results that can be grouped for output.
+type PolicyCheckResults []PolicyCheckResult
+
+// PolicyCheckResourceGroup holds the policy results for a single resource.
+// Results that are not tied to a resource are grouped under an empty Resource.
+type PolicyCheckResourceGroup struct {
+	Resource string
+	Results  PolicyCheckResults
+}
+
+// PolicyCheckProjectGroup holds the policy results for a single project grouped by resource.
+// Results that are not tied to a project are grouped under an empty Project.
+type PolicyCheckProjectGroup struct {
+	Project   string
+	Resources []PolicyCheckResourceGroup
+}
+
+// GroupByProject groups the results by project and then by resource, keeping
+// the order in which projects and resources were first seen.
+func (p PolicyCheckResults) GroupByProject() []PolicyCheckProjectGroup {
+	var groups []PolicyCheckProjectGroup
+	projectIndex := map[string]int{}
+	resourceIndex := map[string]map[string]int{}
+
+	for _, r := range p {
+		pi, ok := projectIndex[r.Project]
+		if !ok {
+			pi = len(groups)
+			projectIndex[r.Project] = pi
+			resourceIndex[r.Project] = map[string]int{}
+			groups = append(groups, PolicyCheckProjectGroup{Project: r.Project})
+		}
+
+		ri, ok := resourceIndex[r.Project][r.Resource]
+		if !ok {
+			ri = len(groups[pi].Resources)
+			resourceIndex[r.Project][r.Resource] = ri
+			groups[pi].Resources = append(groups[pi].Resources, PolicyCheckResourceGroup{Resource: r.Resource})
+		}
+
+		groups[pi].Resources[ri].Results = append(groups[pi].Resources[ri].Results, r)
+	}
+
+	// Results that don't belong to a project or resource are shown first so
+	// they aren't displayed under the heading of the previous group.
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Project == "" && groups[j].Project != ""
+	})
+	for _, g := range groups {
+		sort.SliceStable(g.Resources, func(i, j int) bool {
+			return g.Resources[i].Resource == "" && g.Resources[j].Resource != ""
+		})
+	}
+
+	return groups
+}
+
 // PolicyCheckFailures defines a list of policy check failures that can be collected from a policy evaluation.
-type PolicyCheckFailures []string
+type PolicyCheckFailures []PolicyCheckResult
+
+// GroupByProject groups the failures by project and then by resource.
+func (p PolicyCheckFailures) GroupByProject() []PolicyCheckProjectGroup {
+	return PolicyCheckResults(p).GroupByProject()
+}
 
 // Error implements the Error interface returning the failures as a single message that can be used in stderr.
 func (p PolicyCheckFailures) Error() string {
@@ -151,17 +356,92 @@ func (p PolicyCheckFailures) Error() string {
 	out := bytes.NewBuffer([]byte("Policy check failed:\n\n"))
 
 	for _, e := range p {
-		out.WriteString(e + "\n")
+		out.WriteString(e.String() + "\n")
+		if e.Remediation != "" {
+			out.WriteString("  Remediation: " + e.Remediation + "\n")
+		}
 	}
 
 	return out.String()
 }
 
+// policyChecksMessage returns a summary of the policy check results that can
+// be appended to the table and diff outputs.
+func policyChecksMessage(p PolicyCheck) string {
+	if !p.Enabled {
+		return ""
+	}
+
+	s := ""
+
+	if p.HasFailed() {
+		s += ui.ErrorString("Policy checks failed:") + "\n"
+		s += policyResultsMessage(p.Failures.GroupByProject(), "✖")
+	} else {
+		s += ui.SuccessString("Policy checks passed:") + "\n"
+		s += policyResultsMessage(p.Passed.GroupByProject(), "✔")
+	}
+
+	if p.HasWarnings() {
+		s += "\n" + ui.WarningString("Policy checks warnings:") + "\n"
+		s += policyResultsMessage(p.Warnings.GroupByProject(), "!")
+	}
+
+	return s
+}
+
+func policyResultsMessage(groups []PolicyCheckProjectGroup, symbol string) string {
+	s := ""
+
+	for _, g := range groups {
+		indent := "  "
+		if g.Project != "" {
+			s += fmt.Sprintf("  %s %s\n", ui.BoldString("Project:"), g.Project)
+			indent = "    "
+		}
+
+		for _, rg := range g.Resources {
+			resultIndent := indent
+			if rg.Resource != "" {
+				s += fmt.Sprintf("%s%s\n", indent, rg.Resource)
+				resultIndent += "  "
+			}
+
+			for _, r := range rg.Results {
+				s += fmt.Sprintf("%s%s %s\n", resultIndent, symbol, r.Message)
+				if r.Remediation != "" {
+					s += fmt.Sprintf("%s  %s\n", resultIndent, ui.FaintStringf("Remediation: %s", r.Remediation))
+				}
+			}
+		}
+	}
+
+	return s
+}
+
 type MarkdownOptions struct {
 	WillUpdate          bool
 	WillReplace         bool
 	IncludeFeedbackLink bool
 	BasicSyntax         bool
+	// Approvers are the users or groups that must approve cost increases above the ApprovalThreshold.
+	Approvers         []string
+	ApprovalThreshold *decimal.Decimal
+}
+
+// ApprovalRequired returns true if approvers are set and the total monthly cost
+// increase is greater than the approval threshold.
+func (o MarkdownOptions) ApprovalRequired(r Root) bool {
+	if len(o.Approvers) == 0 || r.DiffTotalMonthlyCost == nil {
+		return false
+	}
+
+	threshold := decimal.Zero
+	if o.ApprovalThreshold != nil {
+		threshold = *o.ApprovalThreshold
+	}
+
+	return r.DiffTotalMonthlyCost.GreaterThan(threshold)
 }
 
 func outputBreakdown(resources []*schema.Resource) *Breakdown {
@@ -190,13 +470,19 @@ func outputResource(r *schema.Resource) Resource {
 	for _, c := range r.CostComponents {
 
 		comps = append(comps, CostComponent{
-			Name:            c.Name,
-			Unit:            c.Unit,
-			HourlyQuantity:  c.UnitMultiplierHourlyQuantity(),
-			MonthlyQuantity: c.UnitMultiplierMonthlyQuantity(),
-			Price:           c.UnitMultiplierPrice(),
-			HourlyCost:      c.HourlyCost,
-			MonthlyCost:     c.MonthlyCost,
+			Name:                c.Name,
+			Unit:                c.Unit,
+			HourlyQuantity:      c.UnitMultiplierHourlyQuantity(),
+			MonthlyQuantity:     c.UnitMultiplierMonthlyQuantity(),
+			Price:               c.UnitMultiplierPrice(),
+			HourlyCost:          c.HourlyCost,
+			MonthlyCost:         c.MonthlyCost,
+			PriceHash:           c.PriceHash(),
+			ProductFilter:       c.ProductFilter,
+			PriceFilter:         c.PriceFilter,
+			MonthlyDiscountPerc: c.MonthlyDiscountPerc,
+			IsCustomPrice:       c.CustomPrice() != nil,
+			IsMissingPrice:      c.MissingPrice(),
 		})
 	}
 
@@ -205,17 +491,56 @@ func outputResource(r *schema.Resource) Resource {
 		subresources = append(subresources, outputResource(s))
 	}
 
+	metadata := make(map[string]string, len(r.Metadata))
+	for k, v := range r.Metadata {
+		metadata[k] = v
+	}
+
 	return Resource{
-		Name:           r.Name,
-		Metadata:       map[string]string{},
-		Tags:           r.Tags,
-		HourlyCost:     r.HourlyCost,
-		MonthlyCost:    r.MonthlyCost,
-		CostComponents: comps,
-		SubResources:   subresources,
+		Name:              r.Name,
+		MovedFrom:         r.MovedFrom,
+		Metadata:          metadata,
+		Tags:              r.Tags,
+		UnknownAttributes: r.UnknownAttributes,
+		Usage:             r.AppliedUsage,
+		HourlyCost:        r.HourlyCost,
+		MonthlyCost:       r.MonthlyCost,
+		CostComponents:    comps,
+		SubResources:      subresources,
 	}
 }
 
+// setResourceIDs sets the ID of the resources of the project that don't have one, e.g. resources loaded
+// from Infracost JSON files generated before IDs were added.
+func (p *Project) setResourceIDs() {
+	for _, b := range []*Breakdown{p.PastBreakdown, p.Breakdown, p.Diff} {
+		if b != nil {
+			setResourceIDs(p.Name, "", b.Resources)
+		}
+	}
+}
+
+func setResourceIDs(projectName, parentAddress string, resources []Resource) {
+	for i := range resources {
+		address := resources[i].Name
+		if parentAddress != "" {
+			address = parentAddress + "." + address
+		}
+
+		if resources[i].ID == "" {
+			resources[i].ID = resourceID(projectName, address)
+		}
+
+		setResourceIDs(projectName, address, resources[i].SubResources)
+	}
+}
+
+// resourceID returns a stable ID for the resource address in the project.
+func resourceID(projectName, address string) string {
+	h := sha256.Sum256([]byte(projectName + "\x00" + address))
+	return hex.EncodeToString(h[:16])
+}
+
 func ToOutputFormat(projects []*schema.Project) (Root, error) {
 	var totalMonthlyCost, totalHourlyCost,
 		pastTotalMonthlyCost, pastTotalHourlyCost,
@@ -305,7 +630,7 @@ func ToOutputFormat(projects []*schema.Project) (Root, error) {
 		}
 		fullSummaries = append(fullSummaries, fullSummary)
 
-		outProjects = append(outProjects, Project{
+		outProject := Project{
 			Name:          project.Name,
 			Metadata:      project.Metadata,
 			PastBreakdown: pastBreakdown,
@@ -313,7 +638,10 @@ func ToOutputFormat(projects []*schema.Project) (Root, error) {
 			Diff:          diff,
 			Summary:       summary,
 			fullSummary:   fullSummary,
-		})
+		}
+		outProject.setResourceIDs()
+
+		outProjects = append(outProjects, outProject)
 	}
 
 	out := Root{
@@ -634,6 +962,22 @@ func sortResources(resources []Resource, groupKey string) {
 	})
 }
 
+// ResourceType returns the resource type from a resource address, e.g.
+// module.web["a"].aws_instance.web[0] returns aws_instance.
+func ResourceType(address string) string {
+	parts := terraform.SplitAddress(address)
+	if len(parts) < 2 {
+		return ""
+	}
+
+	t := parts[len(parts)-2]
+	if i := strings.Index(t, "["); i != -1 {
+		t = t[:i]
+	}
+
+	return t
+}
+
 func contains(arr []string, e string) bool {
 	for _, a := range arr {
 		if a == e {
@@ -5,6 +5,9 @@ import (
 
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/infracost/infracost/internal/schema"
 )
 
 func TestCalculateTotalCosts(t *testing.T) {
@@ -32,3 +35,118 @@ func TestCalculateTotalCosts(t *testing.T) {
 	actual, _ = totalMonthlyCost.Float64()
 	assert.Equal(t, expected, actual)
 }
+
+func TestMarkdownOptionsApprovalRequired(t *testing.T) {
+	r := Root{DiffTotalMonthlyCost: decimalPtr(decimal.NewFromInt(100))}
+
+	assert.False(t, MarkdownOptions{}.ApprovalRequired(r))
+	assert.True(t, MarkdownOptions{Approvers: []string{"alice"}}.ApprovalRequired(r))
+	assert.True(t, MarkdownOptions{Approvers: []string{"alice"}, ApprovalThreshold: decimalPtr(decimal.NewFromInt(50))}.ApprovalRequired(r))
+	assert.False(t, MarkdownOptions{Approvers: []string{"alice"}, ApprovalThreshold: decimalPtr(decimal.NewFromInt(100))}.ApprovalRequired(r))
+	assert.False(t, MarkdownOptions{Approvers: []string{"alice"}}.ApprovalRequired(Root{}))
+}
+
+func TestUnknownAttributesMessage(t *testing.T) {
+	out := Root{
+		Projects: []Project{
+			{
+				Breakdown: &Breakdown{
+					Resources: []Resource{
+						{Name: "aws_instance.web", UnknownAttributes: []string{"instance_type"}},
+						{Name: "aws_instance.db"},
+					},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, "* 1 resource has attributes that could not be resolved from the Terraform HCL, so their costs may be inaccurate.\nRerun with --format json to see the unknownAttributes of each resource.", unknownAttributesMessage(out))
+
+	out.Projects[0].Breakdown.Resources[1].UnknownAttributes = []string{"allocated_storage"}
+	assert.Contains(t, unknownAttributesMessage(out), "* 2 resources have attributes")
+
+	assert.Equal(t, "", unknownAttributesMessage(Root{}))
+}
+
+func TestResourceType(t *testing.T) {
+	tests := map[string]string{
+		"aws_instance.web":                             "aws_instance",
+		"aws_instance.web[0]":                          "aws_instance",
+		`module.web["a.b"].aws_instance.web["c.d"]`:    "aws_instance",
+		"module.web.module.db.aws_db_instance.primary": "aws_db_instance",
+		"data.aws_ami.ubuntu":                          "aws_ami",
+	}
+
+	for address, expected := range tests {
+		assert.Equal(t, expected, ResourceType(address), address)
+	}
+}
+
+func TestOutputResourceCostComponentFields(t *testing.T) {
+	region := "us-east-1"
+	purchaseOption := "on_demand"
+
+	custom := &schema.CostComponent{
+		Name:                "Instance usage",
+		UnitMultiplier:      decimal.NewFromInt(1),
+		ProductFilter:       &schema.ProductFilter{Region: &region},
+		PriceFilter:         &schema.PriceFilter{PurchaseOption: &purchaseOption},
+		MonthlyDiscountPerc: 0.1,
+	}
+	customPrice := decimal.NewFromInt(2)
+	custom.SetCustomPrice(&customPrice)
+	custom.SetPrice(customPrice)
+
+	fromAPI := &schema.CostComponent{Name: "Storage", UnitMultiplier: decimal.NewFromInt(1)}
+	fromAPI.SetPriceHash("abc123")
+
+	missing := &schema.CostComponent{Name: "IOPS", UnitMultiplier: decimal.NewFromInt(1)}
+	missing.SetMissingPrice(true)
+
+	r := outputResource(&schema.Resource{
+		Name:           "aws_instance.web",
+		CostComponents: []*schema.CostComponent{custom, fromAPI, missing},
+		AppliedUsage:   map[string]interface{}{"operating_system": "linux"},
+	})
+
+	assert.Equal(t, map[string]interface{}{"operating_system": "linux"}, r.Usage)
+
+	assert.True(t, r.CostComponents[0].IsCustomPrice)
+	assert.Equal(t, 0.1, r.CostComponents[0].MonthlyDiscountPerc)
+	assert.Equal(t, &region, r.CostComponents[0].ProductFilter.Region)
+	assert.Equal(t, &purchaseOption, r.CostComponents[0].PriceFilter.PurchaseOption)
+
+	assert.Equal(t, "abc123", r.CostComponents[1].PriceHash)
+	assert.False(t, r.CostComponents[1].IsCustomPrice)
+	assert.False(t, r.CostComponents[1].IsMissingPrice)
+
+	assert.True(t, r.CostComponents[2].IsMissingPrice)
+}
+
+func TestLoadSetsResourceIDs(t *testing.T) {
+	// Version 0.2 files don't have resource IDs
+	out, err := Load([]byte(`{
+  "version": "0.2",
+  "currency": "USD",
+  "projects": [
+    {
+      "name": "infracost/infracost/examples/terraform",
+      "breakdown": {
+        "resources": [
+          {"name": "aws_instance.web", "subresources": [{"name": "root_block_device"}]},
+          {"id": "existing", "name": "aws_instance.db"}
+        ]
+      }
+    }
+  ]
+}`))
+	require.NoError(t, err)
+
+	resources := out.Projects[0].Breakdown.Resources
+	assert.Equal(t, resourceID("infracost/infracost/examples/terraform", "aws_instance.web"), resources[0].ID)
+	assert.Len(t, resources[0].ID, 32)
+	assert.Equal(t, resourceID("infracost/infracost/examples/terraform", "aws_instance.web.root_block_device"), resources[0].SubResources[0].ID)
+	assert.Equal(t, "existing", resources[1].ID)
+
+	assert.NotEqual(t, resourceID("infracost/infracost/examples/terraform", "aws_instance.web"), resourceID("other", "aws_instance.web"))
+}
@@ -0,0 +1,204 @@
+package output
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/infracost/infracost/internal/version"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Version        string      `json:"version,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// defaultPolicyName is used for the results of Rego policies that don't set a policy name.
+const defaultPolicyName = "Cost policy"
+
+var policyIDReplaceReg = regexp.MustCompile(`[^a-z0-9]+`)
+
+// policyName returns the name of the policy that returned the result.
+func policyName(r PolicyCheckResult) string {
+	if r.Policy == "" {
+		return defaultPolicyName
+	}
+
+	return r.Policy
+}
+
+// policyID returns an ID for the policy that can be used as a SARIF rule ID, e.g. Max cost increase
+// becomes infracost/max-cost-increase.
+func policyID(r PolicyCheckResult) string {
+	id := strings.Trim(policyIDReplaceReg.ReplaceAllString(strings.ToLower(policyName(r)), "-"), "-")
+	return "infracost/" + id
+}
+
+// ToSARIF returns the failed and warned policy results in the SARIF format so they can be uploaded to
+// code scanning tools such as GitHub code scanning. There is a result for each policy and resource. Passed
+// policies aren't included since code scanning tools only show problems.
+func ToSARIF(out Root, opts Options) ([]byte, error) {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           "Infracost",
+				InformationURI: "https://www.infracost.io",
+				Version:        version.Version,
+				Rules:          []sarifRule{},
+			},
+		},
+		Results: []sarifResult{},
+	}
+
+	ruleIndexes := map[string]int{}
+
+	addResults := func(results []PolicyCheckResult, level string) {
+		for _, r := range results {
+			id := policyID(r)
+
+			i, ok := ruleIndexes[id]
+			if !ok {
+				i = len(run.Tool.Driver.Rules)
+				ruleIndexes[id] = i
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+					ID:               id,
+					Name:             policyName(r),
+					ShortDescription: sarifMessage{Text: policyName(r)},
+				})
+			}
+
+			msg := r.String()
+			if r.Remediation != "" {
+				msg += "\nRemediation: " + r.Remediation
+			}
+
+			run.Results = append(run.Results, sarifResult{
+				RuleID:    id,
+				RuleIndex: i,
+				Level:     level,
+				Message:   sarifMessage{Text: msg},
+				Locations: sarifLocations(out, r),
+			})
+		}
+	}
+
+	addResults(opts.PolicyChecks.Failures, "error")
+	addResults(opts.PolicyChecks.Warnings, "warning")
+
+	return json.MarshalIndent(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	}, "", "  ")
+}
+
+// sarifLocations returns the location of the resource of the result. The file and line are only set
+// if they were recorded in the resource metadata, otherwise the project path is used as the file.
+func sarifLocations(out Root, r PolicyCheckResult) []sarifLocation {
+	var loc sarifLocation
+
+	filename, line := r.resourceSource(out)
+	if filename == "" {
+		filename = projectPath(out, r.Project)
+	}
+
+	if filename != "" {
+		loc.PhysicalLocation = &sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: filename},
+		}
+
+		if line > 0 {
+			loc.PhysicalLocation.Region = &sarifRegion{StartLine: line}
+		}
+	}
+
+	if r.Resource != "" {
+		loc.LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: r.Resource, Kind: "resource"}}
+	}
+
+	if loc.PhysicalLocation == nil && loc.LogicalLocations == nil {
+		return nil
+	}
+
+	return []sarifLocation{loc}
+}
+
+// projectPath returns the relative path of the project, e.g. the Terraform directory or plan JSON file.
+func projectPath(out Root, name string) string {
+	if name == "" {
+		return ""
+	}
+
+	for _, p := range out.Projects {
+		if p.Name != name || p.Metadata == nil {
+			continue
+		}
+
+		return projectRelPath(p)
+	}
+
+	return ""
+}
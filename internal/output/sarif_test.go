@@ -0,0 +1,98 @@
+package output
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/infracost/infracost/internal/schema"
+)
+
+func policyTestRoot() Root {
+	return Root{
+		Projects: []Project{
+			{
+				Name:     "infra/prod",
+				Metadata: &schema.ProjectMetadata{Path: "./infra/prod"},
+				Breakdown: &Breakdown{
+					Resources: []Resource{
+						{Name: "aws_instance.web", Metadata: map[string]string{"filename": "main.tf", "startLine": "12"}},
+						{Name: "aws_nat_gateway.main", Metadata: map[string]string{}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func policyTestOptions() Options {
+	return Options{
+		PolicyChecks: PolicyCheck{
+			Enabled: true,
+			Failures: PolicyCheckFailures{
+				{Policy: "prod", Message: "prod: Monthly cost exceeds the maximum", Resource: "aws_instance.web", Project: "infra/prod", Severity: PolicySeverityError, Remediation: "Use a smaller instance"},
+			},
+			Warnings: PolicyCheckResults{
+				{Policy: "tags", Message: "tags: Resource is missing required tags: team", Resource: "aws_nat_gateway.main", Project: "infra/prod", Severity: PolicySeverityWarn},
+			},
+			Passed: PolicyCheckResults{
+				{Message: "Cost increase is within budget", Severity: PolicySeverityError},
+			},
+		},
+	}
+}
+
+func TestPolicyID(t *testing.T) {
+	assert.Equal(t, "infracost/max-cost-increase", policyID(PolicyCheckResult{Policy: "Max cost increase!"}))
+	assert.Equal(t, "infracost/cost-policy", policyID(PolicyCheckResult{}))
+}
+
+func TestToSARIF(t *testing.T) {
+	b, err := ToSARIF(policyTestRoot(), policyTestOptions())
+	require.NoError(t, err)
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(b, &log))
+
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+
+	assert.Equal(t, []sarifRule{
+		{ID: "infracost/prod", Name: "prod", ShortDescription: sarifMessage{Text: "prod"}},
+		{ID: "infracost/tags", Name: "tags", ShortDescription: sarifMessage{Text: "tags"}},
+	}, run.Tool.Driver.Rules)
+
+	assert.Equal(t, []sarifResult{
+		{
+			RuleID:    "infracost/prod",
+			RuleIndex: 0,
+			Level:     "error",
+			Message:   sarifMessage{Text: "[infra/prod] aws_instance.web: prod: Monthly cost exceeds the maximum\nRemediation: Use a smaller instance"},
+			Locations: []sarifLocation{
+				{
+					PhysicalLocation: &sarifPhysicalLocation{
+						ArtifactLocation: sarifArtifactLocation{URI: "infra/prod/main.tf"},
+						Region:           &sarifRegion{StartLine: 12},
+					},
+					LogicalLocations: []sarifLogicalLocation{{FullyQualifiedName: "aws_instance.web", Kind: "resource"}},
+				},
+			},
+		},
+		{
+			RuleID:    "infracost/tags",
+			RuleIndex: 1,
+			Level:     "warning",
+			Message:   sarifMessage{Text: "[infra/prod] aws_nat_gateway.main: tags: Resource is missing required tags: team"},
+			Locations: []sarifLocation{
+				{
+					PhysicalLocation: &sarifPhysicalLocation{
+						ArtifactLocation: sarifArtifactLocation{URI: "infra/prod"},
+					},
+					LogicalLocations: []sarifLogicalLocation{{FullyQualifiedName: "aws_nat_gateway.main", Kind: "resource"}},
+				},
+			},
+		},
+	}, run.Results)
+}
@@ -55,7 +55,7 @@ func ToTable(out Root, opts Options) ([]byte, error) {
 		s += "\n"
 	}
 
-	totalOut := formatCost2DP(out.Currency, out.TotalMonthlyCost)
+	totalOut := formatCost2DP(out.Currency, out.TotalMonthlyCost) + formatSecondaryCost(out, out.TotalMonthlyCost)
 
 	overallTitle := formatTitleWithCurrency(" OVERALL TOTAL", out.Currency)
 	s += fmt.Sprintf("%s%s",
@@ -69,9 +69,53 @@ func ToTable(out Root, opts Options) ([]byte, error) {
 		s += "\n──────────────────────────────────\n" + summaryMsg
 	}
 
+	if unknownMsg := unknownAttributesMessage(out); unknownMsg != "" {
+		s += "\n──────────────────────────────────\n" + unknownMsg
+	}
+
+	if policyMsg := policyChecksMessage(opts.PolicyChecks); policyMsg != "" {
+		s += "\n──────────────────────────────────\n" + policyMsg
+	}
+
 	return []byte(s), nil
 }
 
+// unknownAttributesMarker is shown next to resources that have attributes that could not be resolved,
+// to show that their costs might not be accurate.
+var unknownAttributesMarker = "*"
+
+// unknownAttributesMessage returns a message explaining the unknownAttributesMarker, or an empty string
+// if no resources have unknown attributes.
+func unknownAttributesMessage(out Root) string {
+	count := 0
+
+	for _, project := range out.Projects {
+		if project.Breakdown == nil {
+			continue
+		}
+
+		for _, r := range project.Breakdown.Resources {
+			if len(r.UnknownAttributes) > 0 {
+				count++
+			}
+		}
+	}
+
+	if count == 0 {
+		return ""
+	}
+
+	resources := "1 resource has"
+	if count > 1 {
+		resources = fmt.Sprintf("%d resources have", count)
+	}
+
+	return fmt.Sprintf("%s %s attributes that could not be resolved from the Terraform HCL, so their costs may be inaccurate.\nRerun with --format json to see the unknownAttributes of each resource.",
+		ui.WarningString(unknownAttributesMarker),
+		resources,
+	)
+}
+
 func tableForBreakdown(currency string, breakdown Breakdown, fields []string, includeTotal bool) string {
 	t := table.NewWriter()
 	t.Style().Options.DrawBorder = false
@@ -154,7 +198,12 @@ func tableForBreakdown(currency string, breakdown Breakdown, fields []string, in
 			continue
 		}
 
-		t.AppendRow(table.Row{ui.BoldString(r.Name)})
+		name := ui.BoldString(r.Name)
+		if len(r.UnknownAttributes) > 0 {
+			name += " " + ui.WarningString(unknownAttributesMarker)
+		}
+
+		t.AppendRow(table.Row{name})
 
 		buildCostComponentRows(t, currency, filteredComponents, "", len(r.SubResources) > 0, fields)
 		buildSubResourceRows(t, currency, filteredSubResources, "", fields)
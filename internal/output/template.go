@@ -0,0 +1,109 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"text/template"
+
+	"github.com/Masterminds/sprig"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+
+	"github.com/infracost/infracost/internal/ui"
+)
+
+// templateFuncs returns the helper functions that are available to the built-in templates and to the
+// templates set with Options.TemplatePath, in addition to the sprig functions:
+//
+//	formatCost <cost>                               cost rounded to whole units, e.g. $1,234
+//	formatCost2DP <cost>                            cost with 2 decimal places, e.g. $1,234.56
+//	formatCostChange <pastCost> <cost>              cost change with a percentage, e.g. +$100 (+25%)
+//	formatCostChangeSentence <currency> <pastCost> <cost> <useEmoji>
+//	                                                e.g. monthly cost will increase by $100 (+25%)
+//	formatPrice <price>                             unit price with the currency, e.g. $0.096
+//	formatQuantity <quantity>                       quantity with thousands separators
+//	formatTitleWithCurrency <title>                 adds the currency to a title if it's not USD
+//	hasDiff <project>                               true if the project has resource changes
+//	projectLabel <project>                          name of the project shown in the outputs
+//	truncateMiddle <string> <maxLength> <fill>      shortens a string by replacing its middle with fill
+//	stripColor <string>                             removes the terminal color codes from a string
+func templateFuncs(out Root, opts Options) map[string]interface{} {
+	return map[string]interface{}{
+		"formatCost": func(d *decimal.Decimal) string {
+			if d == nil || d.IsZero() {
+				return formatWholeDecimalCurrency(out.Currency, decimal.Zero)
+			}
+			return formatCost(out.Currency, d)
+		},
+		"formatCost2DP": func(d *decimal.Decimal) string { return formatCost2DP(out.Currency, d) },
+		"formatCostChange": func(pastCost, cost *decimal.Decimal) string {
+			return formatMarkdownCostChange(out.Currency, pastCost, cost, false)
+		},
+		"formatCostChangeSentence": formatCostChangeSentence,
+		"formatPrice":              func(d decimal.Decimal) string { return formatPrice(out.Currency, d) },
+		"formatQuantity":           formatQuantity,
+		"formatTitleWithCurrency":  func(title string) string { return formatTitleWithCurrency(title, out.Currency) },
+		"hasDiff": func(p Project) bool {
+			if p.Diff == nil || len(p.Diff.Resources) == 0 {
+				return false
+			}
+			return true
+		},
+		"projectLabel": func(p Project) string {
+			return p.Label(opts.DashboardEnabled)
+		},
+		"truncateMiddle": truncateMiddle,
+		"stripColor":     ui.StripColor,
+	}
+}
+
+// readTemplate returns the contents of the template file set with Options.TemplatePath.
+func readTemplate(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "Error reading template file %s", path)
+	}
+
+	return string(b), nil
+}
+
+// templateError wraps errors from parsing or executing a template. The template is named after its path,
+// so the error already contains the file and line of the problem, e.g. template: custom.tmpl:12: ...
+func templateError(err error) error {
+	return fmt.Errorf("Invalid template: %w", err)
+}
+
+// ToText renders the template set with Options.TemplatePath as plain text, e.g. for a custom summary
+// printed by CI pipelines.
+func ToText(out Root, opts Options) ([]byte, error) {
+	t, err := readTemplate(opts.TemplatePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	bufw := bufio.NewWriter(&buf)
+
+	tmpl := template.New(opts.TemplatePath)
+	tmpl.Funcs(sprig.TxtFuncMap())
+	tmpl.Funcs(templateFuncs(out, opts))
+
+	tmpl, err = tmpl.Parse(t)
+	if err != nil {
+		return nil, templateError(err)
+	}
+
+	err = tmpl.Execute(bufw, struct {
+		Root           Root
+		SummaryMessage string
+		Options        Options
+	}{out, out.summaryMessage(opts.ShowSkipped), opts})
+	if err != nil {
+		return nil, templateError(err)
+	}
+
+	bufw.Flush()
+	return buf.Bytes(), nil
+}
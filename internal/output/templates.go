@@ -114,6 +114,584 @@ table.overall-total {
   margin-top: 1rem;
 }
 
+.controls {
+  display: flex;
+  flex-wrap: wrap;
+  gap: 0.5rem 1rem;
+  align-items: center;
+  margin-bottom: 1rem;
+}
+
+.controls label {
+  font-size: 0.875rem;
+}
+
+.treemap {
+  position: relative;
+  height: 16rem;
+  margin-bottom: 1rem;
+  border: 1px solid #6b7280;
+}
+
+.treemap .tile {
+  position: absolute;
+  box-sizing: border-box;
+  border: 1px solid #ffffff;
+  overflow: hidden;
+  padding: 0.25rem;
+  font-size: 0.75rem;
+  color: #ffffff;
+  cursor: pointer;
+}
+
+.treemap .project-tile {
+  border: 2px solid #111827;
+}
+
+.report-summary {
+  margin-bottom: 0.5rem;
+}
+
+table.resources {
+  min-width: 946px;
+}
+
+table.resources th {
+  cursor: pointer;
+  user-select: none;
+  background-color: #6b7280;
+  color: #ffffff;
+}
+
+table.resources td.cost, table.resources th.cost {
+  text-align: right;
+}
+
+table.resources tr.resource-row:nth-child(4n+2) {
+  background-color: #f3f4f6;
+}
+
+table.resources tr.added td.diff {
+  color: #b91c1c;
+}
+
+table.resources tr.removed td.diff {
+  color: #15803d;
+}
+
+table.resources tr.removed td.name {
+  text-decoration: line-through;
+}
+
+table.resources button.toggle {
+  border: none;
+  background: none;
+  cursor: pointer;
+  width: 1.5rem;
+}
+
+table.details {
+  margin: 0.25rem 0 0.5rem 1.5rem;
+  border-color: #d1d5db;
+}
+
+details.subresource {
+  margin-left: 1.5rem;
+}
+
+.tags {
+  color: #6b7280;
+  font-size: 0.75rem;
+}
+
+{{end}}
+
+{{define "script"}}
+(function () {
+  var dataEl = document.getElementById("report-data");
+  var reportEl = document.getElementById("report");
+  if (!dataEl || !reportEl) {
+    return;
+  }
+
+  var data = JSON.parse(dataEl.textContent);
+  var resources = data.resources || [];
+  var fields = data.fields || ["monthlyQuantity", "unit", "monthlyCost"];
+
+  var state = {
+    project: "",
+    type: "",
+    tag: "",
+    name: "",
+    changesOnly: false,
+    sortKey: "monthlyCost",
+    sortDesc: true,
+    expanded: {}
+  };
+
+  var columns = [
+    { key: "name", title: "Resource" },
+    { key: "project", title: "Project" },
+    { key: "resourceType", title: "Type" },
+    { key: "monthlyCost", title: "Monthly cost", cost: true }
+  ];
+  if (data.hasDiff) {
+    columns.push({ key: "pastMonthlyCost", title: "Previous monthly cost", cost: true });
+    columns.push({ key: "diffMonthlyCost", title: "Monthly cost change", cost: true, diff: true });
+  }
+
+  var currencyFormat = null;
+  try {
+    currencyFormat = new Intl.NumberFormat("en-US", { style: "currency", currency: data.currency || "USD" });
+  } catch (e) {
+    currencyFormat = null;
+  }
+
+  function toNumber(v) {
+    if (v === undefined || v === null || v === "") {
+      return null;
+    }
+    return parseFloat(v);
+  }
+
+  function formatCost(v) {
+    if (v === null) {
+      return "-";
+    }
+    if (currencyFormat) {
+      return currencyFormat.format(v);
+    }
+    return v.toFixed(2) + " " + (data.currency || "USD");
+  }
+
+  function formatDiff(v) {
+    if (v === null) {
+      return "-";
+    }
+    if (v > 0) {
+      return "+" + formatCost(v);
+    }
+    if (v < 0) {
+      return "-" + formatCost(-v);
+    }
+    return formatCost(0);
+  }
+
+  function formatNumber(v) {
+    if (v === null) {
+      return "";
+    }
+    return v.toLocaleString("en-US", { maximumFractionDigits: 4 });
+  }
+
+  function el(tag, className, text) {
+    var e = document.createElement(tag);
+    if (className) {
+      e.className = className;
+    }
+    if (text !== undefined && text !== null) {
+      e.textContent = text;
+    }
+    return e;
+  }
+
+  function clear(e) {
+    while (e.firstChild) {
+      e.removeChild(e.firstChild);
+    }
+  }
+
+  var rows = resources.map(function (r, i) {
+    return {
+      index: i,
+      resource: r,
+      name: r.name,
+      project: r.project || "",
+      resourceType: r.resourceType || "",
+      monthlyCost: toNumber(r.monthlyCost),
+      pastMonthlyCost: toNumber(r.pastMonthlyCost),
+      diffMonthlyCost: toNumber(r.diffMonthlyCost)
+    };
+  });
+
+  function unique(key) {
+    var seen = {};
+    var values = [];
+    rows.forEach(function (row) {
+      var v = row[key];
+      if (v && !seen[v]) {
+        seen[v] = true;
+        values.push(v);
+      }
+    });
+    return values.sort();
+  }
+
+  function addOptions(select, values) {
+    values.forEach(function (v) {
+      var opt = el("option", "", v);
+      opt.value = v;
+      select.appendChild(opt);
+    });
+  }
+
+  // matchesTag matches a "key" filter against the tag keys and a "key=value" filter against the tags.
+  function matchesTag(row, filter) {
+    if (!filter) {
+      return true;
+    }
+    var tags = row.resource.tags || {};
+    var i = filter.indexOf("=");
+    if (i === -1) {
+      return Object.prototype.hasOwnProperty.call(tags, filter);
+    }
+    var key = filter.substring(0, i);
+    return Object.prototype.hasOwnProperty.call(tags, key) && tags[key] === filter.substring(i + 1);
+  }
+
+  function filteredRows() {
+    var name = state.name.toLowerCase();
+    return rows.filter(function (row) {
+      if (state.project && row.project !== state.project) {
+        return false;
+      }
+      if (state.type && row.resourceType !== state.type) {
+        return false;
+      }
+      if (name && row.name.toLowerCase().indexOf(name) === -1) {
+        return false;
+      }
+      if (state.changesOnly && (row.diffMonthlyCost === null || row.diffMonthlyCost === 0)) {
+        return false;
+      }
+      return matchesTag(row, state.tag.trim());
+    });
+  }
+
+  function sortRows(list) {
+    var col = columns.filter(function (c) { return c.key === state.sortKey; })[0];
+    return list.slice().sort(function (a, b) {
+      var x = a[state.sortKey];
+      var y = b[state.sortKey];
+      var cmp;
+      if (col && col.cost) {
+        x = x === null ? -Infinity : x;
+        y = y === null ? -Infinity : y;
+        cmp = x < y ? -1 : (x > y ? 1 : 0);
+      } else {
+        cmp = String(x).localeCompare(String(y));
+      }
+      if (cmp === 0) {
+        return a.index - b.index;
+      }
+      return state.sortDesc ? -cmp : cmp;
+    });
+  }
+
+  function rowClass(row) {
+    if (!data.hasDiff) {
+      return "";
+    }
+    if (row.resource.movedFrom) {
+      return "moved";
+    }
+    if (row.pastMonthlyCost === null && row.monthlyCost !== null) {
+      return "added";
+    }
+    if (row.monthlyCost === null && row.pastMonthlyCost !== null) {
+      return "removed";
+    }
+    if (row.diffMonthlyCost !== null && row.diffMonthlyCost !== 0) {
+      return "changed";
+    }
+    return "";
+  }
+
+  function costComponentsTable(components) {
+    var table = el("table", "details");
+    var head = el("tr");
+    head.appendChild(el("th", "name", "Cost component"));
+    if (fields.indexOf("monthlyQuantity") !== -1) {
+      head.appendChild(el("th", "monthly-quantity", "Monthly qty"));
+    }
+    if (fields.indexOf("unit") !== -1) {
+      head.appendChild(el("th", "unit", "Unit"));
+    }
+    if (fields.indexOf("price") !== -1) {
+      head.appendChild(el("th", "price", "Price"));
+    }
+    if (fields.indexOf("hourlyCost") !== -1) {
+      head.appendChild(el("th", "hourly-cost", "Hourly cost"));
+    }
+    if (fields.indexOf("monthlyCost") !== -1) {
+      head.appendChild(el("th", "monthly-cost", "Monthly cost"));
+    }
+    table.appendChild(head);
+
+    components.forEach(function (c) {
+      var tr = el("tr");
+      tr.appendChild(el("td", "name", c.name));
+      if (fields.indexOf("monthlyQuantity") !== -1) {
+        tr.appendChild(el("td", "monthly-quantity", formatNumber(toNumber(c.monthlyQuantity))));
+      }
+      if (fields.indexOf("unit") !== -1) {
+        tr.appendChild(el("td", "unit", c.unit));
+      }
+      if (fields.indexOf("price") !== -1) {
+        tr.appendChild(el("td", "price", formatNumber(toNumber(c.price))));
+      }
+      if (fields.indexOf("hourlyCost") !== -1) {
+        tr.appendChild(el("td", "hourly-cost", formatCost(toNumber(c.hourlyCost))));
+      }
+      if (fields.indexOf("monthlyCost") !== -1) {
+        tr.appendChild(el("td", "monthly-cost", formatCost(toNumber(c.monthlyCost))));
+      }
+      table.appendChild(tr);
+    });
+
+    return table;
+  }
+
+  function resourceDetails(r) {
+    var container = el("div");
+    if (r.tags && Object.keys(r.tags).length > 0) {
+      container.appendChild(el("div", "tags", Object.keys(r.tags).sort().map(function (k) {
+        return k + "=" + r.tags[k];
+      }).join(", ")));
+    }
+    if (r.costComponents && r.costComponents.length > 0) {
+      container.appendChild(costComponentsTable(r.costComponents));
+    }
+    (r.subresources || []).forEach(function (sub) {
+      var details = el("details", "subresource");
+      details.appendChild(el("summary", "", sub.name + " (" + formatCost(toNumber(sub.monthlyCost)) + ")"));
+      details.appendChild(resourceDetails(sub));
+      container.appendChild(details);
+    });
+    return container;
+  }
+
+  function hasDetails(r) {
+    return (r.costComponents && r.costComponents.length > 0) || (r.subresources && r.subresources.length > 0);
+  }
+
+  function renderTable(list) {
+    var table = document.getElementById("resources");
+    clear(table);
+
+    var head = el("tr");
+    columns.forEach(function (col) {
+      var title = col.title;
+      if (state.sortKey === col.key) {
+        title += state.sortDesc ? " ▼" : " ▲";
+      }
+      var th = el("th", col.cost ? "cost" : "", title);
+      th.addEventListener("click", function () {
+        if (state.sortKey === col.key) {
+          state.sortDesc = !state.sortDesc;
+        } else {
+          state.sortKey = col.key;
+          state.sortDesc = !!col.cost;
+        }
+        render();
+      });
+      head.appendChild(th);
+    });
+    table.appendChild(head);
+
+    list.forEach(function (row) {
+      var tr = el("tr", ("resource-row " + rowClass(row)).trim());
+      columns.forEach(function (col) {
+        var td;
+        if (col.key === "name") {
+          td = el("td", "name");
+          var toggle = el("button", "toggle", hasDetails(row.resource) ? (state.expanded[row.index] ? "▾" : "▸") : "");
+          toggle.type = "button";
+          toggle.addEventListener("click", function () {
+            state.expanded[row.index] = !state.expanded[row.index];
+            render();
+          });
+          td.appendChild(toggle);
+          td.appendChild(document.createTextNode(row.name));
+          if (data.hasDiff && row.resource.movedFrom) {
+            td.appendChild(el("span", "tags", " (moved from " + row.resource.movedFrom + ")"));
+          }
+        } else if (col.diff) {
+          td = el("td", "cost diff", formatDiff(row[col.key]));
+        } else if (col.cost) {
+          td = el("td", "cost", formatCost(row[col.key]));
+        } else {
+          td = el("td", "", row[col.key]);
+        }
+        tr.appendChild(td);
+      });
+      table.appendChild(tr);
+
+      var detailsRow = el("tr", "details-row");
+      if (state.expanded[row.index] && hasDetails(row.resource)) {
+        var td = el("td");
+        td.colSpan = columns.length;
+        td.appendChild(resourceDetails(row.resource));
+        detailsRow.appendChild(td);
+      } else {
+        detailsRow.hidden = true;
+      }
+      table.appendChild(detailsRow);
+    });
+  }
+
+  function sum(list, key) {
+    var total = null;
+    list.forEach(function (row) {
+      if (row[key] !== null) {
+        total = (total || 0) + row[key];
+      }
+    });
+    return total;
+  }
+
+  function renderSummary(list) {
+    var summary = document.getElementById("report-summary");
+    var text = "Showing " + list.length + " of " + rows.length + " resources, monthly cost " + formatCost(sum(list, "monthlyCost"));
+    if (data.hasDiff) {
+      text += ", monthly cost change " + formatDiff(sum(list, "diffMonthlyCost"));
+    }
+    summary.textContent = text;
+  }
+
+  function tileColor(name) {
+    var hash = 0;
+    for (var i = 0; i < name.length; i++) {
+      hash = (hash * 31 + name.charCodeAt(i)) % 360;
+    }
+    return "hsl(" + hash + ", 45%, 45%)";
+  }
+
+  // renderTreemap lays out the projects side by side with a width proportional to their monthly cost,
+  // and splits each project by resource type with a height proportional to the resource type costs.
+  function renderTreemap(list) {
+    var treemap = document.getElementById("treemap");
+    clear(treemap);
+
+    var projects = [];
+    var projectIndexes = {};
+    var total = 0;
+    list.forEach(function (row) {
+      if (row.monthlyCost === null || row.monthlyCost <= 0) {
+        return;
+      }
+      var i = projectIndexes[row.project];
+      if (i === undefined) {
+        i = projects.length;
+        projectIndexes[row.project] = i;
+        projects.push({ name: row.project, cost: 0, types: {} });
+      }
+      var p = projects[i];
+      p.cost += row.monthlyCost;
+      p.types[row.resourceType] = (p.types[row.resourceType] || 0) + row.monthlyCost;
+      total += row.monthlyCost;
+    });
+
+    treemap.hidden = total === 0;
+    if (total === 0) {
+      return;
+    }
+
+    projects.sort(function (a, b) { return b.cost - a.cost; });
+
+    var left = 0;
+    projects.forEach(function (p) {
+      var width = p.cost * 100 / total;
+      var projectTile = el("div", "tile project-tile");
+      projectTile.style.left = left + "%";
+      projectTile.style.top = "0";
+      projectTile.style.width = width + "%";
+      projectTile.style.height = "100%";
+      treemap.appendChild(projectTile);
+
+      var types = Object.keys(p.types).sort(function (a, b) { return p.types[b] - p.types[a]; });
+      var top = 0;
+      types.forEach(function (t) {
+        var height = p.types[t] * 100 / p.cost;
+        var tile = el("div", "tile");
+        tile.style.left = left + "%";
+        tile.style.top = top + "%";
+        tile.style.width = width + "%";
+        tile.style.height = height + "%";
+        tile.style.backgroundColor = tileColor(t);
+        tile.title = (p.name ? p.name + " / " : "") + t + ": " + formatCost(p.types[t]);
+        if (width > 8 && height > 12) {
+          tile.textContent = t;
+        }
+        tile.addEventListener("click", function () {
+          state.project = p.name;
+          state.type = t;
+          document.getElementById("filter-project").value = p.name;
+          document.getElementById("filter-type").value = t;
+          render();
+        });
+        treemap.appendChild(tile);
+        top += height;
+      });
+
+      left += width;
+    });
+  }
+
+  function render() {
+    var list = sortRows(filteredRows());
+    renderTreemap(list);
+    renderSummary(list);
+    renderTable(list);
+  }
+
+  var projectSelect = document.getElementById("filter-project");
+  var typeSelect = document.getElementById("filter-type");
+  var tagInput = document.getElementById("filter-tag");
+  var nameInput = document.getElementById("filter-name");
+  var changesInput = document.getElementById("filter-changes");
+
+  addOptions(projectSelect, unique("project"));
+  addOptions(typeSelect, unique("resourceType"));
+
+  projectSelect.addEventListener("change", function () {
+    state.project = projectSelect.value;
+    render();
+  });
+  typeSelect.addEventListener("change", function () {
+    state.type = typeSelect.value;
+    render();
+  });
+  tagInput.addEventListener("input", function () {
+    state.tag = tagInput.value;
+    render();
+  });
+  nameInput.addEventListener("input", function () {
+    state.name = nameInput.value;
+    render();
+  });
+  changesInput.addEventListener("change", function () {
+    state.changesOnly = changesInput.checked;
+    render();
+  });
+  document.getElementById("reset-filters").addEventListener("click", function () {
+    state.project = "";
+    state.type = "";
+    state.tag = "";
+    state.name = "";
+    state.changesOnly = false;
+    projectSelect.value = "";
+    typeSelect.value = "";
+    tagInput.value = "";
+    nameInput.value = "";
+    changesInput.checked = false;
+    render();
+  });
+
+  document.getElementById("filter-changes-label").hidden = !data.hasDiff;
+  reportEl.hidden = false;
+  render();
+})();
 {{end}}
 
 {{define "faviconBase64"}}
@@ -268,10 +846,26 @@ iVBORw0KGgoAAAANSUhEUgAAAMAAAADACAMAAABlApw1AAAABGdBTUEAALGPC/xhBQAAAAFzUkdCAK7O
 
     {{$options := .Options}}
 
-    {{range .Root.Projects}}
-      {{$resources := .Breakdown.Resources}}
-      {{template "projectBlock" dict "Project" . "Options" $options "Resources" $resources "Indent" 0}}
-    {{end}}
+    <div id="report" hidden>
+      <div class="controls">
+        <label>Project <select id="filter-project"><option value="">All</option></select></label>
+        <label>Resource type <select id="filter-type"><option value="">All</option></select></label>
+        <label>Tag <input id="filter-tag" type="text" placeholder="key or key=value"></label>
+        <label>Name <input id="filter-name" type="text" placeholder="Search"></label>
+        <label id="filter-changes-label" hidden><input id="filter-changes" type="checkbox"> Only show changed resources</label>
+        <button id="reset-filters" type="button">Reset</button>
+      </div>
+      <div id="treemap" class="treemap"></div>
+      <div id="report-summary" class="report-summary"></div>
+      <table id="resources" class="resources"></table>
+    </div>
+
+    <noscript>
+      {{range .Root.Projects}}
+        {{$resources := .Breakdown.Resources}}
+        {{template "projectBlock" dict "Project" . "Options" $options "Resources" $resources "Indent" 0}}
+      {{end}}
+    </noscript>
 
     <table class="overall-total">
       <tbody>
@@ -285,10 +879,53 @@ iVBORw0KGgoAAAANSUhEUgAAAMAAAADACAMAAABlApw1AAAABGdBTUEAALGPC/xhBQAAAAFzUkdCAK7O
     <div class="warnings">
       <p>{{.SummaryMessage | stripColor | replaceNewLines}}</p>
     </div>
+
+    <script id="report-data" type="application/json">{{.ReportData}}</script>
+    <script>
+      {{template "script"}}
+    </script>
   </body>
 </html>`
 
-var CommentMarkdownWithHTMLTemplate = `
+// approvalRequiredTemplate renders the note asking the configured approvers to approve a cost
+// increase above the approval threshold.
+var approvalRequiredTemplate = `
+{{- define "approvalRequired" -}}
+**Approval required:** the monthly cost increase is above {{ formatCost .MarkdownOptions.ApprovalThreshold }}, so this change needs approval from {{ range $i, $a := .MarkdownOptions.Approvers }}{{ if $i }}, {{ end }}@{{ trimPrefix "@" $a }}{{ end }}. To approve, comment ` + "`/infracost approve`" + ` or react with 👍 to this comment.
+{{- end}}
+`
+
+// policyResultsTemplate renders policy check results grouped by project and then resource.
+// It is shared by the comment markdown templates.
+var policyResultsTemplate = `
+{{- define "policyResults"}}
+  {{- range . }}
+    {{- if .Project }}
+
+Project: {{ .Project }}
+    {{- end }}
+    {{- range .Resources }}
+      {{- if .Resource }}
+> {{ .Resource }}
+        {{- range .Results }}
+>   - {{ .Message }}
+          {{- if .Remediation }}
+>     Remediation: {{ .Remediation }}
+          {{- end }}
+        {{- end }}
+      {{- else }}
+        {{- range .Results }}
+> {{ .Message }}
+          {{- if .Remediation }}
+>   Remediation: {{ .Remediation }}
+          {{- end }}
+        {{- end }}
+      {{- end }}
+    {{- end }}
+  {{- end }}
+{{- end }}`
+
+var CommentMarkdownWithHTMLTemplate = approvalRequiredTemplate + policyResultsTemplate + `
 {{- define "summaryRow"}}
     <tr>
       <td>{{ truncateMiddle .Name 64 "..." }}</td>
@@ -343,19 +980,25 @@ var CommentMarkdownWithHTMLTemplate = `
 	{{- if gt (len .Options.PolicyChecks.Failures) 0 }}
 		<details>
 			<summary><strong>❌ Policy checks failed</strong></summary>
-				{{ range $v, $f := .Options.PolicyChecks.Failures}}
-> {{ $f }}
-				{{- end}}
+{{ template "policyResults" .Options.PolicyChecks.Failures.GroupByProject }}
 		</details>
 	{{ else }}
 		<details>
 			<summary><strong>✅ Policy checks passed</strong></summary>
-			{{ range $v, $f := .Options.PolicyChecks.Passed}}
-> {{ $f }}
-			{{- end}}
+{{ template "policyResults" .Options.PolicyChecks.Passed.GroupByProject }}
+		</details>
+	{{- end }}
+	{{- if gt (len .Options.PolicyChecks.Warnings) 0 }}
+		<details>
+			<summary><strong>⚠️ Policy checks warnings</strong></summary>
+{{ template "policyResults" .Options.PolicyChecks.Warnings.GroupByProject }}
 		</details>
 	{{- end }}
 {{- end }}
+{{- if .ApprovalRequired }}
+
+{{ template "approvalRequired" . }}
+{{- end }}
 {{- if .MarkdownOptions.WillUpdate }}
 
 This comment will be updated when the cost estimate changes.
@@ -372,7 +1015,7 @@ This comment will be replaced when the cost estimate changes
 {{- end}}
 `
 
-var CommentMarkdownTemplate = `
+var CommentMarkdownTemplate = approvalRequiredTemplate + policyResultsTemplate + `
 {{- define "summaryRow"}}
 | {{ truncateMiddle .Name 64 "..." }} | {{ formatCost .PastCost }} | {{ formatCost .Cost }} | {{ formatCostChange .PastCost .Cost }} |
 {{- end }}
@@ -415,19 +1058,26 @@ var CommentMarkdownTemplate = `
 	{{- if gt (len .Options.PolicyChecks.Failures) 0 }}
 **Policy checks failed:**
 ` + "```" /* can't escape backticks */ + `
-				{{ range $v, $f := .Options.PolicyChecks.Failures}}
-> {{ $f }}
-				{{- end}}
+{{ template "policyResults" .Options.PolicyChecks.Failures.GroupByProject }}
 ` + "```" /* can't escape backticks */ + `
 	{{ else }}
 **Policy checks passed:**
 ` + "```" /* can't escape backticks */ + `
-			{{ range $v, $f := .Options.PolicyChecks.Passed}}
-> {{ $f }}
-			{{- end}}
+{{ template "policyResults" .Options.PolicyChecks.Passed.GroupByProject }}
+` + "```" /* can't escape backticks */ + `
+	{{- end }}
+	{{- if gt (len .Options.PolicyChecks.Warnings) 0 }}
+
+**Policy checks warnings:**
+` + "```" /* can't escape backticks */ + `
+{{ template "policyResults" .Options.PolicyChecks.Warnings.GroupByProject }}
 ` + "```" /* can't escape backticks */ + `
 	{{- end }}
 {{- end }}
+{{- if .ApprovalRequired }}
+
+{{ template "approvalRequired" . }}
+{{- end }}
 {{- if .MarkdownOptions.WillUpdate }}
 
 This comment will be updated when the cost estimate changes.
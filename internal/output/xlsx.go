@@ -0,0 +1,438 @@
+package output
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+)
+
+// xlsxMaxSheetNameLen is the maximum length of a sheet name in Excel.
+const xlsxMaxSheetNameLen = 31
+
+// xlsxUntaggedValue is the tag value used in the tag sheets for resources that don't have the tag.
+const xlsxUntaggedValue = "(not set)"
+
+var xlsxSheetNameReplacer = strings.NewReplacer("[", "(", "]", ")", ":", "-", "*", "-", "?", "-", "/", "-", "\\", "-")
+
+type xlsxCell struct {
+	value    string
+	isNumber bool
+}
+
+type xlsxSheet struct {
+	name   string
+	header []string
+	rows   [][]xlsxCell
+}
+
+func textCell(s string) xlsxCell {
+	return xlsxCell{value: s}
+}
+
+func numberCell(d *decimal.Decimal) xlsxCell {
+	if d == nil {
+		return xlsxCell{}
+	}
+
+	return xlsxCell{value: d.String(), isNumber: true}
+}
+
+// ToXLSX returns an Excel workbook with a sheet that has a row for each cost component, the same as the CSV
+// output, a summary sheet for each project with the costs of its resources, and a sheet for each tag key
+// with the costs grouped by the tag values, which can be used for showback.
+func ToXLSX(out Root, opts Options) ([]byte, error) {
+	rows := costComponentRows(out, opts)
+
+	sheets := []*xlsxSheet{costComponentSheet(out.Currency, rows)}
+	sheets = append(sheets, projectSheets(out, opts, rows)...)
+	sheets = append(sheets, tagSheets(out.Currency, rows)...)
+
+	names := make(map[string]bool, len(sheets))
+	for _, s := range sheets {
+		s.name = uniqueSheetName(s.name, names)
+	}
+
+	return writeXLSX(sheets)
+}
+
+func costComponentSheet(currency string, rows []costComponentRow) *xlsxSheet {
+	sheet := &xlsxSheet{
+		name:   "Cost components",
+		header: costComponentHeader(currency),
+	}
+
+	for _, row := range rows {
+		values := row.values()
+		cells := make([]xlsxCell, 0, len(values))
+
+		for i, v := range values {
+			if i < textColumns || v == "" {
+				cells = append(cells, textCell(v))
+			} else {
+				cells = append(cells, xlsxCell{value: v, isNumber: true})
+			}
+		}
+
+		sheet.rows = append(sheet.rows, cells)
+	}
+
+	return sheet
+}
+
+// costTotals holds the totals of a group of cost component rows.
+type costTotals struct {
+	name            string
+	resources       map[string]bool
+	hourlyCost      *decimal.Decimal
+	monthlyCost     *decimal.Decimal
+	pastMonthlyCost *decimal.Decimal
+}
+
+func (t *costTotals) add(row costComponentRow) {
+	if t.resources == nil {
+		t.resources = make(map[string]bool)
+	}
+
+	t.resources[row.Resource] = true
+	t.hourlyCost = addDecimals(t.hourlyCost, row.HourlyCost)
+	t.monthlyCost = addDecimals(t.monthlyCost, row.MonthlyCost)
+	t.pastMonthlyCost = addDecimals(t.pastMonthlyCost, row.PastMonthlyCost)
+}
+
+func addDecimals(total *decimal.Decimal, d *decimal.Decimal) *decimal.Decimal {
+	if d == nil {
+		return total
+	}
+
+	if total == nil {
+		return decimalPtr(*d)
+	}
+
+	return decimalPtr(total.Add(*d))
+}
+
+// groupRows returns the totals of the rows grouped by the key, in the order the keys are first seen.
+func groupRows(rows []costComponentRow, key func(costComponentRow) string) []*costTotals {
+	var groups []*costTotals
+	byKey := make(map[string]*costTotals)
+
+	for _, row := range rows {
+		k := key(row)
+
+		g, ok := byKey[k]
+		if !ok {
+			g = &costTotals{name: k}
+			byKey[k] = g
+			groups = append(groups, g)
+		}
+
+		g.add(row)
+	}
+
+	return groups
+}
+
+func projectSheets(out Root, opts Options, rows []costComponentRow) []*xlsxSheet {
+	sheets := make([]*xlsxSheet, 0, len(out.Projects))
+
+	for i, p := range out.Projects {
+		var projectRows []costComponentRow
+		for _, row := range rows {
+			if row.projectIndex == i {
+				projectRows = append(projectRows, row)
+			}
+		}
+
+		sheet := &xlsxSheet{
+			name: p.Label(opts.DashboardEnabled),
+			header: []string{
+				"Resource",
+				"Resource type",
+				formatTitleWithCurrency("Hourly cost", out.Currency),
+				formatTitleWithCurrency("Monthly cost", out.Currency),
+				formatTitleWithCurrency("Past monthly cost", out.Currency),
+				formatTitleWithCurrency("Diff monthly cost", out.Currency),
+			},
+		}
+
+		total := &costTotals{}
+
+		for _, g := range groupRows(projectRows, func(r costComponentRow) string { return r.Resource }) {
+			sheet.rows = append(sheet.rows, []xlsxCell{
+				textCell(g.name),
+				textCell(ResourceType(g.name)),
+				numberCell(g.hourlyCost),
+				numberCell(g.monthlyCost),
+				numberCell(g.pastMonthlyCost),
+				numberCell(diffDecimal(g.pastMonthlyCost, g.monthlyCost)),
+			})
+		}
+
+		for _, row := range projectRows {
+			total.add(row)
+		}
+
+		sheet.rows = append(sheet.rows, []xlsxCell{
+			textCell("Total"),
+			textCell(""),
+			numberCell(total.hourlyCost),
+			numberCell(total.monthlyCost),
+			numberCell(total.pastMonthlyCost),
+			numberCell(diffDecimal(total.pastMonthlyCost, total.monthlyCost)),
+		})
+
+		sheets = append(sheets, sheet)
+	}
+
+	return sheets
+}
+
+func tagSheets(currency string, rows []costComponentRow) []*xlsxSheet {
+	keySet := make(map[string]bool)
+	for _, row := range rows {
+		for k := range row.Tags {
+			keySet[k] = true
+		}
+	}
+
+	keys := make([]string, 0, len(keySet))
+	for k := range keySet {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	sheets := make([]*xlsxSheet, 0, len(keys))
+
+	for _, tagKey := range keys {
+		groups := groupRows(rows, func(r costComponentRow) string {
+			if v, ok := r.Tags[tagKey]; ok {
+				return v
+			}
+			return xlsxUntaggedValue
+		})
+
+		sort.SliceStable(groups, func(i, j int) bool {
+			if (groups[i].name == xlsxUntaggedValue) != (groups[j].name == xlsxUntaggedValue) {
+				return groups[j].name == xlsxUntaggedValue
+			}
+			return groups[i].name < groups[j].name
+		})
+
+		sheet := &xlsxSheet{
+			name: fmt.Sprintf("Tag %s", tagKey),
+			header: []string{
+				tagKey,
+				"Resources",
+				formatTitleWithCurrency("Monthly cost", currency),
+				formatTitleWithCurrency("Past monthly cost", currency),
+				formatTitleWithCurrency("Diff monthly cost", currency),
+			},
+		}
+
+		for _, g := range groups {
+			count := decimal.NewFromInt(int64(len(g.resources)))
+
+			sheet.rows = append(sheet.rows, []xlsxCell{
+				textCell(g.name),
+				numberCell(&count),
+				numberCell(g.monthlyCost),
+				numberCell(g.pastMonthlyCost),
+				numberCell(diffDecimal(g.pastMonthlyCost, g.monthlyCost)),
+			})
+		}
+
+		sheets = append(sheets, sheet)
+	}
+
+	return sheets
+}
+
+// uniqueSheetName returns a valid Excel sheet name that hasn't been used yet. Sheet names can't have some
+// characters, can't be longer than 31 characters and must be unique ignoring case.
+func uniqueSheetName(name string, used map[string]bool) string {
+	name = strings.TrimSpace(xlsxSheetNameReplacer.Replace(name))
+	if name == "" {
+		name = "Sheet"
+	}
+
+	candidate := truncateSheetName(name, "")
+	for i := 2; used[strings.ToLower(candidate)]; i++ {
+		candidate = truncateSheetName(name, fmt.Sprintf(" (%d)", i))
+	}
+
+	used[strings.ToLower(candidate)] = true
+
+	return candidate
+}
+
+// truncateSheetName shortens long names, e.g. project paths, by replacing their middle, since both the start
+// and end of a project path are useful to tell the sheets apart.
+func truncateSheetName(name string, suffix string) string {
+	return truncateMiddle(name, xlsxMaxSheetNameLen-len([]rune(suffix)), "...") + suffix
+}
+
+// writeXLSX writes the sheets as an Office Open XML workbook. Only the parts of the format that are needed
+// for plain tables are written: strings are written inline and the header rows use a bold font.
+func writeXLSX(sheets []*xlsxSheet) ([]byte, error) {
+	var buf bytes.Buffer
+	z := zip.NewWriter(&buf)
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", xlsxContentTypes(len(sheets))},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook(sheets)},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels(len(sheets))},
+		{"xl/styles.xml", xlsxStyles},
+	}
+
+	for i, sheet := range sheets {
+		files = append(files, struct {
+			name    string
+			content string
+		}{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), xlsxWorksheet(sheet)})
+	}
+
+	for _, f := range files {
+		w, err := z.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Deflate})
+		if err != nil {
+			return nil, errors.Wrap(err, "Error creating XLSX file")
+		}
+
+		_, err = w.Write([]byte(f.content))
+		if err != nil {
+			return nil, errors.Wrap(err, "Error writing XLSX file")
+		}
+	}
+
+	err := z.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error writing XLSX file")
+	}
+
+	return buf.Bytes(), nil
+}
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts><fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills><borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders><cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs><cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs></styleSheet>`
+
+func xlsxContentTypes(sheetCount int) string {
+	var b strings.Builder
+
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+
+	for i := 1; i <= sheetCount; i++ {
+		fmt.Fprintf(&b, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i)
+	}
+
+	b.WriteString(`</Types>`)
+
+	return b.String()
+}
+
+func xlsxWorkbook(sheets []*xlsxSheet) string {
+	var b strings.Builder
+
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+
+	for i, sheet := range sheets {
+		fmt.Fprintf(&b, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(sheet.name), i+1, i+1)
+	}
+
+	b.WriteString(`</sheets></workbook>`)
+
+	return b.String()
+}
+
+func xlsxWorkbookRels(sheetCount int) string {
+	var b strings.Builder
+
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+
+	for i := 1; i <= sheetCount; i++ {
+		fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i, i)
+	}
+
+	fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, sheetCount+1)
+	b.WriteString(`</Relationships>`)
+
+	return b.String()
+}
+
+func xlsxWorksheet(sheet *xlsxSheet) string {
+	var b strings.Builder
+
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	header := make([]xlsxCell, 0, len(sheet.header))
+	for _, h := range sheet.header {
+		header = append(header, textCell(h))
+	}
+
+	writeXLSXRow(&b, 1, header, true)
+	for i, row := range sheet.rows {
+		writeXLSXRow(&b, i+2, row, false)
+	}
+
+	b.WriteString(`</sheetData></worksheet>`)
+
+	return b.String()
+}
+
+func writeXLSXRow(b *strings.Builder, rowNum int, cells []xlsxCell, bold bool) {
+	fmt.Fprintf(b, `<row r="%d">`, rowNum)
+
+	style := ""
+	if bold {
+		style = ` s="1"`
+	}
+
+	for i, cell := range cells {
+		ref := fmt.Sprintf("%s%d", xlsxColumnName(i), rowNum)
+
+		switch {
+		case cell.value == "":
+			continue
+		case cell.isNumber:
+			fmt.Fprintf(b, `<c r="%s"%s><v>%s</v></c>`, ref, style, cell.value)
+		default:
+			fmt.Fprintf(b, `<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, style, xmlEscape(cell.value))
+		}
+	}
+
+	b.WriteString(`</row>`)
+}
+
+// xlsxColumnName returns the column letters for the zero based column index, e.g. 0 returns A and 26
+// returns AA.
+func xlsxColumnName(i int) string {
+	name := ""
+
+	for i >= 0 {
+		name = string(rune('A'+i%26)) + name
+		i = i/26 - 1
+	}
+
+	return name
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,142 @@
+package output
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func xlsxTestRoot() Root {
+	return Root{
+		Currency: "USD",
+		Projects: []Project{
+			{
+				Name: "infra",
+				PastBreakdown: &Breakdown{
+					Resources: []Resource{
+						{
+							Name: "aws_instance.web",
+							Tags: map[string]string{"team": "web"},
+							CostComponents: []CostComponent{
+								{Name: "Instance usage", Unit: "hours", Price: decimal.NewFromFloat(0.1), MonthlyQuantity: decimalPtr(decimal.NewFromFloat(730)), HourlyCost: decimalPtr(decimal.NewFromFloat(0.1)), MonthlyCost: decimalPtr(decimal.NewFromFloat(73))},
+							},
+						},
+						{
+							Name: "aws_nat_gateway.old",
+							CostComponents: []CostComponent{
+								{Name: "NAT gateway", Unit: "hours", Price: decimal.NewFromFloat(0.05), MonthlyQuantity: decimalPtr(decimal.NewFromFloat(730)), HourlyCost: decimalPtr(decimal.NewFromFloat(0.05)), MonthlyCost: decimalPtr(decimal.NewFromFloat(36.5))},
+							},
+						},
+					},
+				},
+				Breakdown: &Breakdown{
+					Resources: []Resource{
+						{
+							Name: "aws_instance.web",
+							Tags: map[string]string{"team": "web"},
+							CostComponents: []CostComponent{
+								{Name: "Instance usage", Unit: "hours", Price: decimal.NewFromFloat(0.2), MonthlyQuantity: decimalPtr(decimal.NewFromFloat(730)), HourlyCost: decimalPtr(decimal.NewFromFloat(0.2)), MonthlyCost: decimalPtr(decimal.NewFromFloat(146))},
+							},
+							SubResources: []Resource{
+								{
+									Name: "root_block_device",
+									CostComponents: []CostComponent{
+										{Name: "Storage", Unit: "GB", Price: decimal.NewFromFloat(0.1), MonthlyQuantity: decimalPtr(decimal.NewFromFloat(50)), MonthlyCost: decimalPtr(decimal.NewFromFloat(5))},
+									},
+								},
+							},
+						},
+						{
+							Name: `module.db["a"].aws_db_instance.db`,
+							Tags: map[string]string{"team": "data"},
+							CostComponents: []CostComponent{
+								{Name: "Database instance", Unit: "hours", Price: decimal.NewFromFloat(0.5), MonthlyQuantity: decimalPtr(decimal.NewFromFloat(730)), HourlyCost: decimalPtr(decimal.NewFromFloat(0.5)), MonthlyCost: decimalPtr(decimal.NewFromFloat(365))},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func readXLSXFiles(t *testing.T, b []byte) map[string]string {
+	t.Helper()
+
+	r, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	require.NoError(t, err)
+
+	files := make(map[string]string)
+	for _, f := range r.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+
+		files[f.Name] = string(content)
+	}
+
+	return files
+}
+
+func TestCostComponentRows(t *testing.T) {
+	rows := costComponentRows(xlsxTestRoot(), Options{})
+
+	actual := make([][]string, 0, len(rows))
+	for _, r := range rows {
+		actual = append(actual, r.values())
+	}
+
+	assert.Equal(t, [][]string{
+		{"infra", "aws_instance.web", "aws_instance", "team=web", "Instance usage", "hours", "0.2", "730", "0.2", "146", "730", "0.1", "73", "0", "0.1", "73"},
+		{"infra", "aws_instance.web", "aws_instance", "team=web", "root_block_device > Storage", "GB", "0.1", "50", "", "5", "", "", "", "50", "", "5"},
+		{"infra", `module.db["a"].aws_db_instance.db`, "aws_db_instance", "team=data", "Database instance", "hours", "0.5", "730", "0.5", "365", "", "", "", "730", "0.5", "365"},
+		{"infra", "aws_nat_gateway.old", "aws_nat_gateway", "", "NAT gateway", "hours", "0.05", "", "", "", "730", "0.05", "36.5", "-730", "-0.05", "-36.5"},
+	}, actual)
+}
+
+func TestToXLSX(t *testing.T) {
+	b, err := ToXLSX(xlsxTestRoot(), Options{})
+	require.NoError(t, err)
+
+	files := readXLSXFiles(t, b)
+
+	assert.Contains(t, files["xl/workbook.xml"], `<sheet name="Cost components" sheetId="1" r:id="rId1"/>`)
+	assert.Contains(t, files["xl/workbook.xml"], `<sheet name="infra" sheetId="2" r:id="rId2"/>`)
+	assert.Contains(t, files["xl/workbook.xml"], `<sheet name="Tag team" sheetId="3" r:id="rId3"/>`)
+
+	assert.Contains(t, files["xl/worksheets/sheet1.xml"], `<c r="B4" t="inlineStr"><is><t xml:space="preserve">module.db[&#34;a&#34;].aws_db_instance.db</t></is></c>`)
+
+	project := files["xl/worksheets/sheet2.xml"]
+	assert.Contains(t, project, `<c r="A2" t="inlineStr"><is><t xml:space="preserve">aws_instance.web</t></is></c><c r="B2" t="inlineStr"><is><t xml:space="preserve">aws_instance</t></is></c><c r="C2"><v>0.2</v></c><c r="D2"><v>151</v></c><c r="E2"><v>73</v></c><c r="F2"><v>78</v></c>`)
+	assert.Contains(t, project, `<c r="A5" t="inlineStr"><is><t xml:space="preserve">Total</t></is></c><c r="C5"><v>0.7</v></c><c r="D5"><v>516</v></c><c r="E5"><v>109.5</v></c><c r="F5"><v>406.5</v></c>`)
+
+	tags := files["xl/worksheets/sheet3.xml"]
+	assert.Contains(t, tags, `<c r="A2" t="inlineStr"><is><t xml:space="preserve">data</t></is></c><c r="B2"><v>1</v></c><c r="C2"><v>365</v></c>`)
+	assert.Contains(t, tags, `<c r="A3" t="inlineStr"><is><t xml:space="preserve">web</t></is></c><c r="B3"><v>1</v></c><c r="C3"><v>151</v></c><c r="D3"><v>73</v></c><c r="E3"><v>78</v></c>`)
+	assert.Contains(t, tags, `<c r="A4" t="inlineStr"><is><t xml:space="preserve">(not set)</t></is></c><c r="B4"><v>1</v></c><c r="D4"><v>36.5</v></c><c r="E4"><v>-36.5</v></c>`)
+}
+
+func TestUniqueSheetName(t *testing.T) {
+	used := make(map[string]bool)
+
+	assert.Equal(t, "infra-dev", uniqueSheetName("infra/dev", used))
+	assert.Equal(t, "Infra-Dev (2)", uniqueSheetName("Infra/Dev", used))
+	assert.Equal(t, "my-org-my-repo...ts-staging-app", uniqueSheetName("my-org/my-repo/environments/staging/app", used))
+	assert.Equal(t, "Sheet", uniqueSheetName("", used))
+}
+
+func TestXLSXColumnName(t *testing.T) {
+	assert.Equal(t, "A", xlsxColumnName(0))
+	assert.Equal(t, "Z", xlsxColumnName(25))
+	assert.Equal(t, "AA", xlsxColumnName(26))
+	assert.Equal(t, "AZ", xlsxColumnName(51))
+	assert.Equal(t, "BA", xlsxColumnName(52))
+}
@@ -0,0 +1,307 @@
+package policy
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/infracost/infracost/internal/config"
+	"github.com/infracost/infracost/internal/output"
+)
+
+// EvaluateGuardrails checks the guardrails defined in the config file against the projects
+// in the Infracost output. Each guardrail is only checked against the projects that match
+// its paths.
+func EvaluateGuardrails(guardrails []*config.Guardrail, r output.Root) (output.PolicyCheck, error) {
+	checks := output.PolicyCheck{
+		Enabled: true,
+	}
+
+	for i, g := range guardrails {
+		severity, ok := parseSeverity(g.Severity)
+		if !ok {
+			return checks, fmt.Errorf("Invalid severity '%s' in guardrail '%s', valid values are %s and %s", g.Severity, guardrailName(g, i), output.PolicySeverityError, output.PolicySeverityWarn)
+		}
+
+		e := guardrailEvaluator{
+			name:     guardrailName(g, i),
+			severity: severity,
+			currency: r.Currency,
+			checks:   &checks,
+		}
+
+		for _, p := range r.Projects {
+			if !matchesProjectPath(g.Paths, p) {
+				continue
+			}
+
+			e.evaluate(g, p)
+		}
+	}
+
+	return checks, nil
+}
+
+type guardrailEvaluator struct {
+	name     string
+	severity string
+	currency string
+	checks   *output.PolicyCheck
+}
+
+func (e *guardrailEvaluator) evaluate(g *config.Guardrail, p output.Project) {
+	resources := sortedResources(p.Breakdown)
+
+	// Runs without a past breakdown, e.g. infracost breakdown, don't have a cost increase to check.
+	hasPast := p.PastBreakdown != nil
+
+	if g.MaxMonthlyIncrease != nil && hasPast {
+		max := decimal.NewFromFloat(*g.MaxMonthlyIncrease)
+		increase := monthlyIncrease(p)
+
+		if increase.GreaterThan(max) {
+			e.fail(p, "", fmt.Sprintf("Monthly cost increase of %s exceeds the maximum of %s", e.formatCost(increase), e.formatCost(max)), "")
+		} else {
+			e.pass(p, fmt.Sprintf("Monthly cost increase is below the maximum of %s", e.formatCost(max)))
+		}
+	}
+
+	if g.MaxPercentIncrease != nil && hasPast {
+		max := decimal.NewFromFloat(*g.MaxPercentIncrease)
+		percent, ok := percentIncrease(p)
+
+		if !ok {
+			past, current := projectCosts(p)
+			e.fail(p, "", fmt.Sprintf("Monthly cost increase from %s to %s exceeds the maximum of %s%%", e.formatCost(past), e.formatCost(current), max.String()), "")
+		} else if percent.GreaterThan(max) {
+			e.fail(p, "", fmt.Sprintf("Monthly cost increase of %s%% exceeds the maximum of %s%%", percent.StringFixed(0), max.String()), "")
+		} else {
+			e.pass(p, fmt.Sprintf("Monthly cost increase is below the maximum of %s%%", max.String()))
+		}
+	}
+
+	if g.MaxResourceMonthlyCost != nil {
+		max := decimal.NewFromFloat(*g.MaxResourceMonthlyCost)
+		failed := false
+
+		for _, r := range resources {
+			if r.MonthlyCost != nil && r.MonthlyCost.GreaterThan(max) {
+				failed = true
+				e.fail(p, r.Name, fmt.Sprintf("Monthly cost of %s exceeds the maximum resource cost of %s", e.formatCost(*r.MonthlyCost), e.formatCost(max)), "")
+			}
+		}
+
+		if !failed {
+			e.pass(p, fmt.Sprintf("All resources have a monthly cost below %s", e.formatCost(max)))
+		}
+	}
+
+	if len(g.ForbidResourceTypes) > 0 {
+		failed := false
+
+		for _, r := range resources {
+			t := resourceType(r.Name)
+			if contains(g.ForbidResourceTypes, t) {
+				failed = true
+				e.fail(p, r.Name, fmt.Sprintf("Resource type %s is not allowed", t), "")
+			}
+		}
+
+		if !failed {
+			e.pass(p, fmt.Sprintf("No forbidden resource types are used: %s", strings.Join(g.ForbidResourceTypes, ", ")))
+		}
+	}
+
+	if len(g.RequireTags) > 0 {
+		failed := false
+
+		for _, r := range resources {
+			// Only resources with a cost need to be tagged, so free resources and resources
+			// without usage don't fail the guardrail.
+			if r.MonthlyCost == nil || r.MonthlyCost.IsZero() {
+				continue
+			}
+
+			var missing []string
+			for _, tag := range g.RequireTags {
+				if _, ok := r.Tags[tag]; !ok {
+					missing = append(missing, tag)
+				}
+			}
+
+			if len(missing) > 0 {
+				failed = true
+				e.fail(p, r.Name, fmt.Sprintf("Resource is missing required tags: %s", strings.Join(missing, ", ")), fmt.Sprintf("Add the %s tags to the resource", strings.Join(missing, ", ")))
+			}
+		}
+
+		if !failed {
+			e.pass(p, fmt.Sprintf("All resources with a cost have the required tags: %s", strings.Join(g.RequireTags, ", ")))
+		}
+	}
+}
+
+func (e *guardrailEvaluator) fail(p output.Project, resource, msg, remediation string) {
+	result := output.PolicyCheckResult{
+		Policy:      e.name,
+		Message:     fmt.Sprintf("%s: %s", e.name, msg),
+		Resource:    resource,
+		Project:     p.Name,
+		Severity:    e.severity,
+		Remediation: remediation,
+	}
+
+	if e.severity == output.PolicySeverityWarn {
+		e.checks.Warnings = append(e.checks.Warnings, result)
+		return
+	}
+
+	e.checks.Failures = append(e.checks.Failures, result)
+}
+
+func (e *guardrailEvaluator) pass(p output.Project, msg string) {
+	e.checks.Passed = append(e.checks.Passed, output.PolicyCheckResult{
+		Policy:   e.name,
+		Message:  fmt.Sprintf("%s: %s", e.name, msg),
+		Project:  p.Name,
+		Severity: e.severity,
+	})
+}
+
+func (e *guardrailEvaluator) formatCost(d decimal.Decimal) string {
+	return fmt.Sprintf("%s %s", d.StringFixed(2), e.currency)
+}
+
+func guardrailName(g *config.Guardrail, i int) string {
+	if g.Name != "" {
+		return g.Name
+	}
+
+	return fmt.Sprintf("Guardrail %d", i+1)
+}
+
+// matchesProjectPath returns true if the project path matches any of the given patterns.
+// A pattern matches if it is a glob match for the path or if the path is inside the pattern directory.
+func matchesProjectPath(patterns []string, p output.Project) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+
+	if p.Metadata == nil {
+		return false
+	}
+
+	path := filepath.Clean(p.Metadata.Path)
+
+	for _, pattern := range patterns {
+		pattern = filepath.Clean(pattern)
+
+		if ok, _ := filepath.Match(pattern, path); ok {
+			return true
+		}
+
+		if path == pattern || strings.HasPrefix(path, pattern+string(filepath.Separator)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func monthlyIncrease(p output.Project) decimal.Decimal {
+	past, current := projectCosts(p)
+	return current.Sub(past)
+}
+
+// percentIncrease returns the percentage increase in the project's monthly cost. If the past cost
+// is zero and the current cost isn't then the increase can't be expressed as a percentage, so false
+// is returned.
+func percentIncrease(p output.Project) (decimal.Decimal, bool) {
+	past, current := projectCosts(p)
+	if past.IsZero() {
+		return decimal.Zero, !current.IsPositive()
+	}
+
+	return current.Sub(past).Div(past).Mul(decimal.NewFromInt(100)), true
+}
+
+func projectCosts(p output.Project) (decimal.Decimal, decimal.Decimal) {
+	past := decimal.Zero
+	if p.PastBreakdown != nil && p.PastBreakdown.TotalMonthlyCost != nil {
+		past = *p.PastBreakdown.TotalMonthlyCost
+	}
+
+	current := decimal.Zero
+	if p.Breakdown != nil && p.Breakdown.TotalMonthlyCost != nil {
+		current = *p.Breakdown.TotalMonthlyCost
+	}
+
+	return past, current
+}
+
+func sortedResources(b *output.Breakdown) []output.Resource {
+	if b == nil {
+		return nil
+	}
+
+	resources := make([]output.Resource, len(b.Resources))
+	copy(resources, b.Resources)
+
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].Name < resources[j].Name
+	})
+
+	return resources
+}
+
+// resourceType returns the resource type from a resource address, e.g.
+// module.web["a"].aws_instance.web[0] returns aws_instance.
+func resourceType(address string) string {
+	parts := splitAddress(address)
+	if len(parts) < 2 {
+		return ""
+	}
+
+	t := parts[len(parts)-2]
+	if i := strings.Index(t, "["); i != -1 {
+		t = t[:i]
+	}
+
+	return t
+}
+
+// splitAddress splits a resource address on dots that are not inside an index.
+func splitAddress(address string) []string {
+	var parts []string
+	depth := 0
+	start := 0
+
+	for i, c := range address {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '.':
+			if depth == 0 {
+				parts = append(parts, address[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(parts, address[start:])
+}
+
+func contains(arr []string, e string) bool {
+	for _, a := range arr {
+		if a == e {
+			return true
+		}
+	}
+
+	return false
+}
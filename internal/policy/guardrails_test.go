@@ -0,0 +1,209 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/infracost/infracost/internal/config"
+	"github.com/infracost/infracost/internal/output"
+	"github.com/infracost/infracost/internal/schema"
+)
+
+func decimalPtr(f float64) *decimal.Decimal {
+	d := decimal.NewFromFloat(f)
+	return &d
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+func testRoot() output.Root {
+	return output.Root{
+		Currency: "USD",
+		Projects: []output.Project{
+			{
+				Name:     "infra/prod",
+				Metadata: &schema.ProjectMetadata{Path: "infra/prod"},
+				PastBreakdown: &output.Breakdown{
+					TotalMonthlyCost: decimalPtr(1000),
+				},
+				Breakdown: &output.Breakdown{
+					TotalMonthlyCost: decimalPtr(1600),
+					Resources: []output.Resource{
+						{
+							Name:        "aws_instance.web",
+							Tags:        map[string]string{"team": "web"},
+							MonthlyCost: decimalPtr(1200),
+						},
+						{
+							Name:        `module.network["a"].aws_nat_gateway.main`,
+							MonthlyCost: decimalPtr(400),
+						},
+					},
+				},
+			},
+			{
+				Name:     "infra/dev",
+				Metadata: &schema.ProjectMetadata{Path: "infra/dev"},
+				Breakdown: &output.Breakdown{
+					TotalMonthlyCost: decimalPtr(50),
+					Resources: []output.Resource{
+						{
+							Name:        "aws_instance.web",
+							Tags:        map[string]string{"team": "web"},
+							MonthlyCost: decimalPtr(50),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestEvaluateGuardrails(t *testing.T) {
+	guardrails := []*config.Guardrail{
+		{
+			Name:                   "prod",
+			Paths:                  []string{"infra/prod"},
+			MaxMonthlyIncrease:     floatPtr(500),
+			MaxPercentIncrease:     floatPtr(80),
+			MaxResourceMonthlyCost: floatPtr(1000),
+			ForbidResourceTypes:    []string{"aws_nat_gateway"},
+		},
+		{
+			Name:        "tags",
+			Severity:    "warn",
+			RequireTags: []string{"team"},
+		},
+	}
+
+	checks, err := EvaluateGuardrails(guardrails, testRoot())
+	require.NoError(t, err)
+
+	assert.True(t, checks.Enabled)
+	assert.Equal(t, output.PolicyCheckFailures{
+		{
+			Policy:   "prod",
+			Message:  "prod: Monthly cost increase of 600.00 USD exceeds the maximum of 500.00 USD",
+			Project:  "infra/prod",
+			Severity: output.PolicySeverityError,
+		},
+		{
+			Policy:   "prod",
+			Message:  "prod: Monthly cost of 1200.00 USD exceeds the maximum resource cost of 1000.00 USD",
+			Resource: "aws_instance.web",
+			Project:  "infra/prod",
+			Severity: output.PolicySeverityError,
+		},
+		{
+			Policy:   "prod",
+			Message:  "prod: Resource type aws_nat_gateway is not allowed",
+			Resource: `module.network["a"].aws_nat_gateway.main`,
+			Project:  "infra/prod",
+			Severity: output.PolicySeverityError,
+		},
+	}, checks.Failures)
+	assert.Equal(t, output.PolicyCheckResults{
+		{
+			Policy:      "tags",
+			Message:     "tags: Resource is missing required tags: team",
+			Resource:    `module.network["a"].aws_nat_gateway.main`,
+			Project:     "infra/prod",
+			Severity:    output.PolicySeverityWarn,
+			Remediation: "Add the team tags to the resource",
+		},
+	}, checks.Warnings)
+	assert.Equal(t, output.PolicyCheckResults{
+		{
+			Policy:   "prod",
+			Message:  "prod: Monthly cost increase is below the maximum of 80%",
+			Project:  "infra/prod",
+			Severity: output.PolicySeverityError,
+		},
+		{
+			Policy:   "tags",
+			Message:  "tags: All resources with a cost have the required tags: team",
+			Project:  "infra/dev",
+			Severity: output.PolicySeverityWarn,
+		},
+	}, checks.Passed)
+}
+
+func TestEvaluateGuardrailsNoPastBreakdown(t *testing.T) {
+	guardrails := []*config.Guardrail{
+		{
+			Name:               "dev",
+			Paths:              []string{"infra/dev"},
+			MaxMonthlyIncrease: floatPtr(10),
+			MaxPercentIncrease: floatPtr(10),
+		},
+	}
+
+	checks, err := EvaluateGuardrails(guardrails, testRoot())
+	require.NoError(t, err)
+
+	assert.Empty(t, checks.Failures)
+	assert.Empty(t, checks.Warnings)
+	assert.Empty(t, checks.Passed)
+}
+
+func TestEvaluateGuardrailsIncreaseFromZero(t *testing.T) {
+	r := testRoot()
+	r.Projects[1].PastBreakdown = &output.Breakdown{TotalMonthlyCost: decimalPtr(0)}
+	r.Projects[1].Breakdown.Resources = append(r.Projects[1].Breakdown.Resources,
+		output.Resource{Name: "aws_s3_bucket.logs"},
+		output.Resource{Name: "aws_iam_role.web", MonthlyCost: decimalPtr(0)},
+	)
+
+	guardrails := []*config.Guardrail{
+		{
+			Name:               "dev",
+			Paths:              []string{"infra/dev"},
+			MaxPercentIncrease: floatPtr(20),
+			RequireTags:        []string{"team"},
+		},
+	}
+
+	checks, err := EvaluateGuardrails(guardrails, r)
+	require.NoError(t, err)
+
+	assert.Equal(t, output.PolicyCheckFailures{
+		{
+			Policy:   "dev",
+			Message:  "dev: Monthly cost increase from 0.00 USD to 50.00 USD exceeds the maximum of 20%",
+			Project:  "infra/dev",
+			Severity: output.PolicySeverityError,
+		},
+	}, checks.Failures)
+	assert.Equal(t, output.PolicyCheckResults{
+		{
+			Policy:   "dev",
+			Message:  "dev: All resources with a cost have the required tags: team",
+			Project:  "infra/dev",
+			Severity: output.PolicySeverityError,
+		},
+	}, checks.Passed)
+}
+
+func TestEvaluateGuardrailsInvalidSeverity(t *testing.T) {
+	_, err := EvaluateGuardrails([]*config.Guardrail{{Severity: "critical"}}, testRoot())
+	assert.EqualError(t, err, "Invalid severity 'critical' in guardrail 'Guardrail 1', valid values are error and warn")
+}
+
+func TestResourceType(t *testing.T) {
+	tests := map[string]string{
+		"aws_instance.web":                             "aws_instance",
+		"aws_instance.web[0]":                          "aws_instance",
+		`module.web["a.b"].aws_instance.web["c.d"]`:    "aws_instance",
+		"module.web.module.db.aws_db_instance.primary": "aws_db_instance",
+		"data.aws_ami.ubuntu":                          "aws_ami",
+	}
+
+	for address, expected := range tests {
+		assert.Equal(t, expected, resourceType(address), address)
+	}
+}
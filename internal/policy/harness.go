@@ -0,0 +1,438 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/tester"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	"github.com/infracost/infracost/internal/output"
+)
+
+const (
+	// OutcomePass is the outcome of a policy that returned no failures or warnings.
+	OutcomePass = "pass"
+	// OutcomeWarn is the outcome of a policy that returned warnings but no failures.
+	OutcomeWarn = "warn"
+	// OutcomeFail is the outcome of a policy that returned at least one failure.
+	OutcomeFail = "fail"
+)
+
+var denyRule = ast.Var("deny")
+
+// FixtureSpec defines the expected outcome of running policies against an Infracost JSON
+// fixture. Specs are written as YAML files in the fixtures directory, e.g.
+//
+//	fixture: large_increase.json
+//	expect:
+//	  cost_increase: fail
+//	  tagging: pass
+//
+// The keys of expect are the policy file names without the .rego extension. If policy files
+// in different directories have the same name, the path relative to the policy path can be
+// used instead, e.g. prod/cost_increase.
+type FixtureSpec struct {
+	// Fixture is the path to the Infracost JSON file, relative to the spec file.
+	Fixture string            `yaml:"fixture"`
+	Expect  map[string]string `yaml:"expect"`
+
+	name string
+	path string
+}
+
+// TestResult is the result of a single policy test, either a fixture expectation or an OPA test_ rule.
+type TestResult struct {
+	Policy   string
+	Name     string
+	Expected string
+	Actual   string
+	Err      error
+}
+
+// Passed returns true if the test ran without error and the policy had the expected outcome.
+func (r TestResult) Passed() bool {
+	return r.Err == nil && r.Expected == r.Actual
+}
+
+// RunFixtureTests evaluates the policy files found at policyPaths against the fixtures
+// described by the spec files in fixturesPath and compares the outcome to the expected one.
+// All the policy files are loaded into a single query so policies can import shared helper
+// modules, and the results are then split by the policy file they came from.
+func RunFixtureTests(policyPaths []string, fixturesPath string) ([]TestResult, error) {
+	policyFiles, err := findPolicyFiles(policyPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	specs, err := loadFixtureSpecs(fixturesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	query, err := prepareFixtureQuery(policyFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []TestResult
+
+	for _, spec := range specs {
+		var checks map[string]output.PolicyCheck
+
+		root, err := loadFixture(spec)
+		if err == nil {
+			checks, err = query.eval(root)
+		}
+
+		policyNames := make([]string, 0, len(spec.Expect))
+		for name := range spec.Expect {
+			policyNames = append(policyNames, name)
+		}
+		sort.Strings(policyNames)
+
+		for _, name := range policyNames {
+			result := TestResult{
+				Policy:   strings.TrimSuffix(name, ".rego"),
+				Name:     spec.name,
+				Expected: strings.ToLower(spec.Expect[name]),
+			}
+
+			if err != nil {
+				result.Err = err
+				results = append(results, result)
+				continue
+			}
+
+			if !validOutcome(result.Expected) {
+				result.Err = fmt.Errorf("invalid expected outcome '%s', valid outcomes are %s, %s and %s", spec.Expect[name], OutcomePass, OutcomeWarn, OutcomeFail)
+				results = append(results, result)
+				continue
+			}
+
+			policyFile, lookupErr := lookupPolicyFile(policyFiles, result.Policy)
+			if lookupErr != nil {
+				result.Err = lookupErr
+				results = append(results, result)
+				continue
+			}
+
+			if !query.hasRules(policyFile) {
+				result.Err = fmt.Errorf("policy file %s has no data.infracost.deny rules", policyFile.path)
+				results = append(results, result)
+				continue
+			}
+
+			result.Actual = outcome(checks[policyFile.path])
+			results = append(results, result)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Policy < results[j].Policy
+	})
+
+	return results, nil
+}
+
+// fixtureQuery is a prepared query of all the policy files. The data.infracost.deny rules of
+// each policy file are renamed to a rule unique to that file so the results of the query can be
+// attributed to the file they came from.
+type fixtureQuery struct {
+	query rego.PreparedEvalQuery
+	// rules maps the policy file paths to the name of their renamed deny rules.
+	rules map[string]string
+}
+
+func prepareFixtureQuery(files []policyFile) (*fixtureQuery, error) {
+	q := &fixtureQuery{
+		rules: map[string]string{},
+	}
+
+	opts := []func(*rego.Rego){
+		rego.Query("data.infracost"),
+	}
+
+	for i, f := range files {
+		b, err := os.ReadFile(f.path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Unable to read policy file %s", f.path)
+		}
+
+		module, err := ast.ParseModule(f.path, string(b))
+		if err != nil {
+			return nil, fmt.Errorf("Unable to parse policy file %s: %s", f.path, err.Error())
+		}
+
+		if module.Package.Path.String() == "data.infracost" {
+			ruleName := ast.Var(fmt.Sprintf("fixture_test_deny_%d", i))
+
+			for _, rule := range module.Rules {
+				if rule.Head.Name != denyRule {
+					continue
+				}
+
+				for r := rule; r != nil; r = r.Else {
+					r.Head.Name = ruleName
+				}
+
+				q.rules[f.path] = string(ruleName)
+			}
+		}
+
+		opts = append(opts, rego.ParsedModule(module))
+	}
+
+	pq, err := rego.New(opts...).PrepareForEval(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("Unable to query provided policies: %s", err.Error())
+	}
+
+	q.query = pq
+
+	return q, nil
+}
+
+func (q *fixtureQuery) hasRules(f policyFile) bool {
+	_, ok := q.rules[f.path]
+	return ok
+}
+
+// eval evaluates the policies against the Infracost output and returns the checks keyed by policy file path.
+func (q *fixtureQuery) eval(input output.Root) (map[string]output.PolicyCheck, error) {
+	inputValue, err := ast.InterfaceToValue(input)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to process Infracost output into Rego input: %s", err.Error())
+	}
+
+	res, err := q.query.Eval(context.Background(), rego.EvalParsedInput(inputValue))
+	if err != nil {
+		return nil, err
+	}
+
+	var values map[string]interface{}
+	if len(res) > 0 && len(res[0].Expressions) > 0 {
+		values, _ = res[0].Expressions[0].Value.(map[string]interface{})
+	}
+
+	checks := make(map[string]output.PolicyCheck, len(q.rules))
+	for path, rule := range q.rules {
+		c := output.PolicyCheck{
+			Enabled: true,
+		}
+		readOutput(values[rule], &c)
+		checks[path] = c
+	}
+
+	return checks, nil
+}
+
+// RunRegoTests runs any OPA test_ rules found in the policyPaths.
+func RunRegoTests(policyPaths []string) ([]TestResult, error) {
+	res, err := tester.Run(context.Background(), policyPaths...)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to run policy tests: %s", err.Error())
+	}
+
+	results := make([]TestResult, 0, len(res))
+	for _, r := range res {
+		if r.Skip {
+			continue
+		}
+
+		result := TestResult{
+			Policy:   r.Package,
+			Name:     r.Name,
+			Expected: OutcomePass,
+			Actual:   OutcomePass,
+			Err:      r.Error,
+		}
+
+		if r.Fail {
+			result.Actual = OutcomeFail
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func outcome(checks output.PolicyCheck) string {
+	if checks.HasFailed() {
+		return OutcomeFail
+	}
+
+	if checks.HasWarnings() {
+		return OutcomeWarn
+	}
+
+	return OutcomePass
+}
+
+func validOutcome(s string) bool {
+	return s == OutcomePass || s == OutcomeWarn || s == OutcomeFail
+}
+
+// policyFile is a Rego policy file found by findPolicyFiles.
+type policyFile struct {
+	// name is the path of the file relative to the policy path it was found in, without the
+	// .rego extension, e.g. prod/cost_increase.
+	name string
+	path string
+}
+
+// findPolicyFiles returns the Rego policy files found at the given paths sorted by name.
+// Test files are ignored.
+func findPolicyFiles(paths []string) ([]policyFile, error) {
+	var files []policyFile
+	seen := map[string]bool{}
+
+	add := func(root, path string) {
+		if filepath.Ext(path) != ".rego" || strings.HasSuffix(path, "_test.rego") || seen[path] {
+			return
+		}
+
+		name, err := filepath.Rel(root, path)
+		if err != nil || name == "." {
+			name = filepath.Base(path)
+		}
+
+		seen[path] = true
+		files = append(files, policyFile{
+			name: filepath.ToSlash(strings.TrimSuffix(name, ".rego")),
+			path: path,
+		})
+	}
+
+	for _, p := range paths {
+		matches, _ := filepath.Glob(p)
+		if len(matches) == 0 {
+			matches = []string{p}
+		}
+
+		for _, m := range matches {
+			info, err := os.Stat(m)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Unable to read policy path %s", m)
+			}
+
+			if !info.IsDir() {
+				add(filepath.Dir(m), m)
+				continue
+			}
+
+			err = filepath.Walk(m, func(path string, info os.FileInfo, err error) error {
+				if err != nil {
+					return err
+				}
+
+				if !info.IsDir() {
+					add(m, path)
+				}
+
+				return nil
+			})
+			if err != nil {
+				return nil, errors.Wrapf(err, "Unable to read policy path %s", m)
+			}
+		}
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].name < files[j].name
+	})
+
+	return files, nil
+}
+
+// lookupPolicyFile returns the policy file for a policy name from a fixture spec. The name can
+// either be the path of the file relative to its policy path or, if it is unique, the file name.
+func lookupPolicyFile(files []policyFile, name string) (policyFile, error) {
+	var matches []policyFile
+
+	for _, f := range files {
+		if f.name == name {
+			return f, nil
+		}
+
+		if path.Base(f.name) == name {
+			matches = append(matches, f)
+		}
+	}
+
+	if len(matches) == 0 {
+		return policyFile{}, fmt.Errorf("no policy file found for %s", name)
+	}
+
+	if len(matches) > 1 {
+		names := make([]string, 0, len(matches))
+		for _, f := range matches {
+			names = append(names, f.name)
+		}
+
+		return policyFile{}, fmt.Errorf("multiple policy files found for %s, use one of %s", name, strings.Join(names, ", "))
+	}
+
+	return matches[0], nil
+}
+
+func loadFixtureSpecs(fixturesPath string) ([]FixtureSpec, error) {
+	var paths []string
+	for _, pattern := range []string{"*.yml", "*.yaml"} {
+		matches, err := filepath.Glob(filepath.Join(fixturesPath, pattern))
+		if err != nil {
+			return nil, err
+		}
+
+		paths = append(paths, matches...)
+	}
+	sort.Strings(paths)
+
+	specs := make([]FixtureSpec, 0, len(paths))
+
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Unable to read fixture spec %s", path)
+		}
+
+		var spec FixtureSpec
+		err = yaml.Unmarshal(b, &spec)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Unable to parse fixture spec %s", path)
+		}
+
+		if spec.Fixture == "" {
+			return nil, fmt.Errorf("Fixture spec %s must set a fixture path", path)
+		}
+
+		spec.name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		spec.path = path
+		specs = append(specs, spec)
+	}
+
+	return specs, nil
+}
+
+func loadFixture(spec FixtureSpec) (output.Root, error) {
+	path := spec.Fixture
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(spec.path), path)
+	}
+
+	inputs, err := output.LoadPaths([]string{path})
+	if err != nil {
+		return output.Root{}, err
+	}
+
+	return output.Combine(inputs)
+}
@@ -0,0 +1,173 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeHarnessFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, contents := range files {
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0700))
+		require.NoError(t, os.WriteFile(p, []byte(contents), 0600))
+	}
+}
+
+var harnessPolicies = map[string]string{
+	"lib/helpers.rego": `package lib
+
+over(limit) {
+	to_number(input.totalMonthlyCost) > limit
+}
+`,
+	"policies/max_cost.rego": `package infracost
+
+import data.lib
+
+deny[out] {
+	out := {
+		"msg": "Total monthly cost must be less than $100",
+		"failed": lib.over(100),
+	}
+}
+`,
+	"policies/cost_warning.rego": `package infracost
+
+import data.lib
+
+deny[out] {
+	out := {
+		"msg": "Total monthly cost is over $10",
+		"failed": lib.over(10),
+		"severity": "warn",
+	}
+}
+`,
+	"policies/cost_warning_test.rego": `package infracost
+
+test_ignored {
+	true
+}
+`,
+	"prod/max_cost.rego": `package infracost
+
+deny[out] {
+	out := {
+		"msg": "Total monthly cost must be less than $1000",
+		"failed": to_number(input.totalMonthlyCost) > 1000,
+	}
+}
+`,
+}
+
+var harnessFixtures = map[string]string{
+	"cheap.json":     `{"version": "0.2", "currency": "USD", "totalMonthlyCost": "5", "projects": []}`,
+	"expensive.json": `{"version": "0.2", "currency": "USD", "totalMonthlyCost": "500", "projects": []}`,
+}
+
+func runHarness(t *testing.T, specs map[string]string) []TestResult {
+	t.Helper()
+
+	dir := t.TempDir()
+	writeHarnessFiles(t, dir, harnessPolicies)
+	writeHarnessFiles(t, filepath.Join(dir, "fixtures"), harnessFixtures)
+	writeHarnessFiles(t, filepath.Join(dir, "fixtures"), specs)
+
+	results, err := RunFixtureTests([]string{dir}, filepath.Join(dir, "fixtures"))
+	require.NoError(t, err)
+
+	return results
+}
+
+func TestRunFixtureTests(t *testing.T) {
+	results := runHarness(t, map[string]string{
+		"cheap.yml": `fixture: cheap.json
+expect:
+  policies/max_cost: pass
+  cost_warning: pass
+  prod/max_cost: pass
+`,
+		"expensive.yml": `fixture: expensive.json
+expect:
+  policies/max_cost: fail
+  cost_warning: warn
+  prod/max_cost: pass
+`,
+	})
+
+	assert.Equal(t, []TestResult{
+		{Policy: "cost_warning", Name: "cheap", Expected: OutcomePass, Actual: OutcomePass},
+		{Policy: "cost_warning", Name: "expensive", Expected: OutcomeWarn, Actual: OutcomeWarn},
+		{Policy: "policies/max_cost", Name: "cheap", Expected: OutcomePass, Actual: OutcomePass},
+		{Policy: "policies/max_cost", Name: "expensive", Expected: OutcomeFail, Actual: OutcomeFail},
+		{Policy: "prod/max_cost", Name: "cheap", Expected: OutcomePass, Actual: OutcomePass},
+		{Policy: "prod/max_cost", Name: "expensive", Expected: OutcomePass, Actual: OutcomePass},
+	}, results)
+
+	for _, r := range results {
+		assert.True(t, r.Passed(), "%s %s", r.Policy, r.Name)
+	}
+}
+
+func TestRunFixtureTestsUnexpectedOutcome(t *testing.T) {
+	results := runHarness(t, map[string]string{
+		"expensive.yml": `fixture: expensive.json
+expect:
+  cost_warning: pass
+`,
+	})
+
+	require.Len(t, results, 1)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, OutcomeWarn, results[0].Actual)
+	assert.False(t, results[0].Passed())
+}
+
+func TestRunFixtureTestsErrors(t *testing.T) {
+	results := runHarness(t, map[string]string{
+		"cheap.yml": `fixture: cheap.json
+expect:
+  missing: pass
+  cost_warning: maybe
+  max_cost: pass
+  helpers: pass
+`,
+	})
+
+	errs := make(map[string]string, len(results))
+	for _, r := range results {
+		assert.False(t, r.Passed(), r.Policy)
+		require.Error(t, r.Err, r.Policy)
+		errs[r.Policy] = r.Err.Error()
+	}
+
+	assert.Equal(t, map[string]string{
+		"missing":      "no policy file found for missing",
+		"cost_warning": "invalid expected outcome 'maybe', valid outcomes are pass, warn and fail",
+		"max_cost":     "multiple policy files found for max_cost, use one of policies/max_cost, prod/max_cost",
+	}, map[string]string{
+		"missing":      errs["missing"],
+		"cost_warning": errs["cost_warning"],
+		"max_cost":     errs["max_cost"],
+	})
+	assert.Contains(t, errs["helpers"], "helpers.rego has no data.infracost.deny rules")
+}
+
+func TestRunFixtureTestsMissingFixture(t *testing.T) {
+	results := runHarness(t, map[string]string{
+		"missing.yml": `fixture: missing.json
+expect:
+  cost_warning: pass
+`,
+	})
+
+	require.Len(t, results, 1)
+	assert.Error(t, results[0].Err)
+	assert.False(t, results[0].Passed())
+}
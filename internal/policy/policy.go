@@ -0,0 +1,155 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+
+	"github.com/infracost/infracost/internal/output"
+)
+
+// Query evaluates the data.infracost.deny rules of the Rego policies found at policyPaths
+// against the Infracost output. Each rule must return an object containing at least
+// {msg: string, failed: bool} and can optionally set policy, resource, project,
+// severity (error or warn) and remediation to give more context about a failure.
+func Query(policyPaths []string, input output.Root) (output.PolicyCheck, error) {
+	checks := output.PolicyCheck{
+		Enabled: true,
+	}
+
+	inputValue, err := ast.InterfaceToValue(input)
+	if err != nil {
+		return checks, fmt.Errorf("Unable to process Infracost output into Rego input: %s", err.Error())
+	}
+
+	ctx := context.Background()
+	r := rego.New(
+		rego.Query("data.infracost.deny"),
+		rego.ParsedInput(inputValue),
+		rego.Load(policyPaths, func(abspath string, info os.FileInfo, depth int) bool {
+			return false
+		}),
+	)
+	pq, err := r.PrepareForEval(ctx)
+	if err != nil {
+		return checks, fmt.Errorf("Unable to query provided policies: %s", err.Error())
+	}
+
+	res, err := pq.Eval(ctx)
+	if err != nil {
+		return checks, err
+	}
+
+	if len(res) == 0 {
+		return checks, fmt.Errorf("The provided polices returned no valid data.infracost.deny rules. Please check that the policies are formatted correctly.")
+	}
+
+	for _, e := range res[0].Expressions {
+		readOutput(e.Value, &checks)
+	}
+
+	return checks, nil
+}
+
+// readOutput adds the results of an evaluated data.infracost.deny rule to checks.
+// The value can either be a single rule output object or a set of them.
+func readOutput(value interface{}, checks *output.PolicyCheck) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		readPolicyOut(v, checks)
+	case []interface{}:
+		for _, ii := range v {
+			if m, ok := ii.(map[string]interface{}); ok {
+				readPolicyOut(m, checks)
+			}
+		}
+	}
+}
+
+func readPolicyOut(v map[string]interface{}, checks *output.PolicyCheck) {
+	invalid := func(msg string) {
+		checks.Failures = append(checks.Failures, output.PolicyCheckResult{
+			Message:  msg,
+			Severity: output.PolicySeverityError,
+		})
+	}
+
+	msg, ok := v["msg"].(string)
+	if !ok {
+		invalid("Policy rule invalid as it did not contain {msg: string} property in output object. Please edit rule output object.")
+		return
+	}
+
+	if _, ok := v["failed"]; !ok {
+		invalid(fmt.Sprintf("Policy rule: [%s] did not contain {failed: bool} output property. Please edit rule output object.", msg))
+		return
+	}
+
+	failed, _ := v["failed"].(bool)
+
+	result := output.PolicyCheckResult{
+		Message:  msg,
+		Severity: output.PolicySeverityError,
+	}
+
+	for _, field := range []struct {
+		name string
+		dst  *string
+	}{
+		{"policy", &result.Policy},
+		{"resource", &result.Resource},
+		{"project", &result.Project},
+		{"remediation", &result.Remediation},
+	} {
+		raw, ok := v[field.name]
+		if !ok {
+			continue
+		}
+
+		s, ok := raw.(string)
+		if !ok {
+			invalid(fmt.Sprintf("Policy rule: [%s] has an invalid {%s: string} output property. Please edit rule output object.", msg, field.name))
+			return
+		}
+
+		*field.dst = s
+	}
+
+	if raw, ok := v["severity"]; ok {
+		s, ok := raw.(string)
+		severity, valid := parseSeverity(s)
+		if !ok || !valid {
+			invalid(fmt.Sprintf("Policy rule: [%s] has an invalid {severity: string} output property, valid values are %s and %s. Please edit rule output object.", msg, output.PolicySeverityError, output.PolicySeverityWarn))
+			return
+		}
+
+		result.Severity = severity
+	}
+
+	if !failed {
+		checks.Passed = append(checks.Passed, result)
+		return
+	}
+
+	if result.Severity == output.PolicySeverityWarn {
+		checks.Warnings = append(checks.Warnings, result)
+		return
+	}
+
+	checks.Failures = append(checks.Failures, result)
+}
+
+func parseSeverity(s string) (string, bool) {
+	switch strings.ToLower(s) {
+	case "", output.PolicySeverityError:
+		return output.PolicySeverityError, true
+	case output.PolicySeverityWarn, "warning":
+		return output.PolicySeverityWarn, true
+	}
+
+	return "", false
+}
@@ -0,0 +1,114 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/infracost/infracost/internal/output"
+)
+
+func TestReadOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected output.PolicyCheck
+	}{
+		{
+			name:  "passed",
+			value: map[string]interface{}{"msg": "ok", "failed": false},
+			expected: output.PolicyCheck{
+				Passed: output.PolicyCheckResults{{Message: "ok", Severity: output.PolicySeverityError}},
+			},
+		},
+		{
+			name: "failed with resource details",
+			value: []interface{}{
+				map[string]interface{}{
+					"msg":         "too expensive",
+					"failed":      true,
+					"policy":      "instance size",
+					"resource":    "aws_instance.web",
+					"project":     "infra/prod",
+					"remediation": "use a smaller instance",
+				},
+			},
+			expected: output.PolicyCheck{
+				Failures: output.PolicyCheckFailures{
+					{
+						Policy:      "instance size",
+						Message:     "too expensive",
+						Resource:    "aws_instance.web",
+						Project:     "infra/prod",
+						Severity:    output.PolicySeverityError,
+						Remediation: "use a smaller instance",
+					},
+				},
+			},
+		},
+		{
+			name:  "warning",
+			value: map[string]interface{}{"msg": "watch out", "failed": true, "severity": "warning"},
+			expected: output.PolicyCheck{
+				Warnings: output.PolicyCheckResults{{Message: "watch out", Severity: output.PolicySeverityWarn}},
+			},
+		},
+		{
+			name:  "missing msg",
+			value: map[string]interface{}{"failed": true},
+			expected: output.PolicyCheck{
+				Failures: output.PolicyCheckFailures{
+					{
+						Message:  "Policy rule invalid as it did not contain {msg: string} property in output object. Please edit rule output object.",
+						Severity: output.PolicySeverityError,
+					},
+				},
+			},
+		},
+		{
+			name:  "invalid severity",
+			value: map[string]interface{}{"msg": "bad", "failed": true, "severity": "critical"},
+			expected: output.PolicyCheck{
+				Failures: output.PolicyCheckFailures{
+					{
+						Message:  "Policy rule: [bad] has an invalid {severity: string} output property, valid values are error and warn. Please edit rule output object.",
+						Severity: output.PolicySeverityError,
+					},
+				},
+			},
+		},
+		{
+			name:  "non-string severity",
+			value: map[string]interface{}{"msg": "bad", "failed": true, "severity": true},
+			expected: output.PolicyCheck{
+				Failures: output.PolicyCheckFailures{
+					{
+						Message:  "Policy rule: [bad] has an invalid {severity: string} output property, valid values are error and warn. Please edit rule output object.",
+						Severity: output.PolicySeverityError,
+					},
+				},
+			},
+		},
+		{
+			name:  "invalid resource",
+			value: map[string]interface{}{"msg": "bad", "failed": true, "resource": 1},
+			expected: output.PolicyCheck{
+				Failures: output.PolicyCheckFailures{
+					{
+						Message:  "Policy rule: [bad] has an invalid {resource: string} output property. Please edit rule output object.",
+						Severity: output.PolicySeverityError,
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var checks output.PolicyCheck
+			readOutput(tt.value, &checks)
+
+			assert.Equal(t, tt.expected, checks)
+		})
+	}
+}
@@ -103,6 +103,7 @@ func setCostComponentPrice(ctx *config.RunContext, currency string, r *schema.Re
 		log.Warnf("No products found for %s %s, using 0.00", r.Name, c.Name)
 		setResourceWarningEvent(ctx, r, "No products found")
 		c.SetPrice(decimal.Zero)
+		c.SetMissingPrice(true)
 		return
 	}
 	if len(products) > 1 {
@@ -121,6 +122,7 @@ func setCostComponentPrice(ctx *config.RunContext, currency string, r *schema.Re
 		log.Warnf("No prices found for %s %s, using 0.00", r.Name, c.Name)
 		setResourceWarningEvent(ctx, r, "No prices found")
 		c.SetPrice(decimal.Zero)
+		c.SetMissingPrice(true)
 		return
 	}
 	if len(prices) > 1 {
@@ -134,6 +136,7 @@ func setCostComponentPrice(ctx *config.RunContext, currency string, r *schema.Re
 		log.Warnf("Error converting price to '%v' (using 0.00)  '%v': %s", currency, prices[0].Get(currency).String(), err.Error())
 		setResourceWarningEvent(ctx, r, "Error converting price")
 		c.SetPrice(decimal.Zero)
+		c.SetMissingPrice(true)
 		return
 	}
 
@@ -37,10 +37,10 @@ func (p *TemplateProvider) LoadResources(usage map[string]*schema.UsageData) ([]
 		return []*schema.Project{}, errors.Wrap(err, "Error reading Cloudformation template file")
 	}
 
-	metadata := config.DetectProjectMetadata(p.ctx.ProjectConfig.Path)
+	metadata := config.DetectProjectMetadata(p.ctx.ProjectConfig.Path, p.ctx.ProjectConfig)
 	metadata.Type = p.Type()
 	p.AddMetadata(metadata)
-	name := schema.GenerateProjectName(metadata, p.ctx.RunContext.Config.EnableDashboard)
+	name := p.ctx.ProjectName(metadata)
 
 	project := schema.NewProject(name, metadata)
 	parser := NewParser(p.ctx)
@@ -26,6 +26,10 @@ func Detect(ctx *config.ProjectContext) (schema.Provider, error) {
 	}
 
 	if ctx.ProjectConfig.TerraformParseHCL {
+		if isTerragruntDir(ctx.ProjectConfig, path) || (!isTerraformDir(path) && isTerragruntNestedDir(ctx.ProjectConfig, path, 5)) {
+			return terraform.NewTerragruntHCLProvider(ctx)
+		}
+
 		return terraform.NewHCLProvider(ctx, terraform.NewPlanJSONProvider(ctx))
 	}
 
@@ -45,7 +49,7 @@ func Detect(ctx *config.ProjectContext) (schema.Provider, error) {
 		return terraform.NewPlanProvider(ctx), nil
 	}
 
-	if isTerragruntDir(path) {
+	if isTerragruntDir(ctx.ProjectConfig, path) {
 		return terraform.NewTerragruntProvider(ctx), nil
 	}
 
@@ -53,7 +57,7 @@ func Detect(ctx *config.ProjectContext) (schema.Provider, error) {
 		return terraform.NewDirProvider(ctx), nil
 	}
 
-	if isTerragruntNestedDir(path, 5) {
+	if isTerragruntNestedDir(ctx.ProjectConfig, path, 5) {
 		return terraform.NewTerragruntProvider(ctx), nil
 	}
 
@@ -126,8 +130,8 @@ func isTerraformPlan(path string) bool {
 	return planFile != nil
 }
 
-func isTerragruntDir(path string) bool {
-	if val, ok := os.LookupEnv("TERRAGRUNT_CONFIG"); ok {
+func isTerragruntDir(projectCfg *config.Project, path string) bool {
+	if val, ok := projectCfg.LookupEnv("TERRAGRUNT_CONFIG"); ok {
 		if filepath.IsAbs(val) {
 			return config.FileExists(val)
 		}
@@ -137,8 +141,8 @@ func isTerragruntDir(path string) bool {
 	return config.FileExists(filepath.Join(path, "terragrunt.hcl")) || config.FileExists(filepath.Join(path, "terragrunt.hcl.json"))
 }
 
-func isTerragruntNestedDir(path string, maxDepth int) bool {
-	if isTerragruntDir(path) {
+func isTerragruntNestedDir(projectCfg *config.Project, path string, maxDepth int) bool {
+	if isTerragruntDir(projectCfg, path) {
 		return true
 	}
 
@@ -147,7 +151,7 @@ func isTerragruntNestedDir(path string, maxDepth int) bool {
 		if err == nil {
 			for _, entry := range entries {
 				if entry.IsDir() {
-					if isTerragruntNestedDir(filepath.Join(path, entry.Name()), maxDepth-1) {
+					if isTerragruntNestedDir(projectCfg, filepath.Join(path, entry.Name()), maxDepth-1) {
 						return true
 					}
 				}
@@ -0,0 +1,321 @@
+package providers
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	log "github.com/sirupsen/logrus"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/infracost/infracost/internal/config"
+)
+
+// discoverSkipDirs are directories that are never searched for projects since they hold third party code.
+// Hidden directories, e.g. .terraform and .terragrunt-cache, are also skipped since they hold copies of the
+// Terraform code made by Terraform, Terragrunt or Infracost.
+var discoverSkipDirs = []string{"node_modules", "vendor"}
+
+var discoverFileSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "terraform"},
+		{Type: "provider", LabelNames: []string{"name"}},
+		{Type: "module", LabelNames: []string{"name"}},
+	},
+}
+
+var discoverTerraformSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "backend", LabelNames: []string{"type"}},
+		{Type: "cloud"},
+	},
+}
+
+var discoverModuleSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "source"},
+	},
+}
+
+// DiscoverOptions sets which of the discovered projects are returned by DiscoverProjects.
+type DiscoverOptions struct {
+	// IncludePaths are glob patterns matched against the project path relative to the root path. If set,
+	// only projects that match at least one of the patterns are returned.
+	IncludePaths []string
+	// ExcludePaths are glob patterns matched against the project path relative to the root path. Projects
+	// that match any of the patterns are not returned.
+	ExcludePaths []string
+}
+
+// discoveredDir holds what DiscoverProjects has found out about a directory in the repo.
+type discoveredDir struct {
+	hasTerraform  bool
+	hasBackend    bool
+	hasProvider   bool
+	hasTerragrunt bool
+	varFiles      []string
+}
+
+// DiscoverProjects walks the path and returns a project for each Terraform root module and Terragrunt leaf
+// config it finds. A Terraform root module is a directory with a backend, or a directory with a provider
+// config that isn't called as a module by another directory. If a root module has .tfvars files that
+// Terraform doesn't load automatically, e.g. dev.tfvars and prod.tfvars, a project is returned for each of
+// them. A Terragrunt leaf is a directory with a terragrunt.hcl file and no terragrunt.hcl files below it.
+func DiscoverProjects(path string, opts DiscoverOptions) ([]*config.Project, error) {
+	dirs := make(map[string]*discoveredDir)
+	calledModules := make(map[string]bool)
+	var varFiles []string
+
+	parser := hclparse.NewParser()
+
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if p != path && (strings.HasPrefix(d.Name(), ".") || containsString(discoverSkipDirs, d.Name())) {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		dir := filepath.Dir(p)
+		name := d.Name()
+
+		switch {
+		case strings.HasSuffix(name, ".tf") || strings.HasSuffix(name, ".tf.json"):
+			info := discoverDir(dirs, dir)
+			info.hasTerraform = true
+
+			for _, source := range inspectTerraformFile(parser, p, info) {
+				calledModules[filepath.Join(dir, source)] = true
+			}
+		case name == "terragrunt.hcl" || name == "terragrunt.hcl.json":
+			discoverDir(dirs, dir).hasTerragrunt = true
+		case isVarFileVariant(name):
+			varFiles = append(varFiles, p)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error discovering projects in %s: %w", path, err)
+	}
+
+	for _, varFile := range varFiles {
+		if root := varFileRoot(dirs, path, filepath.Dir(varFile)); root != nil {
+			root.varFiles = append(root.varFiles, varFile)
+		}
+	}
+
+	var projects []*config.Project
+
+	for dir, info := range dirs {
+		if !isDiscoveredProject(dirs, calledModules, dir, info) {
+			continue
+		}
+
+		rel, err := filepath.Rel(path, dir)
+		if err != nil {
+			return nil, err
+		}
+
+		if !matchesDiscoverOptions(filepath.ToSlash(rel), opts) {
+			log.Debugf("Skipping discovered project %s since it doesn't match the include and exclude paths", dir)
+			continue
+		}
+
+		if info.hasTerragrunt || len(info.varFiles) == 0 {
+			projects = append(projects, &config.Project{Path: dir})
+			continue
+		}
+
+		for _, varFile := range info.varFiles {
+			relVarFile, err := filepath.Rel(dir, varFile)
+			if err != nil {
+				return nil, err
+			}
+
+			projects = append(projects, &config.Project{
+				Name:               fmt.Sprintf("%s (%s)", dir, varFileEnv(relVarFile)),
+				Path:               dir,
+				TerraformPlanFlags: fmt.Sprintf("-var-file=%s", filepath.ToSlash(relVarFile)),
+			})
+		}
+	}
+
+	sort.Slice(projects, func(i, j int) bool {
+		if projects[i].Path != projects[j].Path {
+			return projects[i].Path < projects[j].Path
+		}
+
+		return projects[i].TerraformPlanFlags < projects[j].TerraformPlanFlags
+	})
+
+	return projects, nil
+}
+
+func discoverDir(dirs map[string]*discoveredDir, dir string) *discoveredDir {
+	if _, ok := dirs[dir]; !ok {
+		dirs[dir] = &discoveredDir{}
+	}
+
+	return dirs[dir]
+}
+
+// inspectTerraformFile records whether the file has a backend or provider config and returns the sources of
+// the local modules it calls. Files that can't be parsed are ignored, since we only need a best guess here.
+func inspectTerraformFile(parser *hclparse.Parser, filename string, info *discoveredDir) []string {
+	var file *hcl.File
+	var diags hcl.Diagnostics
+
+	if strings.HasSuffix(filename, ".json") {
+		file, diags = parser.ParseJSONFile(filename)
+	} else {
+		file, diags = parser.ParseHCLFile(filename)
+	}
+
+	if diags.HasErrors() {
+		log.Debugf("Could not parse %s when discovering projects: %s", filename, diags.Error())
+		return nil
+	}
+
+	content, _, _ := file.Body.PartialContent(discoverFileSchema)
+
+	var sources []string
+
+	for _, block := range content.Blocks {
+		switch block.Type {
+		case "terraform":
+			tfContent, _, _ := block.Body.PartialContent(discoverTerraformSchema)
+			if len(tfContent.Blocks) > 0 {
+				info.hasBackend = true
+			}
+		case "provider":
+			info.hasProvider = true
+		case "module":
+			moduleContent, _, _ := block.Body.PartialContent(discoverModuleSchema)
+			attr, ok := moduleContent.Attributes["source"]
+			if !ok {
+				continue
+			}
+
+			val, diags := attr.Expr.Value(nil)
+			if diags.HasErrors() || !val.IsKnown() || val.IsNull() || !val.Type().Equals(cty.String) {
+				continue
+			}
+
+			source := val.AsString()
+			if strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../") {
+				sources = append(sources, filepath.FromSlash(source))
+			}
+		}
+	}
+
+	return sources
+}
+
+// isVarFileVariant returns true if the file is a var file that Terraform doesn't load automatically, so it's
+// likely to be one of the environments the root module is deployed with.
+func isVarFileVariant(name string) bool {
+	if !strings.HasSuffix(name, ".tfvars") && !strings.HasSuffix(name, ".tfvars.json") {
+		return false
+	}
+
+	if name == "terraform.tfvars" || name == "terraform.tfvars.json" {
+		return false
+	}
+
+	return !strings.HasSuffix(name, ".auto.tfvars") && !strings.HasSuffix(name, ".auto.tfvars.json")
+}
+
+// varFileEnv returns the name of the environment that the var file is for, which is its path relative
+// to the root module without the extension, e.g. dev for dev.tfvars and envs/staging for envs/staging.tfvars.
+func varFileEnv(relVarFile string) string {
+	name := filepath.ToSlash(relVarFile)
+	name = strings.TrimSuffix(name, ".json")
+	return strings.TrimSuffix(name, ".tfvars")
+}
+
+// varFileRoot returns the directory that the var file found in dir belongs to. This is the closest
+// directory at or above dir that has Terraform files, so var files kept in a subdirectory of the root
+// module, e.g. envs/dev.tfvars, are also used.
+func varFileRoot(dirs map[string]*discoveredDir, path string, dir string) *discoveredDir {
+	for {
+		if info, ok := dirs[dir]; ok && (info.hasTerraform || info.hasTerragrunt) {
+			return info
+		}
+
+		if dir == path || dir == filepath.Dir(dir) {
+			return nil
+		}
+
+		dir = filepath.Dir(dir)
+	}
+}
+
+// isDiscoveredProject returns true if the directory is a Terraform root module or a Terragrunt leaf config.
+func isDiscoveredProject(dirs map[string]*discoveredDir, calledModules map[string]bool, dir string, info *discoveredDir) bool {
+	if info.hasTerragrunt {
+		for other, otherInfo := range dirs {
+			if otherInfo.hasTerragrunt && other != dir && strings.HasPrefix(other, dir+string(filepath.Separator)) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	if !info.hasTerraform {
+		return false
+	}
+
+	return info.hasBackend || (info.hasProvider && !calledModules[dir])
+}
+
+func matchesDiscoverOptions(rel string, opts DiscoverOptions) bool {
+	for _, pattern := range opts.ExcludePaths {
+		if globMatch(pattern, rel) {
+			return false
+		}
+	}
+
+	if len(opts.IncludePaths) == 0 {
+		return true
+	}
+
+	for _, pattern := range opts.IncludePaths {
+		if globMatch(pattern, rel) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func globMatch(pattern string, rel string) bool {
+	ok, err := doublestar.Match(strings.TrimPrefix(filepath.ToSlash(pattern), "./"), rel)
+	if err != nil {
+		log.Warnf("Invalid path pattern %s: %s", pattern, err)
+		return false
+	}
+
+	return ok
+}
+
+func containsString(s []string, e string) bool {
+	for _, a := range s {
+		if a == e {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,81 @@
+package providers
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var discoverTestPath = "testdata/discover_test"
+
+type discoveredProject struct {
+	path      string
+	planFlags string
+}
+
+func discoverTestProjects(t *testing.T, opts DiscoverOptions) []discoveredProject {
+	t.Helper()
+
+	projects, err := DiscoverProjects(discoverTestPath, opts)
+	require.NoError(t, err)
+
+	actual := make([]discoveredProject, 0, len(projects))
+	for _, p := range projects {
+		rel, err := filepath.Rel(discoverTestPath, p.Path)
+		require.NoError(t, err)
+
+		actual = append(actual, discoveredProject{path: filepath.ToSlash(rel), planFlags: p.TerraformPlanFlags})
+	}
+
+	return actual
+}
+
+func TestDiscoverProjects(t *testing.T) {
+	assert.Equal(t, []discoveredProject{
+		{path: "app"},
+		{path: "examples/basic"},
+		{path: "infra", planFlags: "-var-file=dev.tfvars"},
+		{path: "infra", planFlags: "-var-file=envs/staging.tfvars"},
+		{path: "infra", planFlags: "-var-file=prod.tfvars"},
+		{path: "live/dev/app"},
+		{path: "live/dev/db"},
+	}, discoverTestProjects(t, DiscoverOptions{}))
+}
+
+func TestDiscoverProjectsIncludeExclude(t *testing.T) {
+	assert.Equal(t, []discoveredProject{
+		{path: "app"},
+		{path: "infra", planFlags: "-var-file=dev.tfvars"},
+		{path: "infra", planFlags: "-var-file=envs/staging.tfvars"},
+		{path: "infra", planFlags: "-var-file=prod.tfvars"},
+	}, discoverTestProjects(t, DiscoverOptions{
+		ExcludePaths: []string{"examples/**", "live/**"},
+	}))
+
+	assert.Equal(t, []discoveredProject{
+		{path: "live/dev/db"},
+	}, discoverTestProjects(t, DiscoverOptions{
+		IncludePaths: []string{"live/**"},
+		ExcludePaths: []string{"**/app"},
+	}))
+}
+
+func TestDiscoverProjectsVarFileNames(t *testing.T) {
+	projects, err := DiscoverProjects(discoverTestPath, DiscoverOptions{IncludePaths: []string{"infra"}})
+	require.NoError(t, err)
+
+	infra := filepath.Join(discoverTestPath, "infra")
+
+	names := make([]string, 0, len(projects))
+	for _, p := range projects {
+		names = append(names, p.Name)
+	}
+
+	assert.Equal(t, []string{
+		infra + " (dev)",
+		infra + " (envs/staging)",
+		infra + " (prod)",
+	}, names)
+}
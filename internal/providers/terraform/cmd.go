@@ -137,7 +137,10 @@ func (w *cmdLogWriter) Flush() {
 	}
 }
 
-func CreateConfigFile(dir string, terraformCloudHost string, terraformCloudToken string) (string, error) {
+// CreateConfigFile creates a temporary Terraform CLI config file with the credentials for the Terraform Cloud
+// host. If TF_CLI_CONFIG_FILE is set in the project env or process environment, the existing config is copied
+// into the new file.
+func CreateConfigFile(dir string, terraformCloudHost string, terraformCloudToken string, env map[string]string) (string, error) {
 	if terraformCloudToken == "" {
 		return "", nil
 	}
@@ -148,9 +151,13 @@ func CreateConfigFile(dir string, terraformCloudHost string, terraformCloudToken
 		return "", err
 	}
 
-	if os.Getenv("TF_CLI_CONFIG_FILE") != "" {
-		log.Debugf("TF_CLI_CONFIG_FILE is set, copying existing config from %s to config to temporary config file %s", os.Getenv("TF_CLI_CONFIG_FILE"), tmpFile.Name())
-		path := os.Getenv("TF_CLI_CONFIG_FILE")
+	path, ok := env["TF_CLI_CONFIG_FILE"]
+	if !ok {
+		path = os.Getenv("TF_CLI_CONFIG_FILE")
+	}
+
+	if path != "" {
+		log.Debugf("TF_CLI_CONFIG_FILE is set, copying existing config from %s to config to temporary config file %s", path, tmpFile.Name())
 
 		if !filepath.IsAbs(path) {
 			path, err = filepath.Abs(filepath.Join(dir, path))
@@ -112,11 +112,13 @@ func (p *DirProvider) AddMetadata(metadata *schema.ProjectMetadata) {
 	terraformWorkspace := p.Workspace
 
 	if terraformWorkspace == "" {
-		binary := p.TerraformBinary
-		cmd := exec.Command(binary, "workspace", "show")
-		cmd.Dir = p.Path
+		opts := &CmdOptions{
+			TerraformBinary: p.TerraformBinary,
+			Dir:             p.Path,
+			Env:             p.Env,
+		}
 
-		out, err := cmd.Output()
+		out, err := Cmd(opts, "workspace", "show")
 		if err != nil {
 			log.Debugf("Could not detect Terraform workspace for %s", p.Path)
 		}
@@ -156,14 +158,17 @@ func (p *DirProvider) LoadResources(usage map[string]*schema.UsageData) ([]*sche
 	}
 
 	for _, j := range jsons {
-		metadata := config.DetectProjectMetadata(p.ctx.ProjectConfig.Path)
+		metadata := config.DetectProjectMetadata(p.ctx.ProjectConfig.Path, p.ctx.ProjectConfig)
 		metadata.Type = p.Type()
 		p.AddMetadata(metadata)
-		name := schema.GenerateProjectName(metadata, p.ctx.RunContext.Config.EnableDashboard)
+		name := p.ctx.ProjectName(metadata)
 
 		project := schema.NewProject(name, metadata)
 
 		parser := NewParser(p.ctx)
+		if !p.IsTerragrunt {
+			parser.sourceDir = p.Path
+		}
 		pastResources, resources, err := parser.parseJSON(j, usage)
 		if err != nil {
 			return projects, errors.Wrap(err, "Error parsing Terraform JSON")
@@ -276,7 +281,7 @@ func (p *DirProvider) buildCommandOpts(path string) (*CmdOptions, error) {
 		Env:                p.Env,
 	}
 
-	cfgFile, err := CreateConfigFile(p.Path, p.TerraformCloudHost, p.TerraformCloudToken)
+	cfgFile, err := CreateConfigFile(p.Path, p.TerraformCloudHost, p.TerraformCloudToken, p.Env)
 	if err != nil {
 		return opts, err
 	}
@@ -389,7 +394,7 @@ func (p *DirProvider) runInit(opts *CmdOptions, spinner *ui.Spinner) error {
 }
 
 func (p *DirProvider) runRemotePlan(opts *CmdOptions, args []string) ([]byte, error) {
-	if p.TerraformCloudToken == "" && !checkCloudConfigSet() {
+	if p.TerraformCloudToken == "" && !checkCloudConfigSet(p.Env) {
 		return []byte{}, ErrMissingCloudToken
 	}
 
@@ -414,7 +419,7 @@ func (p *DirProvider) runRemotePlan(opts *CmdOptions, args []string) ([]byte, er
 
 	token := p.TerraformCloudToken
 	if token == "" {
-		token = findCloudToken(host)
+		token = findCloudToken(host, p.Env)
 	}
 	if token == "" {
 		return []byte{}, ErrMissingCloudToken
@@ -0,0 +1,445 @@
+package terraform
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+)
+
+// generatedAttributes are the attributes the HCL parser sets to unique values on
+// every resource. They are ignored when checking if a resource has changed.
+var generatedAttributes = []string{"id", "arn"}
+
+// checkoutGitRef extracts the files of the git repository containing path at the given
+// ref into a temporary directory using git archive, so the working tree and .git directory
+// are left untouched. It returns the location of path inside the extracted tree and a
+// function that removes the temporary directory.
+func checkoutGitRef(path string, ref string) (string, func(), error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", nil, err
+	}
+
+	topLevel, err := runGit(absPath, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", nil, fmt.Errorf("%s is not inside a git repository: %w", path, err)
+	}
+
+	commit, err := runGit(absPath, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return "", nil, fmt.Errorf("could not find git revision %s", ref)
+	}
+
+	subPath, err := filepath.Rel(topLevel, absPath)
+	if err != nil {
+		return "", nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "infracost-compare-to")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() {
+		os.RemoveAll(dir)
+	}
+
+	log.Debugf("Extracting git revision %s (%s) to %s", ref, commit, dir)
+
+	cmd := exec.Command("git", "archive", "--format=tar", commit)
+	cmd.Dir = topLevel
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	extractErr := extractTar(stdout, dir)
+	waitErr := cmd.Wait()
+
+	if extractErr != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("could not extract git revision %s: %w", ref, extractErr)
+	}
+
+	if waitErr != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("could not archive git revision %s: %s", ref, strings.TrimSpace(stderr.String()))
+	}
+
+	return filepath.Join(dir, subPath), cleanup, nil
+}
+
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+func extractTar(r io.Reader, dest string) error {
+	tr := tar.NewReader(r)
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dest, filepath.FromSlash(hdr.Name))
+		if !isWithinDir(dest, target) {
+			return fmt.Errorf("invalid path in archive: %s", hdr.Name)
+		}
+
+		// Entries are never written through a symlink, otherwise a symlink earlier in the
+		// archive could be used to write outside of dest.
+		err = checkNoSymlinks(dest, filepath.Dir(target))
+		if err != nil {
+			return fmt.Errorf("invalid path in archive: %s: %w", hdr.Name, err)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, 0755)
+		case tar.TypeSymlink:
+			link := filepath.FromSlash(hdr.Linkname)
+			if filepath.IsAbs(link) || !isWithinDir(dest, filepath.Join(filepath.Dir(target), link)) {
+				return fmt.Errorf("invalid symlink in archive: %s -> %s", hdr.Name, hdr.Linkname)
+			}
+
+			err = os.Symlink(hdr.Linkname, target)
+		case tar.TypeReg:
+			err = writeTarFile(tr, target, os.FileMode(hdr.Mode))
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// isWithinDir returns true if the path is dir or is inside dir.
+func isWithinDir(dir string, path string) bool {
+	dir = filepath.Clean(dir)
+	path = filepath.Clean(path)
+
+	return path == dir || strings.HasPrefix(path, dir+string(os.PathSeparator))
+}
+
+// checkNoSymlinks returns an error if any existing directory from dir down to path is a symlink.
+func checkNoSymlinks(dir string, path string) error {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return err
+	}
+
+	if rel == "." {
+		return nil
+	}
+
+	current := dir
+	for _, part := range strings.Split(rel, string(os.PathSeparator)) {
+		current = filepath.Join(current, part)
+
+		info, err := os.Lstat(current)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("%s is a symlink", current)
+		}
+	}
+
+	return nil
+}
+
+func writeTarFile(r io.Reader, target string, mode os.FileMode) error {
+	err := os.MkdirAll(filepath.Dir(target), 0755)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, r) // nolint:gosec
+	return err
+}
+
+// diffResourceChanges compares the resource changes of the prior and planned
+// configurations and sets the create, update, delete or no-op action for each resource.
+func diffResourceChanges(prior []ResourceChangesJSON, planned []ResourceChangesJSON) []ResourceChangesJSON {
+	priorMap := make(map[string]ResourceChangesJSON, len(prior))
+	for _, c := range prior {
+		priorMap[c.Address] = c
+	}
+
+	priorGenerated := generatedValues(prior)
+	plannedGenerated := generatedValues(planned)
+
+	changes := make([]ResourceChangesJSON, 0, len(planned)+len(prior))
+	seen := make(map[string]bool, len(planned))
+
+	for _, c := range planned {
+		seen[c.Address] = true
+
+		p, ok := priorMap[c.Address]
+		if ok {
+			// The resource was already at this address in the prior configuration, so any moved
+			// block for it has been applied before and it hasn't moved in this change.
+			c.PreviousAddress = nil
+		} else if c.PreviousAddress != nil {
+			p, ok = priorMap[*c.PreviousAddress]
+			if ok {
+				seen[p.Address] = true
+			}
+		}
+		if !ok {
+			c.Change.Actions = []string{"create"}
+			changes = append(changes, c)
+			continue
+		}
+
+		c.Change.Before = p.Change.After
+		if valuesEqual(p.Change.After, c.Change.After, priorGenerated, plannedGenerated) {
+			c.Change.Actions = []string{"no-op"}
+		} else {
+			c.Change.Actions = []string{"update"}
+		}
+
+		changes = append(changes, c)
+	}
+
+	for _, p := range prior {
+		if seen[p.Address] {
+			continue
+		}
+
+		p.Change.Actions = []string{"delete"}
+		p.Change.Before = p.Change.After
+		p.Change.After = nil
+		changes = append(changes, p)
+	}
+
+	return changes
+}
+
+// generatedValues returns the values of the generated attributes of the resources mapped
+// to a reference to the attribute, e.g. aws_vpc.main.id. Resources that were moved are
+// referenced by their previous address, so references to them are the same in the prior
+// and planned values.
+func generatedValues(changes []ResourceChangesJSON) map[string]string {
+	values := make(map[string]string)
+
+	for _, c := range changes {
+		addr := c.Address
+		if c.PreviousAddress != nil && *c.PreviousAddress != "" {
+			addr = *c.PreviousAddress
+		}
+
+		for _, attr := range generatedAttributes {
+			if s, ok := decodeValue(c.Change.After[attr]).(string); ok && s != "" {
+				values[s] = addr + "." + attr
+			}
+		}
+	}
+
+	return values
+}
+
+// valuesEqual returns true if the values are equal ignoring the generated attributes of the
+// resources. Any generated value referenced by the values, e.g. vpc_id = aws_vpc.main.id, is
+// replaced with the reference so the unique values of each parse aren't compared.
+func valuesEqual(a, b map[string]interface{}, aGenerated, bGenerated map[string]string) bool {
+	return reflect.DeepEqual(
+		maskGeneratedValues(decodeValue(withoutGeneratedAttributes(a)), newGeneratedReplacer(aGenerated)),
+		maskGeneratedValues(decodeValue(withoutGeneratedAttributes(b)), newGeneratedReplacer(bGenerated)),
+	)
+}
+
+// decodeValue returns the value with any JSON encoded attribute values decoded, so the values
+// can be walked. If the value can't be decoded it is returned as is.
+func decodeValue(v interface{}) interface{} {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+
+	var decoded interface{}
+	err = json.Unmarshal(b, &decoded)
+	if err != nil {
+		return v
+	}
+
+	return decoded
+}
+
+func withoutGeneratedAttributes(values map[string]interface{}) map[string]interface{} {
+	m := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		if containsString(generatedAttributes, k) {
+			continue
+		}
+
+		m[k] = v
+	}
+
+	return m
+}
+
+// newGeneratedReplacer returns a replacer of the generated values with their references. The
+// longest values are replaced first, since values of resources with a count are suffixed with
+// the index, e.g. the value for index 1 is a prefix of the value for index 10.
+func newGeneratedReplacer(generated map[string]string) *strings.Replacer {
+	values := make([]string, 0, len(generated))
+	for v := range generated {
+		values = append(values, v)
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		if len(values[i]) != len(values[j]) {
+			return len(values[i]) > len(values[j])
+		}
+
+		return values[i] < values[j]
+	})
+
+	oldnew := make([]string, 0, len(values)*2)
+	for _, v := range values {
+		oldnew = append(oldnew, v, "${"+generated[v]+"}")
+	}
+
+	return strings.NewReplacer(oldnew...)
+}
+
+func maskGeneratedValues(v interface{}, r *strings.Replacer) interface{} {
+	switch t := v.(type) {
+	case string:
+		return r.Replace(t)
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, child := range t {
+			m[k] = maskGeneratedValues(child, r)
+		}
+
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(t))
+		for i, child := range t {
+			l[i] = maskGeneratedValues(child, r)
+		}
+
+		return l
+	}
+
+	return v
+}
+
+// priorStateModule returns the planned values of the prior schema to use as the prior state.
+// The plan only has the configuration of the current revision, so the region of each AWS
+// resource is resolved from the prior provider configuration and set in its values.
+func priorStateModule(prior *PlanSchema) PlanModule {
+	b, err := json.Marshal(prior.Configuration)
+	if err != nil {
+		log.Debugf("Could not marshal prior configuration: %s", err)
+		return prior.PlannedValues.RootModule
+	}
+
+	conf := gjson.ParseBytes(b)
+
+	return withResourceRegions(prior.PlannedValues.RootModule, conf.Get("provider_config"), conf.Get("root_module"))
+}
+
+func withResourceRegions(module PlanModule, providerConf, conf gjson.Result) PlanModule {
+	resources := make([]ResourceJSON, len(module.Resources))
+	for i, r := range module.Resources {
+		if _, ok := r.Values["region"]; !ok && strings.HasPrefix(r.Type, "aws_") {
+			region := providerRegion(r.Address, providerConf, gjson.Result{}, r.Type, getConfJSON(conf, r.Address))
+			if region != "" {
+				values := make(map[string]interface{}, len(r.Values)+1)
+				for k, v := range r.Values {
+					values[k] = v
+				}
+				values["region"] = region
+				r.Values = values
+			}
+		}
+
+		resources[i] = r
+	}
+
+	module.Resources = resources
+
+	children := make([]PlanModule, len(module.ChildModules))
+	for i, m := range module.ChildModules {
+		children[i] = withResourceRegions(m, providerConf, conf)
+	}
+	module.ChildModules = children
+
+	return module
+}
+
+// mergeModuleConfig adds the configuration of the resources and module calls in src that are
+// not in dst, so resources that were deleted since the prior revision keep their configuration.
+func mergeModuleConfig(dst *ModuleConfig, src ModuleConfig) {
+	addresses := make(map[string]bool, len(dst.Resources))
+	for _, r := range dst.Resources {
+		addresses[r.Address] = true
+	}
+
+	for _, r := range src.Resources {
+		if !addresses[r.Address] {
+			dst.Resources = append(dst.Resources, r)
+		}
+	}
+
+	for name, call := range src.ModuleCalls {
+		if dst.ModuleCalls == nil {
+			dst.ModuleCalls = map[string]ModuleCall{}
+		}
+
+		existing, ok := dst.ModuleCalls[name]
+		if !ok {
+			dst.ModuleCalls[name] = call
+			continue
+		}
+
+		mergeModuleConfig(&existing.ModuleConfig, call.ModuleConfig)
+		dst.ModuleCalls[name] = existing
+	}
+}
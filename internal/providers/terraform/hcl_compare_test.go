@@ -0,0 +1,109 @@
+package terraform
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tarEntry struct {
+	name     string
+	typeflag byte
+	linkname string
+	body     string
+}
+
+func testTar(t *testing.T, entries []tarEntry) *bytes.Buffer {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+
+	for _, e := range entries {
+		err := tw.WriteHeader(&tar.Header{
+			Name:     e.name,
+			Typeflag: e.typeflag,
+			Linkname: e.linkname,
+			Mode:     0644,
+			Size:     int64(len(e.body)),
+		})
+		require.NoError(t, err)
+
+		_, err = tw.Write([]byte(e.body))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+
+	return &buf
+}
+
+func TestExtractTar(t *testing.T) {
+	dest := t.TempDir()
+
+	err := extractTar(testTar(t, []tarEntry{
+		{name: "modules/", typeflag: tar.TypeDir},
+		{name: "modules/main.tf", typeflag: tar.TypeReg, body: "// main"},
+		{name: "main.tf", typeflag: tar.TypeSymlink, linkname: "modules/main.tf"},
+	}), dest)
+	require.NoError(t, err)
+
+	b, err := os.ReadFile(filepath.Join(dest, "main.tf"))
+	require.NoError(t, err)
+	assert.Equal(t, "// main", string(b))
+}
+
+func TestExtractTarMalicious(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []tarEntry
+	}{
+		{
+			name: "path outside dest",
+			entries: []tarEntry{
+				{name: "../evil.tf", typeflag: tar.TypeReg, body: "evil"},
+			},
+		},
+		{
+			name: "absolute symlink",
+			entries: []tarEntry{
+				{name: "escape", typeflag: tar.TypeSymlink, linkname: "/tmp"},
+				{name: "escape/evil.tf", typeflag: tar.TypeReg, body: "evil"},
+			},
+		},
+		{
+			name: "relative symlink outside dest",
+			entries: []tarEntry{
+				{name: "escape", typeflag: tar.TypeSymlink, linkname: "../outside"},
+				{name: "escape/evil.tf", typeflag: tar.TypeReg, body: "evil"},
+			},
+		},
+		{
+			name: "write through symlink inside dest",
+			entries: []tarEntry{
+				{name: "self", typeflag: tar.TypeSymlink, linkname: "."},
+				{name: "self/escape", typeflag: tar.TypeSymlink, linkname: "../outside"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := t.TempDir()
+			dest := filepath.Join(parent, "checkout")
+			outside := filepath.Join(parent, "outside")
+			require.NoError(t, os.Mkdir(dest, 0755))
+			require.NoError(t, os.Mkdir(outside, 0755))
+
+			err := extractTar(testTar(t, tt.entries), dest)
+			assert.Error(t, err)
+
+			assert.NoFileExists(t, filepath.Join(parent, "evil.tf"))
+			assert.NoFileExists(t, filepath.Join(outside, "evil.tf"))
+			assert.NoFileExists(t, filepath.Join(dest, "escape", "evil.tf"))
+		})
+	}
+}
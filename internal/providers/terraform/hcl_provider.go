@@ -4,9 +4,11 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"os"
 	"regexp"
 	"strings"
 
+	log "github.com/sirupsen/logrus"
 	"github.com/zclconf/go-cty/cty"
 	ctyJson "github.com/zclconf/go-cty/cty/json"
 
@@ -21,6 +23,15 @@ type HCLProvider struct {
 
 	schema      *PlanSchema
 	providerKey string
+	compareTo   string
+	options     []hcl.Option
+	path        string
+	// missingVariables are the required variables of the root module that were not set in the last parse
+	missingVariables []string
+	// unknownAttributes are the attributes that could not be resolved in the last parse, keyed by resource address
+	unknownAttributes map[string][]string
+	// sourceMetadata is the location of the resources in the last parse, keyed by resource address
+	sourceMetadata map[string]map[string]string
 }
 
 type flagStringSlice []string
@@ -61,12 +72,40 @@ func varsFromPlanFlags(planFlags string) (vars, error) {
 // It will use input flags from either the terraform-plan-flags or top level var and var-file flags to
 // set input vars and files on the underlying hcl.Parser.
 func NewHCLProvider(ctx *config.ProjectContext, provider *PlanJSONProvider) (*HCLProvider, error) {
+	options, err := hclOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p := hcl.New(ctx.ProjectConfig.Path, options...)
+
+	return &HCLProvider{
+		Parser:    p,
+		Provider:  provider,
+		compareTo: ctx.ProjectConfig.TerraformCompareTo,
+		options:   options,
+		path:      ctx.ProjectConfig.Path,
+	}, err
+}
+
+// hclOptions returns the hcl.Parser options for the project, setting the input vars and files from
+// either the terraform-plan-flags or top level var and var-file flags. The project env is passed to the
+// parser rather than the process environment, so that projects parsed in parallel are isolated.
+func hclOptions(ctx *config.ProjectContext) ([]hcl.Option, error) {
 	v, err := varsFromPlanFlags(ctx.ProjectConfig.TerraformPlanFlags)
 	if err != nil {
 		return nil, fmt.Errorf("could not parse vars from plan flags %w", err)
 	}
 
-	var options []hcl.Option
+	options := []hcl.Option{hcl.OptionWithEnv(ctx.ProjectConfig.Env)}
+
+	workspace := ctx.ProjectConfig.TerraformWorkspace
+	if workspace == "" {
+		workspace, _ = ctx.ProjectConfig.LookupEnv("TF_WORKSPACE")
+	}
+	if workspace != "" {
+		options = append(options, hcl.OptionWithWorkspaceName(workspace))
+	}
 	v.files = append(v.files, ctx.ProjectConfig.TerraformVarFiles...)
 	if len(v.files) > 0 {
 		withFiles := hcl.OptionWithTFVarsPaths(v.files)
@@ -79,12 +118,11 @@ func NewHCLProvider(ctx *config.ProjectContext, provider *PlanJSONProvider) (*HC
 		options = append(options, withVars)
 	}
 
-	p := hcl.New(ctx.ProjectConfig.Path, options...)
+	if dir := ctx.RunContext.Config.ModuleCacheDir(); dir != "" {
+		options = append(options, hcl.OptionWithModuleCacheDir(dir))
+	}
 
-	return &HCLProvider{
-		Parser:   p,
-		Provider: provider,
-	}, err
+	return options, nil
 }
 
 func (p *HCLProvider) Type() string                                 { return "terraform_hcl" }
@@ -100,17 +138,88 @@ func (p *HCLProvider) LoadResources(usage map[string]*schema.UsageData) ([]*sche
 		return nil, err
 	}
 
-	return p.Provider.LoadResourcesFromSrc(usage, b, nil)
+	projects, err := p.Provider.LoadResourcesFromSrc(usage, b, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, project := range projects {
+		project.Metadata.MissingVariables = p.missingVariables
+
+		for _, r := range project.Resources {
+			r.UnknownAttributes = p.unknownAttributes[r.Name]
+		}
+
+		setSourceMetadata(project.Resources, p.sourceMetadata)
+	}
+
+	return projects, nil
 }
 
 // LoadPlanJSON parses the provided directory and returns it as a Terraform Plan JSON.
+// If a git ref to compare to is set, the directory is also parsed at that ref and
+// returned as the prior state of the plan so that the cost diff can be calculated.
 func (p *HCLProvider) LoadPlanJSON() ([]byte, error) {
 	rootModule, err := p.Parser.ParseDirectory()
 	if err != nil {
 		return nil, err
 	}
 
-	return p.modulesToPlanJSON(rootModule)
+	p.missingVariables = rootModule.MissingVariables
+	p.unknownAttributes = make(map[string][]string)
+	collectUnknownAttributes(rootModule, p.unknownAttributes)
+	p.sourceMetadata = make(map[string]map[string]string)
+	collectSourceMetadata(rootModule, p.path, p.sourceMetadata)
+
+	if p.compareTo == "" {
+		return p.modulesToPlanJSON(rootModule, nil)
+	}
+
+	priorModule, err := p.parseCompareTo()
+	if err != nil {
+		return nil, err
+	}
+
+	return p.modulesToPlanJSON(rootModule, priorModule)
+}
+
+// parseCompareTo parses the directory as it was at the compareTo git ref. If the
+// directory did not exist at that ref then nil is returned, so every resource is
+// shown as created.
+func (p *HCLProvider) parseCompareTo() (*hcl.Module, error) {
+	priorPath, cleanup, err := checkoutGitRef(p.path, p.compareTo)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	if _, err := os.Stat(priorPath); os.IsNotExist(err) {
+		log.Debugf("Path %s does not exist at %s, treating all resources as new", p.path, p.compareTo)
+		return nil, nil
+	}
+
+	priorModule, err := hcl.New(priorPath, p.options...).ParseDirectory()
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s at %s %w", p.path, p.compareTo, err)
+	}
+
+	return priorModule, nil
+}
+
+// collectUnknownAttributes adds the unknown attributes of the resources in the module and its child modules to
+// the unknownAttributes map, keyed by resource address.
+func collectUnknownAttributes(module *hcl.Module, unknownAttributes map[string][]string) {
+	for _, block := range module.Blocks {
+		if block.Type() != "resource" || len(block.UnknownAttributes()) == 0 {
+			continue
+		}
+
+		unknownAttributes[block.FullName()] = block.UnknownAttributes()
+	}
+
+	for _, m := range module.Modules {
+		collectUnknownAttributes(m, unknownAttributes)
+	}
 }
 
 func (p *HCLProvider) newPlanSchema() {
@@ -139,13 +248,37 @@ func (p *HCLProvider) newPlanSchema() {
 	p.providerKey = ""
 }
 
-func (p *HCLProvider) modulesToPlanJSON(rootModule *hcl.Module) ([]byte, error) {
+func (p *HCLProvider) modulesToPlanJSON(rootModule *hcl.Module, priorModule *hcl.Module) ([]byte, error) {
+	var prior *PlanSchema
+	if p.compareTo != "" && priorModule != nil {
+		// The prior module is marshalled into its own schema so the provider and resource
+		// configuration at the compared revision are kept separate from the current ones.
+		p.newPlanSchema()
+		mo := p.marshalModule(priorModule, nil)
+		p.schema.Configuration.RootModule = mo.ModuleConfig
+		p.schema.PlannedValues.RootModule = mo.PlanModule
+		prior = p.schema
+	}
+
 	p.newPlanSchema()
 
-	mo := p.marshalModule(rootModule)
+	mo := p.marshalModule(rootModule, nil)
 	p.schema.Configuration.RootModule = mo.ModuleConfig
 	p.schema.PlannedValues.RootModule = mo.PlanModule
 
+	if p.compareTo != "" {
+		p.schema.PriorState = &PriorState{}
+
+		var priorChanges []ResourceChangesJSON
+		if prior != nil {
+			p.schema.PriorState.Values.RootModule = priorStateModule(prior)
+			mergeModuleConfig(&p.schema.Configuration.RootModule, prior.Configuration.RootModule)
+			priorChanges = prior.ResourceChanges
+		}
+
+		p.schema.ResourceChanges = diffResourceChanges(priorChanges, p.schema.ResourceChanges)
+	}
+
 	b, err := json.MarshalIndent(p.schema, "", "  ")
 	if err != nil {
 		return nil, fmt.Errorf("error handling built plan json from hcl %w", err)
@@ -153,7 +286,11 @@ func (p *HCLProvider) modulesToPlanJSON(rootModule *hcl.Module) ([]byte, error)
 	return b, nil
 }
 
-func (p *HCLProvider) marshalModule(module *hcl.Module) ModuleOut {
+// marshalModule marshals the module and its child modules. moved are the moved blocks of the parent
+// modules with absolute addresses, since a moved block can move a resource into a child module.
+func (p *HCLProvider) marshalModule(module *hcl.Module, moved []hcl.Moved) ModuleOut {
+	moved = append(moved[:len(moved):len(moved)], absoluteMovedBlocks(module)...)
+
 	moduleConfig := ModuleConfig{
 		ModuleCalls: map[string]ModuleCall{},
 	}
@@ -181,15 +318,15 @@ func (p *HCLProvider) marshalModule(module *hcl.Module) ModuleOut {
 
 			planModule.Resources = append(planModule.Resources, out.Planned)
 
+			out.Changes.PreviousAddress = newString(previousAddress(out.Changes.Address, moved))
 			p.schema.ResourceChanges = append(p.schema.ResourceChanges, out.Changes)
 		}
 	}
 
 	for _, m := range module.Modules {
-		pieces := strings.Split(m.Name, ".")
-		modKey := pieces[len(pieces)-1]
+		modKey := moduleCallName(m.Name)
 
-		mo := p.marshalModule(m)
+		mo := p.marshalModule(m, moved)
 
 		moduleConfig.ModuleCalls[modKey] = ModuleCall{
 			Source:       m.Source,
@@ -205,6 +342,38 @@ func (p *HCLProvider) marshalModule(module *hcl.Module) ModuleOut {
 	}
 }
 
+// absoluteMovedBlocks returns the moved blocks of the module with the addresses prefixed with the module address.
+func absoluteMovedBlocks(module *hcl.Module) []hcl.Moved {
+	moved := module.MovedBlocks()
+	if module.Name == "" {
+		return moved
+	}
+
+	for i := range moved {
+		moved[i].From = module.Name + "." + moved[i].From
+		moved[i].To = module.Name + "." + moved[i].To
+	}
+
+	return moved
+}
+
+// previousAddress returns the address the resource had before it was moved by one of the moved blocks,
+// or an empty string if it wasn't moved. A moved block for a module call or a resource without an index
+// moves all the resources in the module or all the instances of the resource.
+func previousAddress(addr string, moved []hcl.Moved) string {
+	for _, m := range moved {
+		if addr == m.To {
+			return m.From
+		}
+
+		if strings.HasPrefix(addr, m.To+".") || strings.HasPrefix(addr, m.To+"[") {
+			return m.From + strings.TrimPrefix(addr, m.To)
+		}
+	}
+
+	return ""
+}
+
 func (p *HCLProvider) getResourceOutput(block *hcl.Block) ResourceOutput {
 	planned := ResourceJSON{
 		Address:       block.FullName(),
@@ -436,13 +605,14 @@ type ResourceJSON struct {
 }
 
 type ResourceChangesJSON struct {
-	Address       string         `json:"address"`
-	ModuleAddress *string        `json:"module_address,omitempty"`
-	Mode          string         `json:"mode"`
-	Type          string         `json:"type"`
-	Name          string         `json:"name"`
-	Index         *int64         `json:"index,omitempty"`
-	Change        ResourceChange `json:"change"`
+	Address         string         `json:"address"`
+	PreviousAddress *string        `json:"previous_address,omitempty"`
+	ModuleAddress   *string        `json:"module_address,omitempty"`
+	Mode            string         `json:"mode"`
+	Type            string         `json:"type"`
+	Name            string         `json:"name"`
+	Index           *int64         `json:"index,omitempty"`
+	Change          ResourceChange `json:"change"`
 }
 
 type ResourceChange struct {
@@ -458,10 +628,17 @@ type PlanSchema struct {
 	PlannedValues    struct {
 		RootModule PlanModule `json:"root_module"`
 	} `json:"planned_values"`
+	PriorState      *PriorState           `json:"prior_state,omitempty"`
 	ResourceChanges []ResourceChangesJSON `json:"resource_changes"`
 	Configuration   Configuration         `json:"configuration"`
 }
 
+type PriorState struct {
+	Values struct {
+		RootModule PlanModule `json:"root_module"`
+	} `json:"values"`
+}
+
 type PlanModule struct {
 	Resources    []ResourceJSON `json:"resources,omitempty"`
 	Address      *string        `json:"address,omitempty"`
@@ -526,3 +703,17 @@ var countRegex = regexp.MustCompile(`\[\d+\]$`)
 func stripCount(s string) string {
 	return countRegex.ReplaceAllString(s, "")
 }
+
+// moduleCallName returns the name of the module call from a module address, without any count
+// or for_each index, e.g. module.parent["a"].module.child[0] returns child. All instances of a
+// module call share the same module configuration.
+func moduleCallName(addr string) string {
+	pieces := SplitAddress(addr)
+	name := pieces[len(pieces)-1]
+
+	if i := strings.Index(name, "["); i != -1 {
+		return name[:i]
+	}
+
+	return name
+}
@@ -2,7 +2,10 @@ package terraform
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"os"
+	"os/exec"
 	"path"
 	"strings"
 	"testing"
@@ -181,3 +184,435 @@ func TestHCLProvider_LoadPlanJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestHCLProvider_LoadPlanJSONCompareTo(t *testing.T) {
+	dir := t.TempDir()
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	writeFile := func(contents string) {
+		err := os.WriteFile(path.Join(dir, "main.tf"), []byte(contents), 0600)
+		require.NoError(t, err)
+	}
+
+	git("init", "-q")
+	writeFile(`
+resource "aws_instance" "unchanged" {
+  instance_type = "t3.micro"
+}
+
+resource "aws_instance" "updated" {
+  instance_type = "t3.micro"
+}
+
+resource "aws_instance" "deleted" {
+  instance_type = "t3.micro"
+}
+`)
+	git("add", "main.tf")
+	git("commit", "-q", "-m", "base")
+
+	writeFile(`
+resource "aws_instance" "unchanged" {
+  instance_type = "t3.micro"
+}
+
+resource "aws_instance" "updated" {
+  instance_type = "m5.large"
+}
+
+resource "aws_instance" "created" {
+  instance_type = "t3.micro"
+}
+`)
+
+	p := HCLProvider{
+		Parser:    hcl.New(dir),
+		compareTo: "HEAD",
+		path:      dir,
+	}
+	got, err := p.LoadPlanJSON()
+	require.NoError(t, err)
+
+	var plan PlanSchema
+	err = json.Unmarshal(got, &plan)
+	require.NoError(t, err)
+
+	require.NotNil(t, plan.PriorState)
+	assert.Len(t, plan.PriorState.Values.RootModule.Resources, 3)
+	assert.Len(t, plan.PlannedValues.RootModule.Resources, 3)
+
+	actions := map[string][]string{}
+	for _, c := range plan.ResourceChanges {
+		actions[c.Address] = c.Change.Actions
+	}
+
+	assert.Equal(t, map[string][]string{
+		"aws_instance.unchanged": {"no-op"},
+		"aws_instance.updated":   {"update"},
+		"aws_instance.created":   {"create"},
+		"aws_instance.deleted":   {"delete"},
+	}, actions)
+}
+
+func TestHCLProvider_LoadPlanJSONCompareToMoved(t *testing.T) {
+	dir := t.TempDir()
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	writeFile := func(contents string) {
+		err := os.WriteFile(path.Join(dir, "main.tf"), []byte(contents), 0600)
+		require.NoError(t, err)
+	}
+
+	git("init", "-q")
+	writeFile(`
+resource "aws_instance" "web" {
+  instance_type = "t3.micro"
+}
+`)
+	git("add", "main.tf")
+	git("commit", "-q", "-m", "base")
+
+	writeFile(`
+resource "aws_instance" "app" {
+  instance_type = "t3.micro"
+}
+
+moved {
+  from = aws_instance.web
+  to   = aws_instance.app
+}
+`)
+
+	p := HCLProvider{
+		Parser:    hcl.New(dir),
+		compareTo: "HEAD",
+		path:      dir,
+	}
+	got, err := p.LoadPlanJSON()
+	require.NoError(t, err)
+
+	var plan PlanSchema
+	err = json.Unmarshal(got, &plan)
+	require.NoError(t, err)
+
+	require.Len(t, plan.ResourceChanges, 1)
+	c := plan.ResourceChanges[0]
+	assert.Equal(t, "aws_instance.app", c.Address)
+	require.NotNil(t, c.PreviousAddress)
+	assert.Equal(t, "aws_instance.web", *c.PreviousAddress)
+	assert.Equal(t, []string{"no-op"}, c.Change.Actions)
+}
+
+func TestHCLProvider_LoadPlanJSONCompareToAppliedMoved(t *testing.T) {
+	dir := t.TempDir()
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	writeFile := func(contents string) {
+		err := os.WriteFile(path.Join(dir, "main.tf"), []byte(contents), 0600)
+		require.NoError(t, err)
+	}
+
+	// The moved block is kept in both revisions after it has been applied, as recommended by Terraform.
+	git("init", "-q")
+	writeFile(`
+resource "aws_instance" "app" {
+  instance_type = "t3.micro"
+}
+
+moved {
+  from = aws_instance.web
+  to   = aws_instance.app
+}
+`)
+	git("add", "main.tf")
+	git("commit", "-q", "-m", "base")
+
+	writeFile(`
+resource "aws_instance" "app" {
+  instance_type = "m5.large"
+}
+
+moved {
+  from = aws_instance.web
+  to   = aws_instance.app
+}
+`)
+
+	p := HCLProvider{
+		Parser:    hcl.New(dir),
+		compareTo: "HEAD",
+		path:      dir,
+	}
+	got, err := p.LoadPlanJSON()
+	require.NoError(t, err)
+
+	var plan PlanSchema
+	err = json.Unmarshal(got, &plan)
+	require.NoError(t, err)
+
+	require.Len(t, plan.ResourceChanges, 1)
+	c := plan.ResourceChanges[0]
+	assert.Equal(t, "aws_instance.app", c.Address)
+	assert.Nil(t, c.PreviousAddress)
+	assert.Equal(t, []string{"update"}, c.Change.Actions)
+}
+
+func TestHCLProvider_LoadPlanJSONCompareToReferences(t *testing.T) {
+	dir := t.TempDir()
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	writeFile := func(contents string) {
+		err := os.WriteFile(path.Join(dir, "main.tf"), []byte(contents), 0600)
+		require.NoError(t, err)
+	}
+
+	git("init", "-q")
+	writeFile(`
+resource "aws_vpc" "main" {
+  cidr_block = "10.0.0.0/16"
+}
+
+resource "aws_vpc" "other" {
+  cidr_block = "10.1.0.0/16"
+}
+
+resource "aws_subnet" "public" {
+  count      = 2
+  vpc_id     = aws_vpc.main.id
+  cidr_block = "10.0.${count.index}.0/24"
+}
+
+resource "aws_subnet" "private" {
+  vpc_id     = aws_vpc.main.id
+  cidr_block = "10.0.10.0/24"
+}
+
+resource "aws_route_table" "public" {
+  vpc_id = aws_vpc.main.id
+
+  route {
+    cidr_block = "0.0.0.0/0"
+    gateway_id = "igw-${aws_subnet.public[1].id}"
+  }
+}
+`)
+	git("add", "main.tf")
+	git("commit", "-q", "-m", "base")
+
+	writeFile(`
+resource "aws_vpc" "main" {
+  cidr_block = "10.0.0.0/16"
+}
+
+resource "aws_vpc" "other" {
+  cidr_block = "10.1.0.0/16"
+}
+
+resource "aws_subnet" "public" {
+  count      = 2
+  vpc_id     = aws_vpc.main.id
+  cidr_block = "10.0.${count.index}.0/24"
+}
+
+resource "aws_subnet" "private" {
+  vpc_id     = aws_vpc.other.id
+  cidr_block = "10.0.10.0/24"
+}
+
+resource "aws_route_table" "public" {
+  vpc_id = aws_vpc.main.id
+
+  route {
+    cidr_block = "0.0.0.0/0"
+    gateway_id = "igw-${aws_subnet.public[1].id}"
+  }
+}
+`)
+
+	p := HCLProvider{
+		Parser:    hcl.New(dir),
+		compareTo: "HEAD",
+		path:      dir,
+	}
+	got, err := p.LoadPlanJSON()
+	require.NoError(t, err)
+
+	var plan PlanSchema
+	err = json.Unmarshal(got, &plan)
+	require.NoError(t, err)
+
+	actions := map[string][]string{}
+	for _, c := range plan.ResourceChanges {
+		actions[c.Address] = c.Change.Actions
+	}
+
+	assert.Equal(t, map[string][]string{
+		"aws_vpc.main":           {"no-op"},
+		"aws_vpc.other":          {"no-op"},
+		"aws_subnet.public[0]":   {"no-op"},
+		"aws_subnet.public[1]":   {"no-op"},
+		"aws_subnet.private":     {"update"},
+		"aws_route_table.public": {"no-op"},
+	}, actions)
+}
+
+func TestHCLProvider_LoadPlanJSONCompareToPriorConfiguration(t *testing.T) {
+	dir := t.TempDir()
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	writeFile := func(contents string) {
+		err := os.WriteFile(path.Join(dir, "main.tf"), []byte(contents), 0600)
+		require.NoError(t, err)
+	}
+
+	git("init", "-q")
+	writeFile(`
+provider "aws" {
+  region = "us-west-2"
+}
+
+resource "aws_instance" "web" {
+  instance_type = "t3.micro"
+}
+
+resource "aws_instance" "deleted" {
+  instance_type = "t3.micro"
+}
+`)
+	git("add", "main.tf")
+	git("commit", "-q", "-m", "base")
+
+	writeFile(`
+provider "aws" {
+  region = "eu-west-1"
+}
+
+resource "aws_instance" "web" {
+  instance_type = "t3.micro"
+}
+`)
+
+	p := HCLProvider{
+		Parser:    hcl.New(dir),
+		compareTo: "HEAD",
+		path:      dir,
+	}
+	got, err := p.LoadPlanJSON()
+	require.NoError(t, err)
+
+	var plan PlanSchema
+	err = json.Unmarshal(got, &plan)
+	require.NoError(t, err)
+
+	regions := map[string]interface{}{}
+	for _, r := range plan.PriorState.Values.RootModule.Resources {
+		regions[r.Address] = r.Values["region"]
+	}
+	assert.Equal(t, map[string]interface{}{
+		"aws_instance.web":     "us-west-2",
+		"aws_instance.deleted": "us-west-2",
+	}, regions)
+
+	for _, r := range plan.PlannedValues.RootModule.Resources {
+		assert.NotContains(t, r.Values, "region", r.Address)
+	}
+
+	assert.Equal(t, "eu-west-1", plan.Configuration.ProviderConfig["aws"].Expressions["region"].(map[string]interface{})["constant_value"])
+
+	addresses := make([]string, 0, len(plan.Configuration.RootModule.Resources))
+	for _, r := range plan.Configuration.RootModule.Resources {
+		addresses = append(addresses, r.Address)
+	}
+	assert.ElementsMatch(t, []string{"aws_instance.web", "aws_instance.deleted"}, addresses)
+}
+
+func TestHCLProvider_LoadPlanJSONCompareToUnknownRef(t *testing.T) {
+	dir := t.TempDir()
+
+	cmd := exec.Command("git", "init", "-q")
+	cmd.Dir = dir
+	require.NoError(t, cmd.Run())
+
+	err := os.WriteFile(path.Join(dir, "main.tf"), []byte(`resource "aws_instance" "web" {}`), 0600)
+	require.NoError(t, err)
+
+	p := HCLProvider{
+		Parser:    hcl.New(dir),
+		compareTo: "does-not-exist",
+		path:      dir,
+	}
+	_, err = p.LoadPlanJSON()
+	assert.EqualError(t, err, "could not find git revision does-not-exist")
+}
+
+func TestModuleCallName(t *testing.T) {
+	tests := []struct {
+		address  string
+		expected string
+	}{
+		{"module.svc", "svc"},
+		{"module.svc[0]", "svc"},
+		{"module.svc[\"a\"]", "svc"},
+		{"module.svc[\"a.b\"]", "svc"},
+		{"module.parent[\"x\"].module.child[1]", "child"},
+		{"module.parent.module.child", "child"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, moduleCallName(test.address))
+	}
+}
+
+func TestPreviousAddress(t *testing.T) {
+	moved := []hcl.Moved{
+		{From: "aws_instance.web", To: "module.web.aws_instance.web"},
+		{From: "module.old", To: "module.new"},
+		{From: "aws_instance.db", To: "aws_instance.database"},
+	}
+
+	tests := []struct {
+		address  string
+		expected string
+	}{
+		{"module.web.aws_instance.web", "aws_instance.web"},
+		{"module.new.aws_instance.app", "module.old.aws_instance.app"},
+		{"module.new[0].aws_instance.app", "module.old[0].aws_instance.app"},
+		{"aws_instance.database[1]", "aws_instance.db[1]"},
+		{"aws_instance.databases", ""},
+		{"aws_instance.other", ""},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, previousAddress(test.address, moved), test.address)
+	}
+}
@@ -40,10 +40,13 @@ var arnAttributeMap = map[string]string{
 type Parser struct {
 	ctx              *config.ProjectContext
 	terraformVersion string
+	// sourceDir is the directory of the Terraform files of the plan, used to find the location of the
+	// resources. The locations aren't set if this is empty.
+	sourceDir string
 }
 
 func NewParser(ctx *config.ProjectContext) *Parser {
-	return &Parser{ctx, ""}
+	return &Parser{ctx: ctx}
 }
 
 func (p *Parser) createResource(d *schema.ResourceData, u *schema.UsageData) *schema.Resource {
@@ -71,6 +74,7 @@ func (p *Parser) createResource(d *schema.ResourceData, u *schema.UsageData) *sc
 			res.Tags = d.Tags
 			if u != nil {
 				res.EstimationSummary = u.CalcEstimationSummary()
+				res.AppliedUsage = u.AppliedValues(res.UsageSchema)
 			}
 			return res
 		}
@@ -155,10 +159,30 @@ func (p *Parser) parseJSON(j []byte, usage map[string]*schema.UsageData) ([]*sch
 
 	resourceChanges := parsed.Get("resource_changes").Array()
 	pastResources = stripNonTargetResources(pastResources, resources, resourceChanges)
+	setMovedFrom(resources, resourceChanges)
+	setSourceMetadata(resources, planSourceMetadata(p.sourceDir, conf))
 
 	return pastResources, resources, nil
 }
 
+// setMovedFrom sets the address the resources had before they were moved from the previous_address
+// of the resource changes. Terraform sets this when a resource is moved with a moved block or
+// terraform state mv.
+func setMovedFrom(resources []*schema.Resource, resourceChanges []gjson.Result) {
+	previousAddrMap := make(map[string]string)
+	for _, change := range resourceChanges {
+		if prev := change.Get("previous_address").String(); prev != "" {
+			previousAddrMap[change.Get("address").String()] = prev
+		}
+	}
+
+	for _, resource := range resources {
+		if prev, ok := previousAddrMap[resource.Name]; ok {
+			resource.MovedFrom = prev
+		}
+	}
+}
+
 // StripSetupTerraformWrapper removes any output added from the setup-terraform
 // GitHub action terraform wrapper, so we can parse the output of this as
 // valid JSON. It returns the stripped out JSON and a boolean that is true
@@ -207,6 +231,11 @@ func stripNonTargetResources(pastResources []*schema.Resource, resources []*sche
 	diffAddrMap := make(map[string]bool, len(resourceChanges))
 	for _, change := range resourceChanges {
 		diffAddrMap[change.Get("address").String()] = true
+
+		// Keep the past resources of moved resources so their cost is diffed against the new address.
+		if prev := change.Get("previous_address").String(); prev != "" {
+			diffAddrMap[prev] = true
+		}
 	}
 
 	var filteredResources []*schema.Resource
@@ -607,7 +636,7 @@ func isInfracostResource(res *schema.ResourceData) bool {
 // addressResourcePart parses a resource addr and returns resource suffix (without the module prefix).
 // For example: `module.name1.module.name2.resource` will return `name2.resource`.
 func addressResourcePart(addr string) string {
-	p := splitAddress(addr)
+	p := SplitAddress(addr)
 
 	if len(p) >= 3 && p[len(p)-3] == "data" {
 		return strings.Join(p[len(p)-3:], ".")
@@ -619,7 +648,7 @@ func addressResourcePart(addr string) string {
 // addressModulePart parses a resource addr and returns module prefix.
 // For example: `module.name1.module.name2.resource` will return `module.name1.module.name2.`.
 func addressModulePart(addr string) string {
-	ap := splitAddress(addr)
+	ap := SplitAddress(addr)
 
 	var mp []string
 
@@ -673,7 +702,7 @@ func removeAddressArrayPart(addr string) string {
 }
 
 // splitAddress splits the address by `.`, but ignores any `.`s quoted in the array part of the address
-func splitAddress(addr string) []string {
+func SplitAddress(addr string) []string {
 	quoted := false
 	return strings.FieldsFunc(addr, func(r rune) bool {
 		if r == '"' {
@@ -3,6 +3,7 @@ package terraform
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/infracost/infracost/internal/config"
 	"github.com/infracost/infracost/internal/schema"
@@ -48,17 +49,25 @@ func (p *PlanJSONProvider) LoadResources(usage map[string]*schema.UsageData) ([]
 		return []*schema.Project{}, fmt.Errorf("Error reading Terraform plan JSON file %w", err)
 	}
 
-	return p.LoadResourcesFromSrc(usage, j, spinner)
+	// The Terraform files are usually in the same directory as the plan JSON file.
+	return p.loadResources(usage, j, spinner, filepath.Dir(p.Path))
 }
 
+// LoadResourcesFromSrc loads the resources from plan JSON that was generated by Infracost, e.g. from HCL.
+// The locations of the resources aren't parsed from the Terraform files since the caller already knows them.
 func (p *PlanJSONProvider) LoadResourcesFromSrc(usage map[string]*schema.UsageData, j []byte, spinner *ui.Spinner) ([]*schema.Project, error) {
-	metadata := config.DetectProjectMetadata(p.ctx.ProjectConfig.Path)
+	return p.loadResources(usage, j, spinner, "")
+}
+
+func (p *PlanJSONProvider) loadResources(usage map[string]*schema.UsageData, j []byte, spinner *ui.Spinner, sourceDir string) ([]*schema.Project, error) {
+	metadata := config.DetectProjectMetadata(p.ctx.ProjectConfig.Path, p.ctx.ProjectConfig)
 	metadata.Type = p.Type()
 	p.AddMetadata(metadata)
-	name := schema.GenerateProjectName(metadata, p.ctx.RunContext.Config.EnableDashboard)
+	name := p.ctx.ProjectName(metadata)
 
 	project := schema.NewProject(name, metadata)
 	parser := NewParser(p.ctx)
+	parser.sourceDir = sourceDir
 
 	pastResources, resources, err := parser.parseJSON(j, usage)
 	if err != nil {
@@ -50,13 +50,14 @@ func (p *PlanProvider) LoadResources(usage map[string]*schema.UsageData) ([]*sch
 	})
 	defer spinner.Fail()
 
-	metadata := config.DetectProjectMetadata(p.ctx.ProjectConfig.Path)
+	metadata := config.DetectProjectMetadata(p.ctx.ProjectConfig.Path, p.ctx.ProjectConfig)
 	metadata.Type = p.Type()
 	p.AddMetadata(metadata)
-	name := schema.GenerateProjectName(metadata, p.ctx.RunContext.Config.EnableDashboard)
+	name := p.ctx.ProjectName(metadata)
 
 	project := schema.NewProject(name, metadata)
 	parser := NewParser(p.ctx)
+	parser.sourceDir = filepath.Dir(p.Path)
 
 	pastResources, resources, err := parser.parseJSON(j, usage)
 	if err != nil {
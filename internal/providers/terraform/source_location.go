@@ -0,0 +1,248 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	hcl2 "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+
+	"github.com/infracost/infracost/internal/hcl"
+	"github.com/infracost/infracost/internal/schema"
+)
+
+// sourceMetadata returns the resource metadata for the location of the resource in the Terraform files.
+// The filenames are relative to the project directory so they can be used by editors and code scanning
+// tools. rng is nil if the file of the resource is unknown, e.g. if the module hasn't been downloaded,
+// and moduleCall is nil for resources in the root module.
+func sourceMetadata(projectDir string, rng *hcl2.Range, moduleSource string, moduleCall *hcl2.Range) map[string]string {
+	metadata := map[string]string{}
+
+	if rng != nil {
+		metadata["filename"] = relativeFilename(projectDir, rng.Filename)
+		metadata["startLine"] = strconv.Itoa(rng.Start.Line)
+		metadata["endLine"] = strconv.Itoa(rng.End.Line)
+	}
+
+	if moduleSource != "" {
+		metadata["moduleSource"] = moduleSource
+	}
+
+	if moduleCall != nil {
+		metadata["moduleCallLocation"] = fmt.Sprintf("%s:%d", relativeFilename(projectDir, moduleCall.Filename), moduleCall.Start.Line)
+	}
+
+	return metadata
+}
+
+func relativeFilename(projectDir, filename string) string {
+	if rel, err := filepath.Rel(projectDir, filename); err == nil {
+		filename = rel
+	}
+
+	return filepath.ToSlash(filename)
+}
+
+// collectSourceMetadata adds the source location metadata of the resources in the module and its child
+// modules to the metadata map, keyed by resource address.
+func collectSourceMetadata(module *hcl.Module, projectDir string, metadata map[string]map[string]string) {
+	for _, block := range module.Blocks {
+		if block.Type() != "resource" {
+			continue
+		}
+
+		rng := block.Range()
+
+		var moduleCall *hcl2.Range
+		if block.HasModuleBlock() {
+			r := block.ModuleBlock().Range()
+			moduleCall = &r
+		}
+
+		metadata[block.FullName()] = sourceMetadata(projectDir, &rng, block.ModuleSource(), moduleCall)
+	}
+
+	for _, m := range module.Modules {
+		collectSourceMetadata(m, projectDir, metadata)
+	}
+}
+
+// setSourceMetadata sets the source location metadata of the resources. The metadata is keyed by
+// the resource address, or by the address without any count or for_each index for the metadata
+// from the plan JSON configuration.
+func setSourceMetadata(resources []*schema.Resource, metadata map[string]map[string]string) {
+	for _, r := range resources {
+		m, ok := metadata[r.Name]
+		if !ok {
+			m, ok = metadata[configAddress(r.Name)]
+		}
+
+		if !ok || len(m) == 0 {
+			continue
+		}
+
+		if r.Metadata == nil {
+			r.Metadata = make(map[string]string, len(m))
+		}
+
+		for k, v := range m {
+			r.Metadata[k] = v
+		}
+	}
+}
+
+// configAddress returns the address of the resource in the configuration, i.e. without any count or
+// for_each index, e.g. module.web[0].aws_instance.web["a"] returns module.web.aws_instance.web.
+func configAddress(addr string) string {
+	parts := SplitAddress(addr)
+	for i, p := range parts {
+		if j := strings.Index(p, "["); j != -1 {
+			parts[i] = p[:j]
+		}
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// planSourceMetadata returns the source location metadata of the resources in the plan JSON configuration,
+// keyed by the resource address without any count or for_each index. The plan JSON doesn't include the
+// location of the resources, so the Terraform files of the modules are parsed without evaluating them.
+// Remote modules are found using the module manifest created by terraform init.
+func planSourceMetadata(dir string, conf gjson.Result) map[string]map[string]string {
+	metadata := make(map[string]map[string]string)
+	if dir == "" || !conf.Exists() {
+		return metadata
+	}
+
+	manifest := loadModuleManifest(dir)
+	addModuleSourceMetadata(metadata, dir, dir, "", "", conf, "", nil, manifest)
+
+	return metadata
+}
+
+func addModuleSourceMetadata(metadata map[string]map[string]string, projectDir, moduleDir, prefix, key string, conf gjson.Result, moduleSource string, moduleCall *hcl2.Range, manifest map[string]string) {
+	var ranges map[string]hcl2.Range
+	if moduleDir != "" {
+		ranges = parseBlockRanges(moduleDir)
+	}
+
+	for _, r := range conf.Get("resources").Array() {
+		if r.Get("mode").String() != "managed" {
+			continue
+		}
+
+		addr := r.Get("address").String()
+
+		var rng *hcl2.Range
+		if br, ok := ranges[addr]; ok {
+			rng = &br
+		}
+
+		if m := sourceMetadata(projectDir, rng, moduleSource, moduleCall); len(m) > 0 {
+			metadata[prefix+addr] = m
+		}
+	}
+
+	for name, call := range conf.Get("module_calls").Map() {
+		childKey := name
+		if key != "" {
+			childKey = key + "." + name
+		}
+
+		source := call.Get("source").String()
+
+		childDir := ""
+		if d, ok := manifest[childKey]; ok {
+			childDir = filepath.Join(projectDir, d)
+		} else if strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../") {
+			if moduleDir != "" {
+				childDir = filepath.Join(moduleDir, source)
+			}
+		}
+
+		var callRange *hcl2.Range
+		if br, ok := ranges["module."+name]; ok {
+			callRange = &br
+		}
+
+		addModuleSourceMetadata(metadata, projectDir, childDir, prefix+"module."+name+".", childKey, call.Get("module"), source, callRange, manifest)
+	}
+}
+
+// loadModuleManifest returns the directories of the modules downloaded by terraform init, keyed by the
+// module key, e.g. db.db_1 for the db_1 module called by the db module.
+func loadModuleManifest(dir string) map[string]string {
+	dirs := make(map[string]string)
+
+	b, err := os.ReadFile(filepath.Join(dir, ".terraform", "modules", "modules.json"))
+	if err != nil {
+		return dirs
+	}
+
+	var manifest struct {
+		Modules []struct {
+			Key string `json:"Key"`
+			Dir string `json:"Dir"`
+		} `json:"Modules"`
+	}
+
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		log.Debugf("Error reading Terraform module manifest: %s", err)
+		return dirs
+	}
+
+	for _, m := range manifest.Modules {
+		if m.Key != "" {
+			dirs[m.Key] = m.Dir
+		}
+	}
+
+	return dirs
+}
+
+// parseBlockRanges parses the Terraform files in the directory and returns the locations of the resource
+// and module blocks keyed by their address in the module, e.g. aws_instance.web or module.db.
+func parseBlockRanges(dir string) map[string]hcl2.Range {
+	ranges := make(map[string]hcl2.Range)
+
+	matches, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return ranges
+	}
+
+	parser := hclparse.NewParser()
+
+	for _, filename := range matches {
+		file, diags := parser.ParseHCLFile(filename)
+		if diags.HasErrors() {
+			log.Debugf("Error parsing %s for resource locations: %s", filename, diags.Error())
+			continue
+		}
+
+		body, ok := file.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+
+		for _, block := range body.Blocks {
+			rng := block.DefRange()
+			rng.End = block.Body.SrcRange.End
+
+			switch {
+			case block.Type == "resource" && len(block.Labels) == 2:
+				ranges[block.Labels[0]+"."+block.Labels[1]] = rng
+			case block.Type == "module" && len(block.Labels) == 1:
+				ranges["module."+block.Labels[0]] = rng
+			}
+		}
+	}
+
+	return ranges
+}
@@ -0,0 +1,146 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/infracost/infracost/internal/hcl"
+	"github.com/infracost/infracost/internal/schema"
+)
+
+func writeSourceFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, contents := range files {
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0700))
+		require.NoError(t, os.WriteFile(p, []byte(contents), 0600))
+	}
+}
+
+var sourceLocationFiles = map[string]string{
+	"main.tf": `resource "aws_instance" "web" {
+  instance_type = "t3.micro"
+}
+
+module "db" {
+  source = "./modules/db"
+}
+`,
+	"modules/db/main.tf": `
+resource "aws_db_instance" "db" {
+  instance_class = "db.t3.micro"
+  engine         = "mysql"
+}
+`,
+}
+
+func TestConfigAddress(t *testing.T) {
+	tests := []struct {
+		address  string
+		expected string
+	}{
+		{"aws_instance.web", "aws_instance.web"},
+		{"aws_instance.web[0]", "aws_instance.web"},
+		{"aws_instance.web[\"a.b\"]", "aws_instance.web"},
+		{"module.web[0].aws_instance.web[\"a\"]", "module.web.aws_instance.web"},
+		{"module.parent[\"x\"].module.child.aws_instance.web", "module.parent.module.child.aws_instance.web"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, configAddress(test.address))
+	}
+}
+
+func TestPlanSourceMetadata(t *testing.T) {
+	dir := t.TempDir()
+	writeSourceFiles(t, dir, sourceLocationFiles)
+
+	conf := gjson.Parse(`{
+  "root_module": {
+    "resources": [
+      {"address": "aws_instance.web", "mode": "managed"},
+      {"address": "data.aws_ami.ubuntu", "mode": "data"}
+    ],
+    "module_calls": {
+      "db": {
+        "source": "./modules/db",
+        "module": {
+          "resources": [{"address": "aws_db_instance.db", "mode": "managed"}]
+        }
+      },
+      "remote": {
+        "source": "terraform-aws-modules/vpc/aws",
+        "module": {
+          "resources": [{"address": "aws_vpc.this", "mode": "managed"}]
+        }
+      }
+    }
+  }
+}`).Get("root_module")
+
+	metadata := planSourceMetadata(dir, conf)
+	assert.Equal(t, map[string]map[string]string{
+		"aws_instance.web": {
+			"filename":  "main.tf",
+			"startLine": "1",
+			"endLine":   "3",
+		},
+		"module.db.aws_db_instance.db": {
+			"filename":           "modules/db/main.tf",
+			"startLine":          "2",
+			"endLine":            "5",
+			"moduleSource":       "./modules/db",
+			"moduleCallLocation": "main.tf:5",
+		},
+		"module.remote.aws_vpc.this": {
+			"moduleSource": "terraform-aws-modules/vpc/aws",
+		},
+	}, metadata)
+
+	resources := []*schema.Resource{
+		{Name: "aws_instance.web[1]"},
+		{Name: "module.db.aws_db_instance.db"},
+		{Name: "aws_s3_bucket.unknown"},
+	}
+	setSourceMetadata(resources, metadata)
+
+	assert.Equal(t, "main.tf", resources[0].Metadata["filename"])
+	assert.Equal(t, "modules/db/main.tf", resources[1].Metadata["filename"])
+	assert.Nil(t, resources[2].Metadata)
+}
+
+func TestPlanSourceMetadataNoDir(t *testing.T) {
+	conf := gjson.Parse(`{"resources": [{"address": "aws_instance.web", "mode": "managed"}]}`)
+	assert.Empty(t, planSourceMetadata("", conf))
+}
+
+func TestCollectSourceMetadata(t *testing.T) {
+	dir := t.TempDir()
+	writeSourceFiles(t, dir, sourceLocationFiles)
+
+	parser := hcl.New(dir)
+	module, err := parser.ParseDirectory()
+	require.NoError(t, err)
+
+	metadata := make(map[string]map[string]string)
+	collectSourceMetadata(module, dir, metadata)
+
+	assert.Equal(t, map[string]string{
+		"filename":  "main.tf",
+		"startLine": "1",
+		"endLine":   "3",
+	}, metadata["aws_instance.web"])
+	assert.Equal(t, map[string]string{
+		"filename":           "modules/db/main.tf",
+		"startLine":          "2",
+		"endLine":            "5",
+		"moduleSource":       "./modules/db",
+		"moduleCallLocation": "main.tf:5",
+	}, metadata["module.db.aws_db_instance.db"])
+}
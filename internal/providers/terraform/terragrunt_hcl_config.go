@@ -0,0 +1,726 @@
+package terraform
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	log "github.com/sirupsen/logrus"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+
+	infracosthcl "github.com/infracost/infracost/internal/hcl"
+)
+
+var (
+	// defaultTerragruntConfigFile is the name of the config file Terragrunt looks for in each directory.
+	defaultTerragruntConfigFile = "terragrunt.hcl"
+	// maxTerragruntIncludeDepth limits how deep includes and read_terragrunt_config calls can be nested, so
+	// that configs that include each other don't loop forever.
+	maxTerragruntIncludeDepth = 10
+
+	terragruntIncludeSchema = &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "path", Required: true},
+			{Name: "merge_strategy"},
+		},
+	}
+
+	terragruntTerraformSchema = &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "source"},
+		},
+	}
+
+	terragruntDependencySchema = &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "config_path"},
+			{Name: "mock_outputs"},
+		},
+	}
+)
+
+// terragruntSchema returns the schema of the parts of a Terragrunt config that affect the Terraform
+// inputs. Terragrunt allows a single include block to be unlabeled, so labeledInclude sets if the
+// include blocks are expected to have a name label.
+func terragruntSchema(labeledInclude bool) *hcl.BodySchema {
+	include := hcl.BlockHeaderSchema{Type: "include"}
+	if labeledInclude {
+		include.LabelNames = []string{"name"}
+	}
+
+	return &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "inputs"},
+		},
+		Blocks: []hcl.BlockHeaderSchema{
+			include,
+			{Type: "locals"},
+			{Type: "terraform"},
+			{Type: "dependency", LabelNames: []string{"name"}},
+		},
+	}
+}
+
+// terragruntConfig is an evaluated Terragrunt config, merged with any configs it includes.
+type terragruntConfig struct {
+	// Path is the path of the terragrunt.hcl file.
+	Path string
+	// Source is the terraform.source attribute, or "" if the Terraform code is in the config directory.
+	Source string
+	// Inputs are the values that Terragrunt passes to Terraform as input variables.
+	Inputs map[string]cty.Value
+	// Locals are the evaluated locals of the config, which are exposed to configs that include it.
+	Locals map[string]cty.Value
+	// Dependencies are the dependency blocks of the config, keyed by name. Their outputs are the
+	// mock_outputs of each block, since we can't read the outputs from the state.
+	Dependencies map[string]cty.Value
+}
+
+// asValue returns the config in the form it is exposed by include and read_terragrunt_config.
+func (c *terragruntConfig) asValue() cty.Value {
+	return cty.ObjectVal(map[string]cty.Value{
+		"locals":     objectVal(c.Locals),
+		"inputs":     objectVal(c.Inputs),
+		"dependency": objectVal(c.Dependencies),
+		"terraform": cty.ObjectVal(map[string]cty.Value{
+			"source": cty.StringVal(c.Source),
+		}),
+	})
+}
+
+// terragruntInclude is an include block of a Terragrunt config.
+type terragruntInclude struct {
+	name          string
+	mergeStrategy string
+	config        *terragruntConfig
+}
+
+// terragruntHCLParser evaluates Terragrunt configs without running Terragrunt, so it doesn't need cloud
+// credentials. Only the parts of the config that affect the Terraform module and its inputs are evaluated:
+// include, locals, dependency, terraform.source and inputs. Dependency outputs are taken from the
+// mock_outputs of each dependency block.
+type terragruntHCLParser struct {
+	parser *hclparse.Parser
+	// env is the project env, which takes precedence over the process environment in get_env.
+	env map[string]string
+}
+
+func newTerragruntHCLParser(env map[string]string) *terragruntHCLParser {
+	return &terragruntHCLParser{
+		parser: hclparse.NewParser(),
+		env:    env,
+	}
+}
+
+// parse evaluates the Terragrunt config at the given path.
+func (p *terragruntHCLParser) parse(filename string) (*terragruntConfig, error) {
+	absPath, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.parseFile(absPath, filepath.Dir(absPath), 0)
+}
+
+// parseFile evaluates the Terragrunt config at the given path. Terragrunt evaluates the functions of
+// included configs relative to the config that includes them, so terragruntDir is the directory of the
+// config that is being evaluated, which is not the directory of filename if it is an included config.
+func (p *terragruntHCLParser) parseFile(filename string, terragruntDir string, depth int) (*terragruntConfig, error) {
+	if depth > maxTerragruntIncludeDepth {
+		return nil, fmt.Errorf("Terragrunt config %s is nested too deeply in includes", filename)
+	}
+
+	body, err := p.parseBody(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	// Blocks and attributes that don't affect the Terraform module or its inputs, e.g. remote_state, are ignored.
+	content, _, diags := body.PartialContent(terragruntSchema(true))
+	if diags.HasErrors() {
+		var unlabeledDiags hcl.Diagnostics
+		content, _, unlabeledDiags = body.PartialContent(terragruntSchema(false))
+		if unlabeledDiags.HasErrors() {
+			return nil, fmt.Errorf("Could not parse Terragrunt config %s: %w", filename, diags)
+		}
+	}
+
+	funcs := &terragruntFuncs{
+		parser:        p,
+		terragruntDir: terragruntDir,
+		configDir:     filepath.Dir(filename),
+		includeDirs:   make(map[string]string),
+		depth:         depth,
+	}
+
+	if depth > 0 {
+		// This is an included config, so functions such as path_relative_to_include are relative to this config.
+		funcs.includeDirs[""] = filepath.Dir(filename)
+	}
+
+	evalCtx := &hcl.EvalContext{
+		Functions: funcs.functions(),
+		Variables: make(map[string]cty.Value),
+	}
+
+	includes, err := p.parseIncludes(content.Blocks.OfType("include"), evalCtx, funcs, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &terragruntConfig{
+		Path:         filename,
+		Inputs:       make(map[string]cty.Value),
+		Locals:       make(map[string]cty.Value),
+		Dependencies: make(map[string]cty.Value),
+	}
+
+	evalCtx.Variables["include"] = includesVal(includes)
+
+	var localAttrs []*hcl.Attribute
+	for _, block := range content.Blocks.OfType("locals") {
+		attrs, diags := block.Body.JustAttributes()
+		if diags.HasErrors() {
+			log.Debugf("Error reading locals in Terragrunt config %s: %s", filename, diags)
+		}
+
+		for _, attr := range attrs {
+			localAttrs = append(localAttrs, attr)
+		}
+	}
+
+	config.Locals = evaluateTerragruntLocals(localAttrs, evalCtx)
+	evalCtx.Variables["local"] = objectVal(config.Locals)
+
+	for _, include := range includes {
+		if include.mergeStrategy == "no_merge" {
+			continue
+		}
+
+		for name, dep := range include.config.Dependencies {
+			config.Dependencies[name] = dep
+		}
+	}
+
+	for _, block := range content.Blocks.OfType("dependency") {
+		config.Dependencies[block.Labels[0]] = evaluateTerragruntDependency(block, evalCtx)
+	}
+	evalCtx.Variables["dependency"] = objectVal(config.Dependencies)
+
+	for _, block := range content.Blocks.OfType("terraform") {
+		tfContent, _, _ := block.Body.PartialContent(terragruntTerraformSchema)
+		if attr, ok := tfContent.Attributes["source"]; ok {
+			config.Source = evaluateTerragruntString(attr, evalCtx)
+		}
+	}
+
+	if attr, ok := content.Attributes["inputs"]; ok {
+		config.Inputs = evaluateTerragruntInputs(attr, evalCtx)
+	}
+
+	for _, include := range includes {
+		mergeTerragruntConfig(config, include)
+	}
+
+	return config, nil
+}
+
+// parseBody parses the HCL or JSON Terragrunt config at the given path.
+func (p *terragruntHCLParser) parseBody(filename string) (hcl.Body, error) {
+	var file *hcl.File
+	var diags hcl.Diagnostics
+
+	if strings.HasSuffix(filename, ".json") {
+		file, diags = p.parser.ParseJSONFile(filename)
+	} else {
+		file, diags = p.parser.ParseHCLFile(filename)
+	}
+
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("Could not parse Terragrunt config %s: %w", filename, diags)
+	}
+
+	return file.Body, nil
+}
+
+// lookupEnv returns the value of the environment variable from the project env, falling back to the
+// process environment.
+func (p *terragruntHCLParser) lookupEnv(key string) (string, bool) {
+	if val, ok := p.env[key]; ok {
+		return val, true
+	}
+
+	return os.LookupEnv(key)
+}
+
+// parseIncludes evaluates the include blocks and parses the configs they include.
+func (p *terragruntHCLParser) parseIncludes(blocks hcl.Blocks, evalCtx *hcl.EvalContext, funcs *terragruntFuncs, depth int) ([]*terragruntInclude, error) {
+	includes := make([]*terragruntInclude, 0, len(blocks))
+
+	for _, block := range blocks {
+		include := &terragruntInclude{
+			mergeStrategy: "shallow",
+		}
+		if len(block.Labels) > 0 {
+			include.name = block.Labels[0]
+		}
+
+		content, _, diags := block.Body.PartialContent(terragruntIncludeSchema)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("Could not parse include block: %w", diags)
+		}
+
+		path := evaluateTerragruntString(content.Attributes["path"], evalCtx)
+		if path == "" {
+			return nil, fmt.Errorf("Could not evaluate path of include block at %s", block.DefRange)
+		}
+
+		if attr, ok := content.Attributes["merge_strategy"]; ok {
+			include.mergeStrategy = evaluateTerragruntString(attr, evalCtx)
+		}
+
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(funcs.configDir, path)
+		}
+
+		config, err := p.parseFile(path, funcs.terragruntDir, depth+1)
+		if err != nil {
+			return nil, err
+		}
+
+		include.config = config
+		funcs.includeDirs[include.name] = filepath.Dir(path)
+		if _, ok := funcs.includeDirs[""]; !ok {
+			funcs.includeDirs[""] = filepath.Dir(path)
+		}
+
+		includes = append(includes, include)
+	}
+
+	return includes, nil
+}
+
+// includesVal returns the value of the include variable. A single unlabeled include is exposed directly,
+// e.g. include.locals, otherwise each include is exposed by name, e.g. include.root.locals.
+func includesVal(includes []*terragruntInclude) cty.Value {
+	if len(includes) == 1 && includes[0].name == "" {
+		return includes[0].config.asValue()
+	}
+
+	vals := make(map[string]cty.Value, len(includes))
+	for _, include := range includes {
+		vals[include.name] = include.config.asValue()
+	}
+
+	return objectVal(vals)
+}
+
+// mergeTerragruntConfig merges the included config into the config, using the merge strategy of the include.
+// Values set in the config override those of the included config.
+func mergeTerragruntConfig(config *terragruntConfig, include *terragruntInclude) {
+	switch include.mergeStrategy {
+	case "no_merge":
+		return
+	case "deep":
+		for name, val := range include.config.Inputs {
+			if existing, ok := config.Inputs[name]; ok {
+				config.Inputs[name] = deepMergeValues(val, existing)
+				continue
+			}
+
+			config.Inputs[name] = val
+		}
+	default:
+		for name, val := range include.config.Inputs {
+			if _, ok := config.Inputs[name]; !ok {
+				config.Inputs[name] = val
+			}
+		}
+	}
+
+	if config.Source == "" {
+		config.Source = include.config.Source
+	}
+}
+
+// deepMergeValues merges the override value into the base value. Objects and maps are merged
+// key by key, and any other value in override replaces the base value.
+func deepMergeValues(base cty.Value, override cty.Value) cty.Value {
+	if base.IsNull() || !base.IsKnown() || override.IsNull() || !override.IsKnown() {
+		return override
+	}
+
+	isMergeable := func(v cty.Value) bool {
+		return v.Type().IsObjectType() || v.Type().IsMapType()
+	}
+
+	if !isMergeable(base) || !isMergeable(override) {
+		return override
+	}
+
+	merged := base.AsValueMap()
+	if merged == nil {
+		merged = make(map[string]cty.Value)
+	}
+
+	for k, v := range override.AsValueMap() {
+		if existing, ok := merged[k]; ok {
+			merged[k] = deepMergeValues(existing, v)
+			continue
+		}
+
+		merged[k] = v
+	}
+
+	return objectVal(merged)
+}
+
+// evaluateTerragruntLocals evaluates the locals, which can reference each other, by evaluating them until
+// no more can be resolved. Locals that can't be resolved are unknown.
+func evaluateTerragruntLocals(attrs []*hcl.Attribute, evalCtx *hcl.EvalContext) map[string]cty.Value {
+	locals := make(map[string]cty.Value)
+	pending := attrs
+
+	for len(pending) > 0 {
+		var unresolved []*hcl.Attribute
+
+		for _, attr := range pending {
+			evalCtx.Variables["local"] = objectVal(locals)
+
+			val, diags := attr.Expr.Value(evalCtx)
+			if diags.HasErrors() {
+				unresolved = append(unresolved, attr)
+				continue
+			}
+
+			locals[attr.Name] = val
+		}
+
+		if len(unresolved) == len(pending) {
+			break
+		}
+
+		pending = unresolved
+	}
+
+	for _, attr := range pending {
+		if _, ok := locals[attr.Name]; ok {
+			continue
+		}
+
+		log.Debugf("Could not evaluate local %s at %s", attr.Name, attr.Range)
+		locals[attr.Name] = cty.DynamicVal
+	}
+
+	return locals
+}
+
+// evaluateTerragruntInputs evaluates the inputs attribute. If it is an object expression each input is
+// evaluated separately, so that an input that can't be evaluated doesn't stop the others from being set.
+func evaluateTerragruntInputs(attr *hcl.Attribute, evalCtx *hcl.EvalContext) map[string]cty.Value {
+	inputs := make(map[string]cty.Value)
+
+	items, diags := hcl.ExprMap(attr.Expr)
+	if diags.HasErrors() {
+		val, diags := attr.Expr.Value(evalCtx)
+		if diags.HasErrors() {
+			log.Debugf("Error evaluating inputs at %s: %s", attr.Range, diags)
+		}
+
+		if !val.IsNull() && val.IsKnown() && val.CanIterateElements() {
+			for k, v := range val.AsValueMap() {
+				inputs[k] = v
+			}
+		}
+
+		return inputs
+	}
+
+	for _, item := range items {
+		key, diags := item.Key.Value(evalCtx)
+		if diags.HasErrors() || key.IsNull() || !key.IsKnown() || key.Type() != cty.String {
+			log.Debugf("Could not evaluate input name at %s", item.Key.Range())
+			continue
+		}
+
+		val, diags := item.Value.Value(evalCtx)
+		if diags.HasErrors() {
+			log.Debugf("Error evaluating input %s: %s", key.AsString(), diags)
+			val = cty.DynamicVal
+		}
+
+		inputs[key.AsString()] = val
+	}
+
+	return inputs
+}
+
+// evaluateTerragruntDependency returns the value of a dependency block. The outputs are the mock_outputs of
+// the block, or unknown if it doesn't set any.
+func evaluateTerragruntDependency(block *hcl.Block, evalCtx *hcl.EvalContext) cty.Value {
+	content, _, _ := block.Body.PartialContent(terragruntDependencySchema)
+
+	outputs := cty.DynamicVal
+	if attr, ok := content.Attributes["mock_outputs"]; ok {
+		val, diags := attr.Expr.Value(evalCtx)
+		if diags.HasErrors() {
+			log.Debugf("Error evaluating mock_outputs of dependency %s: %s", block.Labels[0], diags)
+		} else {
+			outputs = val
+		}
+	} else {
+		log.Debugf("Dependency %s at %s has no mock_outputs, its outputs will be unknown", block.Labels[0], block.DefRange)
+	}
+
+	configPath := cty.UnknownVal(cty.String)
+	if attr, ok := content.Attributes["config_path"]; ok {
+		if path := evaluateTerragruntString(attr, evalCtx); path != "" {
+			configPath = cty.StringVal(path)
+		}
+	}
+
+	return cty.ObjectVal(map[string]cty.Value{
+		"config_path": configPath,
+		"outputs":     outputs,
+	})
+}
+
+// evaluateTerragruntString evaluates the attribute as a string, returning "" if it can't be evaluated.
+func evaluateTerragruntString(attr *hcl.Attribute, evalCtx *hcl.EvalContext) string {
+	if attr == nil {
+		return ""
+	}
+
+	val, diags := attr.Expr.Value(evalCtx)
+	if diags.HasErrors() {
+		log.Debugf("Error evaluating %s at %s: %s", attr.Name, attr.Range, diags)
+		return ""
+	}
+
+	if val.IsNull() || !val.IsKnown() || val.Type() != cty.String {
+		return ""
+	}
+
+	return val.AsString()
+}
+
+func objectVal(vals map[string]cty.Value) cty.Value {
+	if len(vals) == 0 {
+		return cty.EmptyObjectVal
+	}
+
+	return cty.ObjectVal(vals)
+}
+
+// terragruntFuncs implements the Terragrunt built-in functions for a config that is being evaluated.
+type terragruntFuncs struct {
+	parser *terragruntHCLParser
+	// terragruntDir is the directory of the config that is being evaluated.
+	terragruntDir string
+	// configDir is the directory of the file that contains the expression.
+	configDir string
+	// includeDirs are the directories of the included configs, keyed by include name. The first include
+	// is also stored with an empty name.
+	includeDirs map[string]string
+	depth       int
+}
+
+func (f *terragruntFuncs) functions() map[string]function.Function {
+	funcs := infracosthcl.ExpFunctions(f.terragruntDir)
+
+	stringFunc := func(impl func() (string, error)) function.Function {
+		return function.New(&function.Spec{
+			Type: function.StaticReturnType(cty.String),
+			Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+				s, err := impl()
+				if err != nil {
+					return cty.NilVal, err
+				}
+
+				return cty.StringVal(s), nil
+			},
+		})
+	}
+
+	optionalIncludeFunc := func(impl func(includeDir string) (string, error)) function.Function {
+		return function.New(&function.Spec{
+			VarParam: &function.Parameter{Name: "name", Type: cty.String},
+			Type:     function.StaticReturnType(cty.String),
+			Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+				name := ""
+				if len(args) > 0 {
+					name = args[0].AsString()
+				}
+
+				includeDir, ok := f.includeDirs[name]
+				if !ok {
+					if name != "" {
+						return cty.NilVal, fmt.Errorf("no include block named %s", name)
+					}
+
+					includeDir = f.terragruntDir
+				}
+
+				s, err := impl(includeDir)
+				if err != nil {
+					return cty.NilVal, err
+				}
+
+				return cty.StringVal(s), nil
+			},
+		})
+	}
+
+	funcs["find_in_parent_folders"] = function.New(&function.Spec{
+		VarParam: &function.Parameter{Name: "args", Type: cty.String},
+		Type:     function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			name := defaultTerragruntConfigFile
+			if len(args) > 0 {
+				name = args[0].AsString()
+			}
+
+			path, err := findInParentFolders(f.terragruntDir, name)
+			if err != nil {
+				if len(args) > 1 {
+					return args[1], nil
+				}
+
+				return cty.NilVal, err
+			}
+
+			return cty.StringVal(path), nil
+		},
+	})
+
+	funcs["path_relative_to_include"] = optionalIncludeFunc(func(includeDir string) (string, error) {
+		return filepath.Rel(includeDir, f.terragruntDir)
+	})
+
+	funcs["path_relative_from_include"] = optionalIncludeFunc(func(includeDir string) (string, error) {
+		return filepath.Rel(f.terragruntDir, includeDir)
+	})
+
+	funcs["get_parent_terragrunt_dir"] = optionalIncludeFunc(func(includeDir string) (string, error) {
+		return includeDir, nil
+	})
+
+	funcs["get_terragrunt_dir"] = stringFunc(func() (string, error) {
+		return f.terragruntDir, nil
+	})
+
+	funcs["get_original_terragrunt_dir"] = stringFunc(func() (string, error) {
+		return f.terragruntDir, nil
+	})
+
+	funcs["get_terraform_command"] = stringFunc(func() (string, error) {
+		return "plan", nil
+	})
+
+	funcs["get_platform"] = stringFunc(func() (string, error) {
+		return runtime.GOOS, nil
+	})
+
+	// We can't look up the AWS identity offline, so use the same placeholders as the aws_caller_identity data source.
+	funcs["get_aws_account_id"] = stringFunc(func() (string, error) {
+		return infracosthcl.AWSPlaceholderAccountID, nil
+	})
+
+	funcs["get_aws_caller_identity_arn"] = stringFunc(func() (string, error) {
+		return fmt.Sprintf("arn:aws:iam::%s:user/infracost", infracosthcl.AWSPlaceholderAccountID), nil
+	})
+
+	funcs["get_terraform_commands_that_need_vars"] = function.New(&function.Spec{
+		Type: function.StaticReturnType(cty.List(cty.String)),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			cmds := []string{"apply", "console", "destroy", "import", "plan", "push", "refresh"}
+
+			vals := make([]cty.Value, len(cmds))
+			for i, cmd := range cmds {
+				vals[i] = cty.StringVal(cmd)
+			}
+
+			return cty.ListVal(vals), nil
+		},
+	})
+
+	funcs["get_env"] = function.New(&function.Spec{
+		Params:   []function.Parameter{{Name: "name", Type: cty.String}},
+		VarParam: &function.Parameter{Name: "default", Type: cty.String},
+		Type:     function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			if val, ok := f.parser.lookupEnv(args[0].AsString()); ok {
+				return cty.StringVal(val), nil
+			}
+
+			if len(args) > 1 {
+				return args[1], nil
+			}
+
+			return cty.NilVal, fmt.Errorf("environment variable %s is not set", args[0].AsString())
+		},
+	})
+
+	// We don't run commands when parsing the HCL, so the output is unknown.
+	funcs["run_cmd"] = function.New(&function.Spec{
+		VarParam: &function.Parameter{Name: "args", Type: cty.String},
+		Type:     function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			log.Debugf("Skipping run_cmd in Terragrunt config, its output will be unknown")
+			return cty.UnknownVal(cty.String), nil
+		},
+	})
+
+	funcs["read_terragrunt_config"] = function.New(&function.Spec{
+		Params:   []function.Parameter{{Name: "path", Type: cty.String}},
+		VarParam: &function.Parameter{Name: "default", Type: cty.DynamicPseudoType},
+		Type:     function.StaticReturnType(cty.DynamicPseudoType),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			path := args[0].AsString()
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(f.configDir, path)
+			}
+
+			config, err := f.parser.parseFile(path, filepath.Dir(path), f.depth+1)
+			if err != nil {
+				if len(args) > 1 {
+					return args[1], nil
+				}
+
+				return cty.NilVal, err
+			}
+
+			return config.asValue(), nil
+		},
+	})
+
+	return funcs
+}
+
+// findInParentFolders searches the parent directories of dir for a file with the given name and
+// returns its absolute path.
+func findInParentFolders(dir string, name string) (string, error) {
+	current := dir
+
+	for {
+		parent := filepath.Dir(current)
+		if parent == current {
+			return "", fmt.Errorf("could not find %s in any of the parent folders of %s", name, dir)
+		}
+
+		current = parent
+
+		path := filepath.Join(current, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+	}
+}
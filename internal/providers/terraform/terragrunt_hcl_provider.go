@@ -0,0 +1,276 @@
+package terraform
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	getter "github.com/hashicorp/go-getter"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/infracost/infracost/internal/config"
+	"github.com/infracost/infracost/internal/hcl"
+	"github.com/infracost/infracost/internal/hcl/modules"
+	"github.com/infracost/infracost/internal/schema"
+	"github.com/infracost/infracost/internal/ui"
+)
+
+// terragruntSkipDirs are the directories that are never searched for Terragrunt configs, since they
+// contain copies of the Terraform code made by Terragrunt, Terraform or Infracost.
+var terragruntSkipDirs = []string{".terragrunt-cache", ".terraform", ".infracost", ".git"}
+
+// TerragruntHCLProvider estimates Terragrunt projects by parsing the HCL instead of running Terragrunt.
+// Each terragrunt.hcl file under the path is evaluated to find the Terraform module it uses and its inputs,
+// and the module is then parsed with a hcl.Parser using the inputs as variables. This doesn't need cloud
+// credentials and is much faster than running terragrunt plan for every config.
+type TerragruntHCLProvider struct {
+	ctx     *config.ProjectContext
+	Path    string
+	options []hcl.Option
+}
+
+// NewTerragruntHCLProvider returns a TerragruntHCLProvider. The input vars and files set using the
+// terraform-plan-flags or top level var and var-file flags are applied to every Terragrunt config.
+func NewTerragruntHCLProvider(ctx *config.ProjectContext) (*TerragruntHCLProvider, error) {
+	options, err := hclOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TerragruntHCLProvider{
+		ctx:     ctx,
+		Path:    ctx.ProjectConfig.Path,
+		options: options,
+	}, nil
+}
+
+func (p *TerragruntHCLProvider) Type() string                                 { return "terragrunt_hcl" }
+func (p *TerragruntHCLProvider) DisplayType() string                          { return "Terragrunt directory (HCL)" }
+func (p *TerragruntHCLProvider) AddMetadata(metadata *schema.ProjectMetadata) {}
+
+// LoadResources evaluates the Terragrunt configs under the path and returns a project for each config.
+// Configs that can't be evaluated are skipped with a warning, so that one broken config doesn't stop the
+// rest of the repo from being estimated.
+func (p *TerragruntHCLProvider) LoadResources(usage map[string]*schema.UsageData) ([]*schema.Project, error) {
+	if p.ctx.ProjectConfig.TerraformCompareTo != "" {
+		log.Warnf("--compare-to is not supported for Terragrunt directories, ignoring it")
+	}
+
+	spinner := ui.NewSpinner("Evaluating Terragrunt configs", ui.SpinnerOptions{
+		EnableLogging: p.ctx.RunContext.Config.IsLogging(),
+		NoColor:       p.ctx.RunContext.Config.NoColor,
+		Indent:        "  ",
+	})
+	defer spinner.Fail()
+
+	configFiles, err := findTerragruntConfigs(p.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	tgParser := newTerragruntHCLParser(p.ctx.ProjectConfig.Env)
+	projects := make([]*schema.Project, 0, len(configFiles))
+
+	for _, configFile := range configFiles {
+		project, err := p.loadProject(tgParser, configFile, usage)
+		if err != nil {
+			log.Warnf("Skipping Terragrunt config %s: %s", configFile, err)
+			continue
+		}
+
+		if project != nil {
+			projects = append(projects, project)
+		}
+	}
+
+	if len(projects) == 0 {
+		return nil, fmt.Errorf("No Terragrunt configs with Terraform code could be evaluated in %s", p.Path)
+	}
+
+	spinner.Success()
+	return projects, nil
+}
+
+// loadProject evaluates the Terragrunt config and parses the Terraform module it uses. It returns nil if the
+// config has no Terraform code, e.g. a root config that is only included by other configs.
+func (p *TerragruntHCLProvider) loadProject(tgParser *terragruntHCLParser, configFile string, usage map[string]*schema.UsageData) (*schema.Project, error) {
+	tgConfig, err := tgParser.parse(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	configDir := filepath.Dir(configFile)
+
+	if tgConfig.Source == "" && !hasTerraformFiles(configDir) {
+		log.Debugf("Terragrunt config %s has no terraform source and no Terraform files, skipping it", configFile)
+		return nil, nil
+	}
+
+	moduleDir, err := p.sourceDir(tgConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Parsing Terraform module %s for Terragrunt config %s", moduleDir, configFile)
+
+	options := append([]hcl.Option{}, p.options...)
+	options = append(options, hcl.OptionWithInputValues(tgConfig.Inputs))
+
+	hclProvider := &HCLProvider{
+		Parser: hcl.New(moduleDir, options...),
+		path:   configDir,
+	}
+
+	j, err := hclProvider.LoadPlanJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := config.DetectProjectMetadata(configDir, p.ctx.ProjectConfig)
+	metadata.Type = p.Type()
+	p.AddMetadata(metadata)
+	metadata.MissingVariables = hclProvider.missingVariables
+	name := schema.GenerateProjectName(metadata, p.ctx.RunContext.Config.EnableDashboard)
+
+	project := schema.NewProject(name, metadata)
+
+	parser := NewParser(p.ctx)
+	pastResources, resources, err := parser.parseJSON(j, usage)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing Terraform plan JSON %w", err)
+	}
+
+	for _, r := range resources {
+		r.UnknownAttributes = hclProvider.unknownAttributes[r.Name]
+	}
+	setSourceMetadata(resources, hclProvider.sourceMetadata)
+
+	project.PastResources = pastResources
+	project.Resources = resources
+
+	return project, nil
+}
+
+// sourceDir returns the directory of the Terraform module used by the Terragrunt config, downloading
+// it if terraform.source is a registry or other remote module source.
+func (p *TerragruntHCLProvider) sourceDir(tgConfig *terragruntConfig) (string, error) {
+	configDir := filepath.Dir(tgConfig.Path)
+	source := tgConfig.Source
+
+	if source == "" {
+		return configDir, nil
+	}
+
+	if isLocalTerragruntSource(source) {
+		dir, subDir := getter.SourceDirSubdir(source)
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(configDir, dir)
+		}
+
+		return filepath.Join(dir, subDir), nil
+	}
+
+	source, version, err := normalizeTerragruntSource(source)
+	if err != nil {
+		return "", err
+	}
+
+	var sharedCache *modules.SharedCache
+	if dir := p.ctx.RunContext.Config.ModuleCacheDir(); dir != "" {
+		sharedCache = modules.NewSharedCache(dir)
+	}
+
+	loader := modules.NewModuleLoader(configDir, sharedCache, p.ctx.ProjectConfig.Env)
+	dir, err := loader.LoadSource("terragrunt", source, version)
+	if err != nil {
+		return "", fmt.Errorf("Failed to load terraform source %s: %w", tgConfig.Source, err)
+	}
+
+	return dir, nil
+}
+
+// isLocalTerragruntSource returns true if the terraform.source is a path on the local filesystem.
+func isLocalTerragruntSource(source string) bool {
+	if strings.Contains(source, "::") || strings.Contains(source, "://") {
+		return false
+	}
+
+	return filepath.IsAbs(source) ||
+		strings.HasPrefix(source, "./") ||
+		strings.HasPrefix(source, "../") ||
+		strings.HasPrefix(source, ".\\") ||
+		strings.HasPrefix(source, "..\\")
+}
+
+// normalizeTerragruntSource converts Terragrunt's tfr:// registry sources into the module source and version
+// used in Terraform module blocks, e.g. tfr:///terraform-aws-modules/vpc/aws?version=3.3.0 returns
+// terraform-aws-modules/vpc/aws and 3.3.0. Other sources are returned unchanged.
+func normalizeTerragruntSource(source string) (string, string, error) {
+	if !strings.HasPrefix(source, "tfr://") {
+		return source, "", nil
+	}
+
+	u, err := url.Parse(source)
+	if err != nil {
+		return "", "", fmt.Errorf("Invalid terraform source %s: %w", source, err)
+	}
+
+	modulePath := strings.TrimPrefix(u.Path, "/")
+	if u.Host != "" {
+		modulePath = u.Host + "/" + modulePath
+	}
+
+	return modulePath, u.Query().Get("version"), nil
+}
+
+// findTerragruntConfigs returns the Terragrunt config files in the path and its subdirectories, sorted by path.
+func findTerragruntConfigs(path string) ([]string, error) {
+	var configFiles []string
+
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if p != path && containsString(terragruntSkipDirs, d.Name()) {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if d.Name() == defaultTerragruntConfigFile || d.Name() == defaultTerragruntConfigFile+".json" {
+			configFiles = append(configFiles, p)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error finding Terragrunt configs in %s: %w", path, err)
+	}
+
+	sort.Strings(configFiles)
+
+	return configFiles, nil
+}
+
+// hasTerraformFiles returns true if the directory contains any Terraform files.
+func hasTerraformFiles(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() && (strings.HasSuffix(entry.Name(), ".tf") || strings.HasSuffix(entry.Name(), ".tf.json")) {
+			return true
+		}
+	}
+
+	return false
+}
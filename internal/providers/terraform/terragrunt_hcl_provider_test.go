@@ -0,0 +1,101 @@
+package terraform
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/infracost/infracost/internal/config"
+)
+
+var terragruntHCLTestPath = "testdata/terragrunt_hcl_provider_test"
+
+func TestTerragruntHCLParser(t *testing.T) {
+	tgConfig, err := newTerragruntHCLParser(nil).parse(filepath.Join(terragruntHCLTestPath, "live/app/terragrunt.hcl"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "../../modules//instance", tgConfig.Source)
+
+	inputs := make(map[string]string)
+	for k, v := range tgConfig.Inputs {
+		if v.Type() == cty.String {
+			inputs[k] = v.AsString()
+		}
+	}
+
+	assert.Equal(t, map[string]string{
+		"name":          "app-app",
+		"region":        "us-east-1",
+		"region_copy":   "us-east-1",
+		"subnet_id":     "subnet-123",
+		"instance_type": "m5.large",
+	}, inputs)
+
+	assert.True(t, tgConfig.Inputs["volume_size"].Equals(cty.NumberIntVal(50)).True())
+	assert.Equal(t, "infra", tgConfig.Inputs["tags"].GetAttr("Team").AsString())
+}
+
+func TestTerragruntHCLParserUnlabeledInclude(t *testing.T) {
+	tgConfig, err := newTerragruntHCLParser(nil).parse(filepath.Join(terragruntHCLTestPath, "live/vpc/terragrunt.hcl"))
+	require.NoError(t, err)
+
+	absPath, err := filepath.Abs(terragruntHCLTestPath)
+	require.NoError(t, err)
+
+	assert.Equal(t, filepath.Join(absPath, "modules/vpc"), tgConfig.Source)
+	assert.Equal(t, "us-east-1", tgConfig.Inputs["region"].AsString())
+	assert.True(t, tgConfig.Inputs["nat_gateway_count"].Equals(cty.NumberIntVal(2)).True())
+}
+
+func TestTerragruntHCLProvider_LoadResources(t *testing.T) {
+	ctx := config.NewProjectContext(config.EmptyRunContext(), &config.Project{Path: terragruntHCLTestPath})
+
+	p, err := NewTerragruntHCLProvider(ctx)
+	require.NoError(t, err)
+
+	projects, err := p.LoadResources(nil)
+	require.NoError(t, err)
+
+	resources := make(map[string]map[string]string)
+	for _, project := range projects {
+		assert.Equal(t, "terragrunt_hcl", project.Metadata.Type)
+
+		for _, r := range project.Resources {
+			resources[r.Name] = map[string]string{"project": filepath.Base(project.Metadata.Path)}
+
+			if r.Name == "aws_instance.web" {
+				require.NotEmpty(t, r.CostComponents)
+				assert.Equal(t, "Instance usage (Linux/UNIX, on-demand, m5.large)", r.CostComponents[0].Name)
+			}
+		}
+	}
+
+	assert.Equal(t, map[string]map[string]string{
+		"aws_instance.web":       {"project": "app"},
+		"aws_nat_gateway.nat[0]": {"project": "vpc"},
+		"aws_nat_gateway.nat[1]": {"project": "vpc"},
+	}, resources)
+}
+
+func TestNormalizeTerragruntSource(t *testing.T) {
+	tests := []struct {
+		source  string
+		module  string
+		version string
+	}{
+		{"tfr:///terraform-aws-modules/vpc/aws?version=3.3.0", "terraform-aws-modules/vpc/aws", "3.3.0"},
+		{"tfr://app.terraform.io/org/vpc/aws?version=1.0.0", "app.terraform.io/org/vpc/aws", "1.0.0"},
+		{"tfr:///terraform-aws-modules/vpc/aws//modules/vpc-endpoints?version=3.3.0", "terraform-aws-modules/vpc/aws//modules/vpc-endpoints", "3.3.0"},
+		{"git::https://example.com/modules.git//vpc?ref=v1.0.0", "git::https://example.com/modules.git//vpc?ref=v1.0.0", ""},
+	}
+
+	for _, test := range tests {
+		module, version, err := normalizeTerragruntSource(test.source)
+		require.NoError(t, err)
+		assert.Equal(t, test.module, module, test.source)
+		assert.Equal(t, test.version, version, test.source)
+	}
+}
@@ -0,0 +1,33 @@
+include "root" {
+  path   = find_in_parent_folders()
+  expose = true
+}
+
+terraform {
+  source = "../../modules//instance"
+
+  extra_arguments "common_vars" {
+    commands = get_terraform_commands_that_need_vars()
+  }
+}
+
+dependency "vpc" {
+  config_path = "../vpc"
+
+  mock_outputs = {
+    subnet_id = "subnet-123"
+  }
+}
+
+locals {
+  volume_size = local.base_size * 2
+  base_size   = 25
+}
+
+inputs = {
+  name          = "app-${basename(path_relative_to_include())}"
+  volume_size   = local.volume_size
+  subnet_id     = dependency.vpc.outputs.subnet_id
+  instance_type = "m5.large"
+  region_copy   = include.root.locals.region
+}
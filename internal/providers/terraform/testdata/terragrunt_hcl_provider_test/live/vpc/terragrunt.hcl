@@ -0,0 +1,11 @@
+include {
+  path = find_in_parent_folders()
+}
+
+terraform {
+  source = "${get_parent_terragrunt_dir()}/modules/vpc"
+}
+
+inputs = {
+  nat_gateway_count = 2
+}
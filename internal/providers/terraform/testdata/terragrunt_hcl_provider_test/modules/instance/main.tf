@@ -0,0 +1,29 @@
+variable "name" {}
+variable "region" {}
+variable "subnet_id" {}
+
+variable "instance_type" {
+  default = "t3.micro"
+}
+
+variable "volume_size" {
+  type = number
+}
+
+provider "aws" {
+  region = var.region
+}
+
+resource "aws_instance" "web" {
+  ami           = "ami-123"
+  instance_type = var.instance_type
+  subnet_id     = var.subnet_id
+
+  root_block_device {
+    volume_size = var.volume_size
+  }
+
+  tags = {
+    Name = var.name
+  }
+}
@@ -0,0 +1,15 @@
+variable "region" {}
+
+variable "nat_gateway_count" {
+  type = number
+}
+
+provider "aws" {
+  region = var.region
+}
+
+resource "aws_nat_gateway" "nat" {
+  count         = var.nat_gateway_count
+  allocation_id = "eip-123"
+  subnet_id     = "subnet-123"
+}
@@ -0,0 +1,20 @@
+locals {
+  region = "us-east-1"
+}
+
+remote_state {
+  backend = "s3"
+  config = {
+    bucket = "my-state"
+    key    = "${path_relative_to_include()}/terraform.tfstate"
+    region = local.region
+  }
+}
+
+inputs = {
+  region        = local.region
+  instance_type = "t3.small"
+  tags = {
+    Team = "infra"
+  }
+}
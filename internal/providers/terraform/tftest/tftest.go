@@ -363,7 +363,7 @@ func RunSyncUsage(t *testing.T, projects []*schema.Project, usageFilePath string
 	usageFile, err := usage.LoadUsageFile(usageFilePath)
 	require.NoError(t, err)
 
-	_, err = usage.SyncUsageData(usageFile, projects)
+	_, err = usage.SyncUsageData(context.Background(), usageFile, projects)
 	require.NoError(t, err)
 
 	out := filepath.Join(t.TempDir(), "actual_usage.yml")
@@ -0,0 +1,8 @@
+provider "aws" {
+  region = "us-east-1"
+}
+
+resource "aws_instance" "web" {
+  ami           = "ami-123"
+  instance_type = "m5.large"
+}
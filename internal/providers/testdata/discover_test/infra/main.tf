@@ -0,0 +1,11 @@
+terraform {
+  backend "s3" {}
+}
+
+provider "aws" {
+  region = "us-east-1"
+}
+
+module "network" {
+  source = "../modules/network"
+}
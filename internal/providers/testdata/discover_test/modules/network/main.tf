@@ -0,0 +1,8 @@
+provider "aws" {
+  region = "us-east-1"
+}
+
+resource "aws_nat_gateway" "nat" {
+  allocation_id = "eip-123"
+  subnet_id     = "subnet-123"
+}
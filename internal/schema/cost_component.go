@@ -17,6 +17,7 @@ type CostComponent struct {
 	price                decimal.Decimal
 	customPrice          *decimal.Decimal
 	priceHash            string
+	missingPrice         bool
 	HourlyCost           *decimal.Decimal
 	MonthlyCost          *decimal.Decimal
 }
@@ -64,6 +65,15 @@ func (c *CostComponent) CustomPrice() *decimal.Decimal {
 	return c.customPrice
 }
 
+// SetMissingPrice marks that no price was found for the cost component, so its price was set to 0.
+func (c *CostComponent) SetMissingPrice(missing bool) {
+	c.missingPrice = missing
+}
+
+func (c *CostComponent) MissingPrice() bool {
+	return c.missingPrice
+}
+
 func (c *CostComponent) UnitMultiplierPrice() decimal.Decimal {
 	return c.Price().Mul(c.UnitMultiplier)
 }
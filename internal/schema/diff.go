@@ -36,8 +36,26 @@ func calculateDiff(past []*Resource, current []*Resource) []*Resource {
 
 	diff := make([]*Resource, 0)
 
+	// Resources that were moved or renamed are diffed against the resource at their
+	// previous address instead of being shown as a removed and an added resource.
+	moved := matchMovedResources(past, current)
+
 	for _, resource := range past {
 		resourceKey := resource.Name
+		if currentKey, ok := moved[resourceKey]; ok {
+			movedFrom := resourceKey
+			if c, ok := currentRMap[currentKey]; ok && currentKey == resourceKey {
+				movedFrom = c.MovedFrom
+			}
+
+			// Moved resources are always included so the move is shown even if the cost didn't change.
+			if _, resources := diffResourcesByKeys(resourceKey, currentKey, pastRMap, currentRMap); resources != nil {
+				resources.MovedFrom = movedFrom
+				diff = append(diff, resources)
+			}
+			continue
+		}
+
 		changed, resources := diffResourcesByKey(resourceKey, pastRMap, currentRMap)
 		if changed {
 			diff = append(diff, resources)
@@ -58,13 +76,124 @@ func calculateDiff(past []*Resource, current []*Resource) []*Resource {
 	return diff
 }
 
+// matchMovedResources returns the addresses of the current resources keyed by the addresses of the past
+// resources they were moved from. A resource is matched by the address it was moved from if that is
+// known, e.g. from a Terraform moved block. Otherwise a removed resource is matched to an added resource
+// of the same type with identical non-zero cost components, which is usually a resource that was renamed
+// or moved into a module. If the past resource already has the new address both addresses are the same.
+func matchMovedResources(past []*Resource, current []*Resource) map[string]string {
+	moved := make(map[string]string)
+
+	pastByName := make(map[string]*Resource, len(past))
+	for _, r := range past {
+		pastByName[r.Name] = r
+	}
+
+	currentByName := make(map[string]*Resource, len(current))
+	for _, r := range current {
+		currentByName[r.Name] = r
+	}
+
+	matchedCurrent := make(map[string]bool)
+
+	for _, r := range current {
+		if r.MovedFrom == "" || r.MovedFrom == r.Name {
+			continue
+		}
+
+		_, pastHasName := pastByName[r.Name]
+		_, pastHasMovedFrom := pastByName[r.MovedFrom]
+		_, currentHasMovedFrom := currentByName[r.MovedFrom]
+		_, alreadyMoved := moved[r.MovedFrom]
+
+		switch {
+		case pastHasName && !pastHasMovedFrom:
+			// The past resource already has the new address, e.g. Terraform applies moved blocks to the prior state.
+			moved[r.Name] = r.Name
+		case !pastHasName && pastHasMovedFrom && !currentHasMovedFrom && !alreadyMoved:
+			moved[r.MovedFrom] = r.Name
+		default:
+			continue
+		}
+
+		matchedCurrent[r.Name] = true
+	}
+
+	for _, p := range past {
+		if _, ok := currentByName[p.Name]; ok {
+			continue
+		}
+
+		if _, ok := moved[p.Name]; ok {
+			continue
+		}
+
+		// Resources without a cost, e.g. usage-based resources without usage, have identical cost components
+		// even if they are unrelated so they can't be matched this way.
+		if p.IsSkipped || len(p.CostComponents) == 0 || p.MonthlyCost == nil || p.MonthlyCost.IsZero() {
+			continue
+		}
+
+		for _, c := range current {
+			if matchedCurrent[c.Name] {
+				continue
+			}
+
+			if _, ok := pastByName[c.Name]; ok {
+				continue
+			}
+
+			if c.ResourceType != p.ResourceType || !resourceCostsEqual(p, c) {
+				continue
+			}
+
+			moved[p.Name] = c.Name
+			matchedCurrent[c.Name] = true
+			break
+		}
+	}
+
+	return moved
+}
+
+// resourceCostsEqual returns true if the resources and their sub resources have the same cost components.
+func resourceCostsEqual(a, b *Resource) bool {
+	if len(a.CostComponents) != len(b.CostComponents) || len(a.SubResources) != len(b.SubResources) {
+		return false
+	}
+
+	for i, c := range a.CostComponents {
+		if changed, _ := diffCostComponents(c, b.CostComponents[i]); changed {
+			return false
+		}
+
+		if c.Name != b.CostComponents[i].Name || c.Unit != b.CostComponents[i].Unit {
+			return false
+		}
+	}
+
+	for i, s := range a.SubResources {
+		if s.Name != b.SubResources[i].Name || !resourceCostsEqual(s, b.SubResources[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
 // diffResourcesByKey calculates the diff between two resources given their resourcesMap and
 // their key.
 func diffResourcesByKey(resourceKey string, pastResMap, currentResMap map[string]*Resource) (bool, *Resource) {
-	past, pastOk := pastResMap[resourceKey]
-	current, currentOk := currentResMap[resourceKey]
+	return diffResourcesByKeys(resourceKey, resourceKey, pastResMap, currentResMap)
+}
+
+// diffResourcesByKeys calculates the diff between two resources given their resourcesMap and
+// their keys. The keys are different if the resource was moved.
+func diffResourcesByKeys(pastKey, currentKey string, pastResMap, currentResMap map[string]*Resource) (bool, *Resource) {
+	past, pastOk := pastResMap[pastKey]
+	current, currentOk := currentResMap[currentKey]
 	if current == nil && past == nil {
-		log.Debugf("diffResourcesByKey nil current and past with key %s", resourceKey)
+		log.Debugf("diffResourcesByKeys nil current and past with keys %s and %s", pastKey, currentKey)
 		return false, nil
 	}
 	baseResource := current
@@ -77,30 +206,35 @@ func diffResourcesByKey(resourceKey string, pastResMap, currentResMap map[string
 	}
 	changed := false
 	diff := &Resource{
-		Name:         baseResource.Name,
-		IsSkipped:    baseResource.IsSkipped,
-		NoPrice:      baseResource.NoPrice,
-		SkipMessage:  baseResource.SkipMessage,
-		ResourceType: baseResource.ResourceType,
-		Tags:         baseResource.Tags,
+		Name:              baseResource.Name,
+		IsSkipped:         baseResource.IsSkipped,
+		NoPrice:           baseResource.NoPrice,
+		SkipMessage:       baseResource.SkipMessage,
+		ResourceType:      baseResource.ResourceType,
+		Tags:              baseResource.Tags,
+		UnknownAttributes: baseResource.UnknownAttributes,
+		Metadata:          baseResource.Metadata,
+		AppliedUsage:      baseResource.AppliedUsage,
 
 		HourlyCost:  diffDecimals(current.HourlyCost, past.HourlyCost),
 		MonthlyCost: diffDecimals(current.MonthlyCost, past.MonthlyCost),
 	}
 	for _, subResource := range past.SubResources {
-		subKey := fmt.Sprintf("%v.%v", resourceKey, subResource.Name)
-		subChanged, subDiff := diffResourcesByKey(subKey, pastResMap, currentResMap)
+		pastSubKey := fmt.Sprintf("%v.%v", pastKey, subResource.Name)
+		currentSubKey := fmt.Sprintf("%v.%v", currentKey, subResource.Name)
+		subChanged, subDiff := diffResourcesByKeys(pastSubKey, currentSubKey, pastResMap, currentResMap)
 		if subChanged {
 			diff.SubResources = append(diff.SubResources, subDiff)
 			changed = true
 		}
 	}
 	for _, subResource := range current.SubResources {
-		subKey := fmt.Sprintf("%v.%v", resourceKey, subResource.Name)
-		if _, ok := currentResMap[subKey]; !ok {
+		pastSubKey := fmt.Sprintf("%v.%v", pastKey, subResource.Name)
+		currentSubKey := fmt.Sprintf("%v.%v", currentKey, subResource.Name)
+		if _, ok := currentResMap[currentSubKey]; !ok {
 			continue
 		}
-		subChanged, subDiff := diffResourcesByKey(subKey, pastResMap, currentResMap)
+		subChanged, subDiff := diffResourcesByKeys(pastSubKey, currentSubKey, pastResMap, currentResMap)
 		if subChanged {
 			diff.SubResources = append(diff.SubResources, subDiff)
 			changed = true
@@ -112,10 +246,10 @@ func diffResourcesByKey(resourceKey string, pastResMap, currentResMap map[string
 		changed = true
 	}
 	if pastOk {
-		delete(pastResMap, resourceKey)
+		delete(pastResMap, pastKey)
 	}
 	if currentOk {
-		delete(currentResMap, resourceKey)
+		delete(currentResMap, currentKey)
 	}
 
 	return changed, diff
@@ -186,6 +320,8 @@ func diffCostComponents(past *CostComponent, current *CostComponent) (bool, *Cos
 		ProductFilter:        baseCostComponent.ProductFilter,
 		PriceFilter:          baseCostComponent.PriceFilter,
 		priceHash:            baseCostComponent.priceHash,
+		customPrice:          baseCostComponent.customPrice,
+		missingPrice:         baseCostComponent.missingPrice,
 
 		HourlyQuantity:      diffDecimals(current.HourlyQuantity, past.HourlyQuantity),
 		MonthlyQuantity:     diffDecimals(current.MonthlyQuantity, past.MonthlyQuantity),
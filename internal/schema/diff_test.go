@@ -133,6 +133,91 @@ func TestCalculateDiff(t *testing.T) {
 	assert.Equal(t, expectedDiff, diff)
 }
 
+func TestCalculateDiffMovedResources(t *testing.T) {
+	resource := func(name, resourceType, movedFrom string, quantity int64) *Resource {
+		cost := decimalPtr(decimal.NewFromInt(quantity * 2))
+		return &Resource{
+			Name:         name,
+			ResourceType: resourceType,
+			MovedFrom:    movedFrom,
+			MonthlyCost:  cost,
+			CostComponents: []*CostComponent{
+				{
+					Name:            "Instance usage",
+					Unit:            "hours",
+					MonthlyQuantity: decimalPtr(decimal.NewFromInt(quantity)),
+					price:           decimal.NewFromInt(2),
+					MonthlyCost:     cost,
+				},
+			},
+		}
+	}
+
+	usageResource := func(name, resourceType string) *Resource {
+		return &Resource{
+			Name:         name,
+			ResourceType: resourceType,
+			CostComponents: []*CostComponent{
+				{
+					Name:  "Requests",
+					Unit:  "1M requests",
+					price: decimal.NewFromFloat(0.2),
+				},
+			},
+		}
+	}
+
+	pastResources := []*Resource{
+		resource("aws_instance.web", "aws_instance", "", 10),
+		resource("aws_instance.app", "aws_instance", "", 10),
+		resource("module.db.aws_db_instance.db", "aws_db_instance", "", 10),
+		resource("aws_nat_gateway.old", "aws_nat_gateway", "", 10),
+		usageResource("aws_lambda_function.a", "aws_lambda_function"),
+	}
+
+	currentResources := []*Resource{
+		// Moved with a moved block and changed
+		resource("module.web.aws_instance.web", "aws_instance", "aws_instance.web", 20),
+		// Renamed without a moved block but the cost components are identical
+		resource("aws_instance.application", "aws_instance", "", 10),
+		// The prior state already has the new address
+		resource("module.db.aws_db_instance.db", "aws_db_instance", "aws_db_instance.db", 10),
+		// Different cost components so this is an added resource and aws_nat_gateway.old is removed
+		resource("aws_nat_gateway.new", "aws_nat_gateway", "", 5),
+		// No usage so the cost components are identical but this is an added resource and aws_lambda_function.a is removed
+		usageResource("aws_lambda_function.b", "aws_lambda_function"),
+	}
+
+	diff := calculateDiff(pastResources, currentResources)
+
+	moved := map[string]string{}
+	costs := map[string]string{}
+	for _, r := range diff {
+		moved[r.Name] = r.MovedFrom
+		costs[r.Name] = r.MonthlyCost.String()
+	}
+
+	assert.Equal(t, map[string]string{
+		"module.web.aws_instance.web":  "aws_instance.web",
+		"aws_instance.application":     "aws_instance.app",
+		"module.db.aws_db_instance.db": "aws_db_instance.db",
+		"aws_nat_gateway.old":          "",
+		"aws_nat_gateway.new":          "",
+		"aws_lambda_function.a":        "",
+		"aws_lambda_function.b":        "",
+	}, moved)
+
+	assert.Equal(t, map[string]string{
+		"module.web.aws_instance.web":  "20",
+		"aws_instance.application":     "0",
+		"module.db.aws_db_instance.db": "0",
+		"aws_nat_gateway.old":          "-20",
+		"aws_nat_gateway.new":          "10",
+		"aws_lambda_function.a":        "0",
+		"aws_lambda_function.b":        "0",
+	}, costs)
+}
+
 func TestDiffCostComponentsByResource(t *testing.T) {
 	pastRS := &Resource{
 		Name: "rs",
@@ -20,6 +20,8 @@ type ProjectMetadata struct {
 	VCSSubPath         string `json:"vcsSubPath,omitempty"`
 	VCSPullRequestURL  string `json:"vcsPullRequestUrl,omitempty"`
 	TerraformWorkspace string `json:"terraformWorkspace,omitempty"`
+	// MissingVariables are the required Terraform variables that had no value when the HCL was parsed
+	MissingVariables []string `json:"missingVariables,omitempty"`
 }
 
 // Project contains the existing, planned state of
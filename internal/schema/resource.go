@@ -25,6 +25,17 @@ type Resource struct {
 	UsageSchema       []*UsageItem
 	EstimateUsage     EstimateFunc
 	EstimationSummary map[string]bool
+	// UnknownAttributes are the attributes that could not be resolved when the resource was parsed
+	// from HCL, so the resource cost might not be accurate.
+	UnknownAttributes []string
+	// MovedFrom is the address the resource had before it was moved or renamed, e.g. from a Terraform
+	// moved block or the previous_address of the resource change in the plan JSON.
+	MovedFrom string
+	// Metadata is extra information about the resource, e.g. the location of the resource in the
+	// Terraform files.
+	Metadata map[string]string
+	// AppliedUsage are the usage values, e.g. from the usage file, that were used to calculate the costs.
+	AppliedUsage map[string]interface{}
 }
 
 func CalculateCosts(project *Project) {
@@ -112,6 +112,34 @@ func (u *UsageData) CalcEstimationSummary() map[string]bool {
 	return estimationMap
 }
 
+// AppliedValues returns the usage values for the keys in the usage schema, or all the usage values if
+// the usage schema is empty. Null and empty values are not included and nil is returned if there are no values.
+func (u *UsageData) AppliedValues(usageSchema []*UsageItem) map[string]interface{} {
+	keys := make(map[string]bool, len(usageSchema))
+	for _, item := range usageSchema {
+		keys[item.Key] = true
+	}
+
+	var values map[string]interface{}
+	for k, v := range u.Attributes {
+		if len(keys) > 0 && !keys[k] {
+			continue
+		}
+
+		if u.IsEmpty(k) {
+			continue
+		}
+
+		if values == nil {
+			values = make(map[string]interface{})
+		}
+
+		values[k] = v.Value()
+	}
+
+	return values
+}
+
 func NewUsageMap(m map[string]interface{}) map[string]*UsageData {
 	usageMap := make(map[string]*UsageData)
 
@@ -114,3 +114,29 @@ func TestParseAttributes(t *testing.T) {
 		})
 	}
 }
+
+func TestUsageDataAppliedValues(t *testing.T) {
+	u := NewUsageData("aws_lambda_function.hello", ParseAttributes(map[string]interface{}{
+		"monthly_requests":   100,
+		"request_duration":   250,
+		"unused_key":         "a",
+		"empty_key":          "",
+		"storage_class_list": []interface{}{"a", "b"},
+	}))
+
+	usageSchema := []*UsageItem{
+		{Key: "monthly_requests", ValueType: Int64},
+		{Key: "request_duration", ValueType: Int64},
+		{Key: "empty_key", ValueType: String},
+		{Key: "storage_class_list", ValueType: StringArray},
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"monthly_requests":   float64(100),
+		"request_duration":   float64(250),
+		"storage_class_list": []interface{}{"a", "b"},
+	}, u.AppliedValues(usageSchema))
+
+	assert.Len(t, u.AppliedValues(nil), 4)
+	assert.Nil(t, NewUsageData("aws_instance.web", nil).AppliedValues(usageSchema))
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
 )
 
 type ctxConfigOptsKeyType struct{}
@@ -25,3 +26,65 @@ func getConfig(ctx context.Context, region string) (aws.Config, error) {
 	cfg, err := config.LoadDefaultConfig(ctx, opts...)
 	return cfg, err
 }
+
+// WithProjectEnv returns a context that configures the AWS clients used to estimate usage with
+// the AWS environment variables of the project, e.g. AWS_PROFILE and AWS_REGION. The project env
+// is never set in the process environment, so the SDK can't read these variables itself.
+func WithProjectEnv(ctx context.Context, env map[string]string) context.Context {
+	opts := projectEnvConfigOpts(env)
+	if len(opts) == 0 {
+		return ctx
+	}
+
+	if ctxOpts, ok := ctx.Value(ctxConfigOptsKey).([]func(*config.LoadOptions) error); ok {
+		opts = append(append([]func(*config.LoadOptions) error{}, ctxOpts...), opts...)
+	}
+
+	return context.WithValue(ctx, ctxConfigOptsKey, opts)
+}
+
+func projectEnvConfigOpts(env map[string]string) []func(*config.LoadOptions) error {
+	var opts []func(*config.LoadOptions) error
+
+	if region := firstEnv(env, "AWS_REGION", "AWS_DEFAULT_REGION"); region != "" {
+		// The region of the resource takes precedence, so only set the region if there isn't one.
+		opts = append(opts, func(o *config.LoadOptions) error {
+			if o.Region == "" {
+				o.Region = region
+			}
+
+			return nil
+		})
+	}
+
+	if profile := firstEnv(env, "AWS_PROFILE", "AWS_DEFAULT_PROFILE"); profile != "" {
+		opts = append(opts, config.WithSharedConfigProfile(profile))
+	}
+
+	if f := env["AWS_CONFIG_FILE"]; f != "" {
+		opts = append(opts, config.WithSharedConfigFiles([]string{f}))
+	}
+
+	if f := env["AWS_SHARED_CREDENTIALS_FILE"]; f != "" {
+		opts = append(opts, config.WithSharedCredentialsFiles([]string{f}))
+	}
+
+	accessKeyID, secretAccessKey := env["AWS_ACCESS_KEY_ID"], env["AWS_SECRET_ACCESS_KEY"]
+	if accessKeyID != "" && secretAccessKey != "" {
+		opts = append(opts, config.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(accessKeyID, secretAccessKey, env["AWS_SESSION_TOKEN"]),
+		))
+	}
+
+	return opts
+}
+
+func firstEnv(env map[string]string, keys ...string) string {
+	for _, k := range keys {
+		if v := env[k]; v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
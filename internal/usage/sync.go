@@ -51,7 +51,10 @@ func (s *SyncResult) ProjectContext() map[string]interface{} {
 	return r
 }
 
-func SyncUsageData(usageFile *UsageFile, projects []*schema.Project) (*SyncResult, error) {
+// SyncUsageData updates the usage file with the usage schema of the resources in the projects and
+// the usage estimated for them. The context is passed to the usage estimates, e.g. to set the
+// cloud credentials of the project.
+func SyncUsageData(ctx context.Context, usageFile *UsageFile, projects []*schema.Project) (*SyncResult, error) {
 	referenceFile, err := LoadReferenceFile()
 	if err != nil {
 		return nil, err
@@ -64,7 +67,7 @@ func SyncUsageData(usageFile *UsageFile, projects []*schema.Project) (*SyncResul
 		resources = append(resources, project.Resources...)
 	}
 
-	syncResult := syncResourceUsages(usageFile, resources, referenceFile)
+	syncResult := syncResourceUsages(ctx, usageFile, resources, referenceFile)
 
 	return syncResult, nil
 }
@@ -74,7 +77,7 @@ type syncResourceResult struct {
 	sr *SyncResult
 }
 
-func syncResourceUsages(usageFile *UsageFile, resources []*schema.Resource, referenceFile *ReferenceFile) *SyncResult {
+func syncResourceUsages(ctx context.Context, usageFile *UsageFile, resources []*schema.Resource, referenceFile *ReferenceFile) *SyncResult {
 	syncResult := &SyncResult{
 		EstimationErrors: make(map[string]error),
 	}
@@ -112,7 +115,7 @@ func syncResourceUsages(usageFile *UsageFile, resources []*schema.Resource, refe
 	for i := 0; i < numWorkers; i++ {
 		go func(jobs <-chan *schema.Resource, results chan<- syncResourceResult) {
 			for r := range jobs {
-				ru, sr := syncResource(r, referenceFile, existingResourceUsagesMap)
+				ru, sr := syncResource(ctx, r, referenceFile, existingResourceUsagesMap)
 				results <- syncResourceResult{ru, sr}
 			}
 		}(jobs, results)
@@ -174,7 +177,7 @@ func syncWildCardResource(wildCardResources map[string]bool, resource *schema.Re
 	return resourceUsage
 }
 
-func syncResource(resource *schema.Resource, referenceFile *ReferenceFile, existingResourceUsagesMap map[string]*ResourceUsage) (*ResourceUsage, *SyncResult) {
+func syncResource(ctx context.Context, resource *schema.Resource, referenceFile *ReferenceFile, existingResourceUsagesMap map[string]*ResourceUsage) (*ResourceUsage, *SyncResult) {
 	syncResult := &SyncResult{
 		EstimationErrors: make(map[string]error),
 	}
@@ -208,7 +211,7 @@ func syncResource(resource *schema.Resource, referenceFile *ReferenceFile, exist
 		syncResult.EstimationCount++
 
 		resourceUsageMap := resourceUsage.Map()
-		err := resource.EstimateUsage(context.TODO(), resourceUsageMap)
+		err := resource.EstimateUsage(ctx, resourceUsageMap)
 		if err != nil {
 			syncResult.EstimationErrors[resource.Name] = err
 			log.Warnf("Error estimating usage for resource %s: %v", resource.Name, err)